@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"fpv-ground-station/internal/serial"
+)
+
+// probeBaud listens on port at each common rate and returns the one with
+// the best valid LTM frame ratio.
+func probeBaud(ctx context.Context, port string) (int, error) {
+	log.Printf("Probing baud rate on %s...", port)
+	baud, results, err := serial.ProbeBaud(ctx, port, nil, serial.DefaultProbeDwell)
+	for _, r := range results {
+		log.Printf("  %6d baud: %d bytes, %d frames, %d errors (%.0f%% valid)",
+			r.Baud, r.Bytes, r.Frames, r.Errors, r.Ratio()*100)
+	}
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Selected %d baud", baud)
+	return baud, nil
+}
+
+// savedBaudPath is the file mapping serial port names to probed baud rates.
+func savedBaudPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fpv-ground-station", "baud.json"), nil
+}
+
+func readSavedBauds() (map[string]int, error) {
+	path, err := savedBaudPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bauds := map[string]int{}
+	if err := json.Unmarshal(data, &bauds); err != nil {
+		return nil, err
+	}
+	return bauds, nil
+}
+
+// loadSavedBaud returns the rate previously saved for port, or 0.
+func loadSavedBaud(port string) int {
+	bauds, err := readSavedBauds()
+	if err != nil {
+		log.Printf("read saved baud rates: %v", err)
+		return 0
+	}
+	return bauds[port]
+}
+
+// saveBaud records baud as the rate for port.
+func saveBaud(port string, baud int) error {
+	bauds, err := readSavedBauds()
+	if err != nil {
+		return err
+	}
+	bauds[port] = baud
+
+	path, err := savedBaudPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bauds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/export"
+	"fpv-ground-station/internal/telemetry"
+)
+
+// exportMain implements the "export" subcommand: it converts one track
+// session to GPX or KML. Given a track directory it exports the latest
+// session, or the points between -since and -until, so unrelated flights
+// are not joined into one line.
+func exportMain(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "-", "output file, or - for stdout")
+	format := fs.String("format", "", "gpx or kml (default: from the -out extension, else gpx)")
+	name := fs.String("name", "", "track name (default: the track file name)")
+	home := fs.String("home", "", "home position as lat,lon[,alt] (default: the first point with a fix)")
+	since := fs.String("since", "", "export points from this time on, as Unix milliseconds or RFC 3339")
+	until := fs.String("until", "", "export points up to this time, as Unix milliseconds or RFC 3339")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: fpv-ground-station export [flags] [track-file-or-dir]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := "tracks"
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	write, err := exportWriter(*format, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var q telemetry.TrackQuery
+	if q.Since, err = parseExportTime(*since); err != nil {
+		log.Fatalf("invalid -since: %v", err)
+	}
+	if q.Until, err = parseExportTime(*until); err != nil {
+		log.Fatalf("invalid -until: %v", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() && q.Since.IsZero() && q.Until.IsZero() {
+		latest, err := telemetry.LatestTrackFile(path)
+		if err != nil {
+			log.Fatalf("read track: %v", err)
+		}
+		if latest == "" {
+			log.Fatalf("read track: no track points in %s", path)
+		}
+		path = latest
+	}
+	points, err := telemetry.QueryTrack(path, q)
+	if err != nil {
+		log.Fatalf("read track: %v", err)
+	}
+	t := export.Track{Name: *name, Points: points}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if *home != "" {
+		lat, lon, alt, err := parseHome(*home)
+		if err != nil {
+			log.Fatalf("invalid -home: %v", err)
+		}
+		t.Home = &export.Home{Lat: lat, Lon: lon, Alt: alt}
+	}
+
+	if *out == "-" {
+		bw := bufio.NewWriter(os.Stdout)
+		if err := write(bw, t); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		if err := bw.Flush(); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("create output: %v", err)
+	}
+	bw := bufio.NewWriter(f)
+	err = write(bw, t)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatalf("export: %v", err)
+	}
+	log.Printf("Exported %d points to %s", len(points), *out)
+}
+
+// parseExportTime parses a time given as Unix milliseconds or RFC 3339.
+// An empty string is the zero time.
+func parseExportTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// exportWriter picks the writer for format, inferring it from the output
+// file extension when format is empty.
+func exportWriter(format, out string) (func(io.Writer, export.Track) error, error) {
+	if format == "" {
+		format = "gpx"
+		if out != "-" && strings.EqualFold(filepath.Ext(out), ".kml") {
+			format = "kml"
+		}
+	}
+	switch strings.ToLower(format) {
+	case "gpx":
+		return export.WriteGPX, nil
+	case "kml":
+		return export.WriteKML, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q (want gpx or kml)", format)
+}
@@ -2,160 +2,141 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
+	"fpv-ground-station/internal/capture"
 	"fpv-ground-station/internal/ltm"
 	"fpv-ground-station/internal/serial"
-	"fpv-ground-station/internal/server"
-	"fpv-ground-station/internal/telemetry"
+	"fpv-ground-station/internal/source"
 )
 
 func main() {
-	portName := flag.String("port", envOr("PORT", "/dev/cu.usbserial-840"), "serial port path")
-	flag.StringVar(portName, "p", *portName, "serial port path (shorthand)")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			replayMain(os.Args[2:])
+			return
+		case "simulate":
+			simulateMain(os.Args[2:])
+			return
+		case "export":
+			exportMain(os.Args[2:])
+			return
+		}
+	}
+
+	portName := flag.String("port", envOr("PORT", "/dev/cu.usbserial-840"),
+		"input source: serial port path or tcp://host:port, tcp-listen://:port, udp://:port, file://path, stdin")
+	flag.StringVar(portName, "p", *portName, "input source (shorthand)")
 	baud := flag.Int("baud", envOrInt("BAUD", 19200), "baud rate")
 	flag.IntVar(baud, "b", *baud, "baud rate (shorthand)")
-	jsonOut := flag.Bool("json", false, "output JSON lines instead of human-readable")
-	webAddr := flag.String("web", ":8080", "web UI listen address (e.g. :8080)")
-	devMode := flag.Bool("dev", false, "dev mode: skip embedded UI, use Vite proxy")
+	probe := flag.Bool("probe-baud", false, "probe common baud rates for valid LTM frames before starting")
+	save := flag.Bool("save-baud", false, "remember the probed baud rate for this port")
+	record := flag.String("record", "", "record raw input bytes to this capture file for later replay")
+	opts := addStationFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	port, err := serial.Open(serial.Config{Name: *portName, Baud: *baud})
-	if err != nil {
-		log.Fatalf("open serial: %v", err)
+	if *probe && opts.protocol != "auto" && opts.protocol != "ltm" {
+		log.Fatal("-probe-baud detects LTM frames only")
+	}
+	if in := (source.Config{URI: *portName}); in.Scheme() == "serial" {
+		*baud = resolveBaud(ctx, in.Addr(), *baud, *probe, *save)
+	} else if *probe {
+		log.Fatal("-probe-baud only applies to serial ports")
 	}
-	defer port.Close()
-
-	// Flush stale bytes before starting
-	port.ResetInputBuffer()
-
-	log.Printf("LTM on %s @ %d baud", *portName, *baud)
-
-	store := &telemetry.Store{}
-	stats := telemetry.NewStats()
 
-	trackLog, err := telemetry.NewTrackLog("track.csv")
+	st := newStation(opts)
+
+	srcCfg := source.Config{
+		URI:  *portName,
+		Baud: *baud,
+		OnLinkState: func(state serial.LinkState, retries int, err error) {
+			switch {
+			case state == serial.Connected:
+				log.Printf("Link restored after %d attempts", retries)
+			case retries == 0:
+				log.Printf("Link lost: %v; reconnecting", err)
+			}
+			st.stats.SetLink(state.String(), retries, err)
+		},
+	}
+	src, err := source.Open(srcCfg)
 	if err != nil {
-		log.Fatalf("open track log: %v", err)
+		log.Fatalf("open input: %v", err)
 	}
-	defer trackLog.Close()
+	defer src.Close()
+	st.stats.SetLink(serial.Connected.String(), 0, nil)
 
-	// Start web server
-	distFS, err := webDistFS()
-	if err != nil {
-		log.Fatalf("load embedded UI: %v", err)
+	// Network and stdin reads block without a timeout; closing the source
+	// is what unblocks them on shutdown.
+	context.AfterFunc(ctx, func() { src.Close() })
+
+	if opts.protocol == "auto" {
+		log.Printf("Detecting protocol on %s", srcCfg)
+	} else {
+		log.Printf("%s on %s", strings.ToUpper(opts.protocol), srcCfg)
 	}
-	if distFS == nil && !*devMode {
-		log.Fatal("no embedded UI available; rebuild with 'make build' or use --dev flag")
+
+	var r io.Reader = src
+	if *record != "" {
+		rec, err := capture.Create(*record)
+		if err != nil {
+			log.Fatalf("create capture: %v", err)
+		}
+		defer rec.Close()
+		r = io.TeeReader(src, rec)
+		log.Printf("Recording raw bytes to %s", *record)
 	}
 
-	srv := server.New(server.Config{
-		Store:    store,
-		Stats:    stats,
-		TrackLog: trackLog,
-		Addr:     *webAddr,
-		WebFS:    distFS,
-		DevMode:  *devMode,
-	})
+	st.run(ctx, r)
+}
 
-	go func() {
-		if err := srv.ListenAndServe(ctx); err != nil {
-			log.Fatalf("web server: %v", err)
+// resolveBaud picks the serial baud rate: a fresh probe when requested,
+// otherwise an explicit -baud/BAUD, otherwise a rate saved by an earlier
+// probe, otherwise def.
+func resolveBaud(ctx context.Context, port string, def int, probe, save bool) int {
+	if probe {
+		baud, err := probeBaud(ctx, port)
+		if err != nil {
+			log.Fatalf("probe baud: %v", err)
 		}
-	}()
-
-	log.Printf("Web UI: http://localhost%s", *webAddr)
-
-	// Perf ticker: log attitude Hz every second
-	go func() {
-		ticker := time.NewTicker(1 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				hz := stats.AttitudeRx.Swap(0)
-				if hz > 0 {
-					log.Printf("Attitude: %d Hz", hz)
-				}
+		if save {
+			if err := saveBaud(port, baud); err != nil {
+				log.Printf("save baud rate: %v", err)
+			} else {
+				log.Printf("Saved %d baud for %s", baud, port)
 			}
 		}
-	}()
-
-	// Read LTM from serial port
-	readLTM(ctx, port, store, stats, trackLog, *jsonOut)
-
-	// Shutdown
-	port.Close()
-	log.Println("Shutting down...")
-
-	if *jsonOut {
-		statsJSON := struct {
-			UptimeSec    float64      `json:"uptime_sec"`
-			Total        int          `json:"total"`
-			FPS          float64      `json:"fps"`
-			Frames       map[byte]int `json:"frames"`
-			CRCErrors    int          `json:"crc_errors"`
-			DecodeErrors int          `json:"decode_errors"`
-		}{
-			UptimeSec:    stats.Uptime().Seconds(),
-			Total:        stats.Total,
-			FPS:          stats.FPS(),
-			Frames:       stats.Frames,
-			CRCErrors:    stats.CRCErrors,
-			DecodeErrors: stats.DecodeErrors,
+		return baud
+	}
+
+	explicit := os.Getenv("BAUD") != ""
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "baud" || f.Name == "b" {
+			explicit = true
+		}
+	})
+	if !explicit {
+		if saved := loadSavedBaud(port); saved > 0 {
+			log.Printf("Using saved baud rate %d for %s", saved, port)
+			return saved
 		}
-		json.NewEncoder(os.Stderr).Encode(statsJSON)
-	} else {
-		fmt.Fprintln(os.Stderr, stats.Summary())
 	}
+	return def
 }
 
-func readLTM(ctx context.Context, port *serial.Port, store *telemetry.Store, stats *telemetry.Stats, trackLog *telemetry.TrackLog, jsonOut bool) {
-	enc := json.NewEncoder(os.Stdout)
-
-	parser := ltm.NewParser(
-		func(raw ltm.RawFrame) {
-			frame, err := ltm.Decode(raw)
-			if err != nil {
-				stats.RecordDecodeError()
-				return
-			}
-
-			store.Update(frame)
-			stats.Count(frame.Function)
-
-			if frame.GPS != nil && frame.GPS.Lat != 0 {
-				trackLog.Append(frame.GPS.Lat, frame.GPS.Lon)
-			}
-
-			if frame.Attitude != nil {
-				stats.AttitudeRx.Add(1)
-			}
-
-			if jsonOut {
-				enc.Encode(frame)
-			} else {
-				printHuman(frame)
-			}
-		},
-		func(err error) {
-			log.Printf("[PARSER ERR] %v", err)
-			stats.RecordCRCError()
-		},
-	)
-
+// readInput copies r into parser until r is exhausted or ctx is cancelled.
+func readInput(ctx context.Context, r io.Reader, parser io.Writer) {
 	buf := make([]byte, 256)
 	for {
 		select {
@@ -164,13 +145,20 @@ func readLTM(ctx context.Context, port *serial.Port, store *telemetry.Store, sta
 		default:
 		}
 
-		n, err := port.Read(buf)
-		if err != nil {
-			continue
-		}
+		n, err := r.Read(buf)
 		if n > 0 {
 			parser.Write(buf[:n])
 		}
+		if err == io.EOF {
+			log.Println("Input ended")
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("read input: %v", err)
+			}
+			return
+		}
 	}
 }
 
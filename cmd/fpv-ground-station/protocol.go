@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"fpv-ground-station/internal/crsf"
+	"fpv-ground-station/internal/detect"
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/mavlink"
+)
+
+// newInputParser returns the byte sink for the -protocol flag. With "auto"
+// the protocol is detected from the stream before a parser is built.
+func (st *station) newInputParser(protocol string) (io.Writer, error) {
+	if protocol != "auto" {
+		st.setProtocol(protocol)
+		return st.newFrameParser(protocol)
+	}
+
+	d := detect.New(st.newFrameParser)
+	d.OnDetect = func(p string) {
+		log.Printf("Detected %s telemetry", strings.ToUpper(p))
+		st.setProtocol(p)
+	}
+	d.OnError = func(err error) {
+		log.Printf("Cannot decode detected protocol: %v", err)
+	}
+	return d, nil
+}
+
+// setProtocol records the protocol being decoded in the stats and in the
+// store, whose freshness depends on the protocol's frame rates.
+func (st *station) setProtocol(protocol string) {
+	st.stats.SetProtocol(protocol)
+	st.store.SetProtocol(protocol)
+}
+
+// newFrameParser returns a byte sink that decodes the named protocol and
+// passes every resulting LTM frame to st.handleFrame. Non-LTM protocols are
+// converted so the store, track log and UI stay protocol-agnostic.
+func (st *station) newFrameParser(protocol string) (io.Writer, error) {
+	stats, handle := st.stats, st.handleFrame
+	onErr := func(err error) {
+		log.Printf("[PARSER ERR] %v", err)
+		stats.RecordCRCError()
+	}
+
+	switch protocol {
+	case detect.LTM:
+		return ltm.NewParser(
+			func(raw ltm.RawFrame) {
+				frame, err := ltm.Decode(raw)
+				if err != nil {
+					stats.RecordDecodeError()
+					return
+				}
+				handle(frame)
+			},
+			onErr,
+		), nil
+
+	case detect.MAVLink:
+		conv := mavlink.NewConverter()
+		return mavlink.NewParser(
+			func(raw mavlink.RawMessage) {
+				msg, err := mavlink.Decode(raw)
+				if err != nil {
+					stats.RecordDecodeError()
+					return
+				}
+				for _, frame := range conv.Frames(msg) {
+					handle(frame)
+				}
+			},
+			onErr,
+		), nil
+
+	case detect.CRSF:
+		conv := crsf.NewConverter()
+		return crsf.NewParser(
+			func(raw crsf.RawFrame) {
+				frame, err := crsf.Decode(raw)
+				if err != nil {
+					stats.RecordDecodeError()
+					return
+				}
+				if frame.LinkStatistics != nil {
+					st.store.UpdateCRSFLink(frame.LinkStatistics, frame.Time)
+				}
+				for _, f := range conv.Frames(frame) {
+					handle(f)
+				}
+			},
+			onErr,
+		), nil
+
+	case detect.MSP:
+		return nil, fmt.Errorf("MSP telemetry is not supported; configure the port for LTM, MAVLink or CRSF")
+	}
+	return nil, fmt.Errorf("unknown protocol %q (want auto, ltm, mavlink or crsf)", protocol)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fpv-ground-station/internal/capture"
+)
+
+// replayMain implements the "replay" subcommand: it feeds a capture file
+// recorded with -record back through the normal pipeline.
+func replayMain(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	speed := fs.Float64("speed", 1, "replay speed multiplier (0 = as fast as possible)")
+	loop := fs.Bool("loop", false, "restart from the beginning when the capture ends")
+	opts := addStationFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: fpv-ground-station replay [flags] <capture-file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	r := &replayReader{ctx: ctx, path: path, speed: *speed, loop: *loop}
+	defer r.Close()
+	if err := r.open(); err != nil {
+		log.Fatalf("open capture: %v", err)
+	}
+
+	if *speed > 0 {
+		log.Printf("Replaying %s at %gx", path, *speed)
+	} else {
+		log.Printf("Replaying %s as fast as possible", path)
+	}
+
+	newStation(opts).run(ctx, r)
+}
+
+// replayReader plays a capture file, optionally reopening it at EOF.
+type replayReader struct {
+	ctx   context.Context
+	path  string
+	speed float64
+	loop  bool
+
+	cap    *capture.Reader
+	player *capture.Player
+}
+
+func (r *replayReader) open() error {
+	c, err := capture.Open(r.path)
+	if err != nil {
+		return err
+	}
+	r.cap = c
+	r.player = capture.NewPlayer(r.ctx, c, r.speed)
+	return nil
+}
+
+func (r *replayReader) Read(buf []byte) (int, error) {
+	n, err := r.player.Read(buf)
+	if err != nil && err != io.EOF && r.ctx.Err() == nil {
+		// A corrupt record cannot be skipped; end the replay.
+		log.Printf("replay: %v", err)
+		return n, io.EOF
+	}
+	if err != io.EOF || !r.loop {
+		return n, err
+	}
+
+	r.cap.Close()
+	if err := r.open(); err != nil {
+		return 0, err
+	}
+	log.Printf("Replay restarted")
+	return r.player.Read(buf)
+}
+
+func (r *replayReader) Close() error {
+	if r.cap == nil {
+		return nil
+	}
+	return r.cap.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"fpv-ground-station/internal/serial"
+	"fpv-ground-station/internal/sim"
+	"fpv-ground-station/internal/source"
+)
+
+// simulateMain implements the "simulate" subcommand: it generates a scripted
+// flight and either feeds it through the local pipeline or writes the LTM
+// stream to a serial port or TCP peer.
+func simulateMain(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	out := fs.String("out", "", "write LTM to a sink instead of the local pipeline: serial port path, tcp://host:port, tcp-listen://:port or - for stdout")
+	baud := fs.Int("baud", 19200, "baud rate for a serial sink")
+	speed := fs.Float64("speed", 1, "simulation speed multiplier (0 = as fast as possible)")
+	loop := fs.Bool("loop", false, "repeat the flight after landing")
+	home := fs.String("home", "", "home position as lat,lon[,alt] (default 51.5,-0.1278)")
+	opts := addStationFlags(fs)
+	fs.Parse(args)
+	// The simulator only speaks LTM.
+	opts.protocol = "ltm"
+
+	cfg := sim.Config{Loop: *loop}
+	if *home != "" {
+		var err error
+		cfg.HomeLat, cfg.HomeLon, cfg.HomeAlt, err = parseHome(*home)
+		if err != nil {
+			log.Fatalf("invalid -home: %v", err)
+		}
+	}
+	s := sim.New(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *out == "" {
+		log.Printf("Simulating %s flight through the local pipeline", s.Duration().Round(time.Second))
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(s.Run(ctx, pw, *speed))
+		}()
+		newStation(opts).run(ctx, pr)
+		return
+	}
+
+	sink, err := openSink(source.Config{URI: *out, Baud: *baud})
+	if err != nil {
+		log.Fatalf("open sink: %v", err)
+	}
+	defer sink.Close()
+	context.AfterFunc(ctx, func() { sink.Close() })
+
+	log.Printf("Simulating %s flight to %s", s.Duration().Round(time.Second), *out)
+	if err := s.Run(ctx, sink, *speed); err != nil && ctx.Err() == nil {
+		log.Fatalf("simulate: %v", err)
+	}
+	log.Println("Simulation finished")
+}
+
+// openSink opens a writable counterpart of an input source URI.
+func openSink(cfg source.Config) (io.WriteCloser, error) {
+	addr := cfg.Addr()
+
+	switch cfg.Scheme() {
+	case "serial":
+		return serial.Open(serial.Config{Name: addr, Baud: cfg.Baud})
+	case "tcp":
+		return net.Dial("tcp", addr)
+	case "tcp-listen":
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		defer ln.Close()
+		log.Printf("Waiting for a client on %s", ln.Addr())
+		return ln.Accept()
+	case "stdin":
+		if cfg.URI == "-" {
+			return os.Stdout, nil
+		}
+		fallthrough
+	default:
+		return nil, fmt.Errorf("unsupported sink %q", cfg.URI)
+	}
+}
+
+// parseHome parses "lat,lon" or "lat,lon,alt".
+func parseHome(s string) (lat, lon, alt float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, 0, fmt.Errorf("%q: want lat,lon[,alt]", s)
+	}
+	vals := make([]float64, 3)
+	for i, p := range parts {
+		if vals[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("%q: %w", s, err)
+		}
+	}
+	return vals[0], vals[1], vals[2], nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/logbook"
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/server"
+	"fpv-ground-station/internal/telemetry"
+)
+
+// legacyTrackFile is where versions before track directories wrote the
+// track log. It is moved into the track directory on startup.
+const legacyTrackFile = "track.csv"
+
+// stationOptions are the settings shared by every mode that runs the
+// telemetry pipeline and web UI.
+type stationOptions struct {
+	protocol string
+	jsonOut  bool
+	webAddr  string
+	maxRate  float64
+	devMode  bool
+	alarms   string
+	history  time.Duration
+
+	trackDir         string
+	trackMaxSize     int // MB
+	trackMaxAge      time.Duration
+	trackMinDistance float64
+	trackMaxSpeed    float64
+
+	logbook        string
+	logbookMaxSize int // MB
+}
+
+func addStationFlags(fs *flag.FlagSet) *stationOptions {
+	o := &stationOptions{}
+	fs.StringVar(&o.protocol, "protocol", "auto", "telemetry protocol: auto, ltm, mavlink or crsf")
+	fs.BoolVar(&o.jsonOut, "json", false, "output JSON lines instead of human-readable")
+	fs.StringVar(&o.webAddr, "web", ":8080", "web UI listen address (e.g. :8080)")
+	fs.Float64Var(&o.maxRate, "max-rate", server.DefaultMaxRate, "most telemetry messages per second sent to each WebSocket or SSE client")
+	fs.BoolVar(&o.devMode, "dev", false, "dev mode: skip embedded UI, use Vite proxy")
+	fs.StringVar(&o.alarms, "alarms", "", "alarm rules file (JSON); built-in rules if empty")
+	fs.DurationVar(&o.history, "history", telemetry.DefaultHistoryWindow, "how far back /api/history reaches")
+	fs.StringVar(&o.trackDir, "track-dir", "tracks", "directory for per-session GPS track files")
+	fs.IntVar(&o.trackMaxSize, "track-max-size", 100, "delete the oldest track files beyond this total size in MB (0 = unlimited)")
+	fs.DurationVar(&o.trackMaxAge, "track-max-age", 30*24*time.Hour, "delete track files last written longer ago than this (0 = keep forever)")
+	fs.Float64Var(&o.trackMinDistance, "track-min-distance", 1, "skip track points closer than this many metres to the last one")
+	fs.Float64Var(&o.trackMaxSpeed, "track-max-speed", 150, "reject track points implying a faster jump than this many m/s (0 = off)")
+	fs.StringVar(&o.logbook, "logbook", "logbook", "directory of the flight logbook (flights, frames and alarms); empty to disable")
+	fs.IntVar(&o.logbookMaxSize, "logbook-max-size", 1024, "delete the oldest logbook frames beyond this size in MB (0 = unlimited)")
+	return o
+}
+
+// alarmInterval is how often alarm rules are evaluated. Evaluation runs on
+// a timer rather than per frame so debounce periods elapse without input.
+const alarmInterval = 200 * time.Millisecond
+
+// station owns the telemetry state shared by the parser and the web server.
+type station struct {
+	opts     *stationOptions
+	store    *telemetry.Store
+	stats    *telemetry.Stats
+	sessions *telemetry.Sessions
+	trackLog *telemetry.TrackLog
+	logbook  *logbook.DB
+	srv      *server.Server
+	enc      *json.Encoder
+
+	logbookFailed bool // a frame write has failed and been logged
+}
+
+func newStation(opts *stationOptions) *station {
+	st := &station{
+		opts:     opts,
+		store:    &telemetry.Store{},
+		stats:    telemetry.NewStats(),
+		sessions: telemetry.NewSessions(),
+		enc:      json.NewEncoder(os.Stdout),
+	}
+	if opts.history < telemetry.HistoryResolution {
+		log.Fatalf("invalid -history %s: want at least %s", opts.history, telemetry.HistoryResolution)
+	}
+	st.store.SetHistoryWindow(opts.history)
+	st.sessions.OnChange = func(f telemetry.Flight) {
+		if f.Active {
+			log.Printf("Flight %d armed", f.ID)
+			// Each flight starts its own track file.
+			if st.trackLog != nil {
+				if err := st.trackLog.Rotate(); err != nil {
+					log.Printf("rotate track log: %v", err)
+				}
+			}
+		} else {
+			log.Printf("Flight %d disarmed after %s", f.ID, time.Duration(f.Summary.DurationSec*float64(time.Second)).Round(time.Second))
+		}
+		st.recordFlight(f)
+		if st.srv != nil {
+			st.srv.PublishSession(f)
+		}
+	}
+	return st
+}
+
+// recordFlight stores the latest state of a session flight in the logbook.
+func (st *station) recordFlight(f telemetry.Flight) {
+	if st.logbook == nil {
+		return
+	}
+	if err := st.logbook.RecordFlight(f); err != nil {
+		log.Printf("logbook: %v", err)
+	}
+}
+
+// run feeds r through the protocol parser into the telemetry store and
+// serves the web UI until r is exhausted or ctx is cancelled.
+func (st *station) run(ctx context.Context, r io.Reader) {
+	opts, store, stats := st.opts, st.store, st.stats
+
+	parser, err := st.newInputParser(opts.protocol)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if moved, err := telemetry.ImportTrackFile(legacyTrackFile, opts.trackDir); err != nil {
+		log.Printf("track log: %v", err)
+	} else if moved {
+		log.Printf("Moved %s into %s", legacyTrackFile, opts.trackDir)
+	}
+	trackLog, err := telemetry.OpenTrackDir(opts.trackDir, telemetry.TrackOptions{
+		MaxBytes:    int64(opts.trackMaxSize) << 20,
+		MaxAge:      opts.trackMaxAge,
+		MinDistance: opts.trackMinDistance,
+		MaxSpeed:    opts.trackMaxSpeed,
+		MinFix:      2,
+	})
+	if err != nil {
+		log.Fatalf("open track log: %v", err)
+	}
+	defer trackLog.Close()
+	st.trackLog = trackLog
+
+	if opts.logbook != "" {
+		db, err := logbook.Open(opts.logbook, logbook.Options{MaxFrameBytes: int64(opts.logbookMaxSize) << 20})
+		if err != nil {
+			log.Fatalf("open logbook: %v", err)
+		}
+		defer db.Close()
+		st.logbook = db
+	}
+
+	alarms, err := newAlarmEngine(opts.alarms)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Start web server
+	distFS, err := webDistFS()
+	if err != nil {
+		log.Fatalf("load embedded UI: %v", err)
+	}
+	if distFS == nil && !opts.devMode {
+		log.Fatal("no embedded UI available; rebuild with 'make build' or use --dev flag")
+	}
+
+	srv := server.New(server.Config{
+		Store:    store,
+		Stats:    stats,
+		TrackLog: trackLog,
+		Sessions: st.sessions,
+		Alarms:   alarms,
+		Logbook:  st.logbook,
+		MaxRate:  opts.maxRate,
+		Addr:     opts.webAddr,
+		WebFS:    distFS,
+		DevMode:  opts.devMode,
+	})
+	st.srv = srv
+
+	go func() {
+		if err := srv.ListenAndServe(ctx); err != nil {
+			log.Fatalf("web server: %v", err)
+		}
+	}()
+
+	log.Printf("Web UI: http://localhost%s", opts.webAddr)
+
+	go func() {
+		ticker := time.NewTicker(alarmInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, ev := range alarms.Evaluate(store.Snapshot(), now) {
+					log.Printf("[ALARM] %s", ev)
+					srv.PublishAlarm(ev)
+					if st.logbook != nil {
+						if err := st.logbook.RecordAlarm(ev); err != nil {
+							log.Printf("logbook: %v", err)
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	// Perf ticker: log attitude Hz every second
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w := stats.Snapshot().Windows[0]
+				if hz := w.Frames["attitude"]; hz > 0 {
+					log.Printf("Attitude: %.0f Hz", hz)
+				}
+			}
+		}
+	}()
+
+	readInput(ctx, r, byteCounter{w: parser, stats: stats})
+
+	log.Println("Shutting down...")
+
+	// A flight still armed keeps its latest summary; the logbook closes it
+	// when next opened.
+	if f, ok := st.sessions.Current(); ok {
+		st.recordFlight(f)
+	}
+
+	flights := st.sessions.List()
+
+	if opts.jsonOut {
+		statsJSON := struct {
+			UptimeSec    float64            `json:"uptime_sec"`
+			Total        int                `json:"total"`
+			FPS          float64            `json:"fps"`
+			Frames       map[byte]int       `json:"frames"`
+			CRCErrors    int                `json:"crc_errors"`
+			DecodeErrors int                `json:"decode_errors"`
+			Flights      []telemetry.Flight `json:"flights"`
+		}{
+			UptimeSec:    stats.Uptime().Seconds(),
+			Total:        stats.Total,
+			FPS:          stats.FPS(),
+			Frames:       stats.Frames,
+			CRCErrors:    stats.CRCErrors,
+			DecodeErrors: stats.DecodeErrors,
+			Flights:      flights,
+		}
+		json.NewEncoder(os.Stderr).Encode(statsJSON)
+	} else {
+		fmt.Fprintln(os.Stderr, stats.Summary())
+		for _, f := range flights {
+			fmt.Fprintf(os.Stderr, "--- Flight %d ---\n%s\n", f.ID, f.Summary)
+		}
+	}
+}
+
+// newAlarmEngine builds the alarm engine from a rules file, or from the
+// built-in rules when path is empty.
+func newAlarmEngine(path string) (*alarm.Engine, error) {
+	rules := alarm.DefaultRules
+	if path != "" {
+		var err error
+		if rules, err = alarm.LoadRules(path); err != nil {
+			return nil, fmt.Errorf("load alarm rules: %w", err)
+		}
+		log.Printf("Loaded %d alarm rules from %s", len(rules), path)
+	}
+	return alarm.NewEngine(rules)
+}
+
+// byteCounter records input throughput before handing bytes to the parser.
+type byteCounter struct {
+	w     io.Writer
+	stats *telemetry.Stats
+}
+
+func (c byteCounter) Write(p []byte) (int, error) {
+	c.stats.RecordBytes(len(p))
+	return c.w.Write(p)
+}
+
+// jsonLine is one line of -json output: the decoded frame, plus derived
+// navigation values on frames that change them.
+type jsonLine struct {
+	ltm.Frame
+	Derived *telemetry.Derived `json:"derived,omitempty"`
+}
+
+// handleFrame applies one decoded frame to the store, stats, track log and
+// console output.
+func (st *station) handleFrame(frame ltm.Frame) {
+	st.store.Update(frame)
+	st.stats.Count(frame.Function)
+	st.sessions.Update(frame)
+	if st.logbook != nil {
+		if err := st.logbook.RecordFrame(frame); err != nil && !st.logbookFailed {
+			log.Printf("logbook: %v (further frame errors are not logged)", err)
+			st.logbookFailed = true
+		}
+	}
+
+	if frame.GPS != nil {
+		if p, ok := st.store.Snapshot().TrackPoint(); ok {
+			st.trackLog.Append(p)
+		}
+	}
+
+	if frame.Extra != nil {
+		st.stats.RecordXCounter(frame.Extra.XCounter)
+	}
+	if st.opts.jsonOut {
+		line := jsonLine{Frame: frame}
+		if frame.GPS != nil || frame.Origin != nil {
+			line.Derived = st.store.Snapshot().Derived
+		}
+		st.enc.Encode(line)
+	} else {
+		printHuman(frame)
+	}
+}
@@ -2,10 +2,12 @@ module fpv-ground-station
 
 go 1.25
 
-require go.bug.st/serial v1.6.4
+require (
+	go.bug.st/serial v1.6.4
+	nhooyr.io/websocket v1.8.17
+)
 
 require (
 	github.com/creack/goselect v0.1.2 // indirect
 	golang.org/x/sys v0.19.0 // indirect
-	nhooyr.io/websocket v1.8.17 // indirect
 )
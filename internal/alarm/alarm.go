@@ -0,0 +1,166 @@
+// Package alarm evaluates threshold rules over telemetry snapshots and
+// reports alarms as they are raised and cleared.
+//
+// Each Rule compares one telemetry field against a threshold. A rule raises
+// once its condition has held for the debounce period, and clears once the
+// value has moved back past the threshold by the hysteresis margin for the
+// same period, so a value hovering at the threshold does not flap.
+package alarm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fpv-ground-station/internal/telemetry"
+)
+
+// Severity ranks how urgent an alarm is.
+type Severity string
+
+const (
+	Info     Severity = "info"
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Event states.
+const (
+	Raised  = "raised"
+	Cleared = "cleared"
+)
+
+// Event reports an alarm being raised or cleared.
+type Event struct {
+	Rule      string    `json:"rule"`
+	State     string    `json:"state"` // Raised or Cleared
+	Severity  Severity  `json:"severity"`
+	Message   string    `json:"message"`
+	Field     string    `json:"field"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Time      time.Time `json:"time"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s: %s (%s = %g, threshold %g)",
+		e.Severity, e.State, e.Message, e.Field, e.Value, e.Threshold)
+}
+
+// ruleState tracks one rule between evaluations.
+type ruleState struct {
+	active  bool
+	pending time.Time // when the condition for the next transition began; zero if none
+	raised  time.Time
+	value   float64
+}
+
+// Engine evaluates a fixed set of rules, safe for concurrent access.
+type Engine struct {
+	mu     sync.Mutex
+	rules  []Rule
+	states []ruleState
+}
+
+// NewEngine validates rules and creates an engine with every alarm clear.
+func NewEngine(rules []Rule) (*Engine, error) {
+	seen := make(map[string]bool, len(rules))
+	rs := make([]Rule, len(rules))
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("alarm: duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Severity == "" {
+			r.Severity = Warning
+		}
+		if r.Message == "" {
+			r.Message = r.Name
+		}
+		rs[i] = r
+	}
+	return &Engine{rules: rs, states: make([]ruleState, len(rs))}, nil
+}
+
+// Rules returns the engine's rules with defaults filled in.
+func (e *Engine) Rules() []Rule {
+	return append([]Rule(nil), e.rules...)
+}
+
+// Evaluate checks every rule against snap at time now and returns the
+// alarms raised or cleared by this evaluation, in rule order. Rules whose
+// field has no data in snap keep their current state.
+func (e *Engine) Evaluate(snap telemetry.Snapshot, now time.Time) []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var events []Event
+	for i, r := range e.rules {
+		v, ok := fields[r.Field](snap, now)
+		if !ok {
+			continue
+		}
+		st := &e.states[i]
+		st.value = v
+
+		// While clear, look for the raise condition; while raised, for the
+		// clear condition. Either must hold for the whole debounce period.
+		var transition bool
+		if st.active {
+			transition = r.clears(v)
+		} else {
+			transition = r.raises(v)
+		}
+		if !transition {
+			st.pending = time.Time{}
+			continue
+		}
+		if st.pending.IsZero() {
+			st.pending = now
+		}
+		if now.Sub(st.pending) < r.Debounce.Duration {
+			continue
+		}
+
+		st.pending = time.Time{}
+		st.active = !st.active
+		state := Cleared
+		if st.active {
+			state = Raised
+			st.raised = now
+		}
+		events = append(events, r.event(state, v, now))
+	}
+	return events
+}
+
+// Active returns the currently raised alarms in rule order. Each event's
+// Time is when the alarm was raised and Value is the latest reading.
+func (e *Engine) Active() []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var active []Event
+	for i, r := range e.rules {
+		if st := e.states[i]; st.active {
+			active = append(active, r.event(Raised, st.value, st.raised))
+		}
+	}
+	return active
+}
+
+func (r Rule) event(state string, v float64, t time.Time) Event {
+	return Event{
+		Rule:      r.Name,
+		State:     state,
+		Severity:  r.Severity,
+		Message:   r.Message,
+		Field:     r.Field,
+		Value:     v,
+		Threshold: r.Threshold,
+		Time:      t,
+	}
+}
@@ -0,0 +1,253 @@
+package alarm
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/telemetry"
+)
+
+func vbatSnap(v float64) telemetry.Snapshot {
+	return telemetry.Snapshot{Status: &ltm.StatusData{Vbat: v}}
+}
+
+func mustEngine(t *testing.T, rules ...Rule) *Engine {
+	t.Helper()
+	e, err := NewEngine(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestEngine_RaiseAndClear(t *testing.T) {
+	e := mustEngine(t, Rule{Name: "low", Field: "vbat", Op: "<", Threshold: 14, Severity: Critical})
+	t0 := time.Now()
+
+	if ev := e.Evaluate(vbatSnap(15), t0); len(ev) != 0 {
+		t.Fatalf("events = %+v, want none above threshold", ev)
+	}
+	ev := e.Evaluate(vbatSnap(13.8), t0.Add(time.Second))
+	if len(ev) != 1 || ev[0].State != Raised || ev[0].Severity != Critical || ev[0].Value != 13.8 {
+		t.Fatalf("events = %+v, want one critical raise at 13.8", ev)
+	}
+	if ev[0].Message != "low" {
+		t.Errorf("message = %q, want rule name by default", ev[0].Message)
+	}
+	if ev := e.Evaluate(vbatSnap(13.5), t0.Add(2*time.Second)); len(ev) != 0 {
+		t.Errorf("events = %+v, want no repeat while raised", ev)
+	}
+	if a := e.Active(); len(a) != 1 || a[0].Value != 13.5 || !a[0].Time.Equal(t0.Add(time.Second)) {
+		t.Errorf("active = %+v, want one alarm raised at t0+1s with latest value", a)
+	}
+
+	ev = e.Evaluate(vbatSnap(14.1), t0.Add(3*time.Second))
+	if len(ev) != 1 || ev[0].State != Cleared {
+		t.Fatalf("events = %+v, want one clear", ev)
+	}
+	if a := e.Active(); len(a) != 0 {
+		t.Errorf("active = %+v, want none after clear", a)
+	}
+}
+
+func TestEngine_Hysteresis(t *testing.T) {
+	e := mustEngine(t, Rule{Name: "low", Field: "vbat", Op: "<", Threshold: 14, Hysteresis: 0.3})
+	t0 := time.Now()
+
+	e.Evaluate(vbatSnap(13.9), t0)
+	if ev := e.Evaluate(vbatSnap(14.2), t0); len(ev) != 0 {
+		t.Errorf("events = %+v, want alarm held inside the hysteresis band", ev)
+	}
+	if ev := e.Evaluate(vbatSnap(14.3), t0); len(ev) != 1 || ev[0].State != Cleared {
+		t.Errorf("events = %+v, want clear at threshold + hysteresis", ev)
+	}
+}
+
+func TestEngine_Debounce(t *testing.T) {
+	e := mustEngine(t, Rule{Name: "low", Field: "vbat", Op: "<", Threshold: 14, Debounce: Duration{2 * time.Second}})
+	t0 := time.Now()
+
+	// A one-sample dip resets the debounce timer.
+	e.Evaluate(vbatSnap(13.5), t0)
+	e.Evaluate(vbatSnap(14.5), t0.Add(time.Second))
+	if ev := e.Evaluate(vbatSnap(13.5), t0.Add(2500*time.Millisecond)); len(ev) != 0 {
+		t.Fatalf("events = %+v, want none after interrupted dip", ev)
+	}
+	if ev := e.Evaluate(vbatSnap(13.5), t0.Add(4*time.Second)); len(ev) != 0 {
+		t.Fatalf("events = %+v, want none before debounce elapses", ev)
+	}
+	if ev := e.Evaluate(vbatSnap(13.5), t0.Add(4500*time.Millisecond)); len(ev) != 1 || ev[0].State != Raised {
+		t.Fatalf("events = %+v, want raise after 2s below threshold", ev)
+	}
+
+	// Clearing is debounced too.
+	e.Evaluate(vbatSnap(15), t0.Add(5*time.Second))
+	if ev := e.Evaluate(vbatSnap(15), t0.Add(6*time.Second)); len(ev) != 0 {
+		t.Errorf("events = %+v, want clear held back by debounce", ev)
+	}
+	if ev := e.Evaluate(vbatSnap(15), t0.Add(7*time.Second)); len(ev) != 1 || ev[0].State != Cleared {
+		t.Errorf("events = %+v, want clear after debounce", ev)
+	}
+}
+
+func TestEngine_MissingFieldKeepsState(t *testing.T) {
+	e := mustEngine(t, Rule{Name: "fix", Field: "gps_fix", Op: "<", Threshold: 2})
+	t0 := time.Now()
+
+	if ev := e.Evaluate(telemetry.Snapshot{}, t0); len(ev) != 0 {
+		t.Errorf("events = %+v, want none without GPS data", ev)
+	}
+	e.Evaluate(telemetry.Snapshot{GPS: &ltm.GPSData{Fix: 0}}, t0)
+	if ev := e.Evaluate(telemetry.Snapshot{}, t0); len(ev) != 0 || len(e.Active()) != 1 {
+		t.Errorf("alarm should stay raised when GPS data is missing")
+	}
+}
+
+func TestEngine_Failsafe(t *testing.T) {
+	e := mustEngine(t, DefaultRules...)
+	snap := telemetry.Snapshot{Status: &ltm.StatusData{Vbat: 16, RSSI: 200, Failsafe: true}}
+
+	ev := e.Evaluate(snap, time.Now())
+	if len(ev) != 1 || ev[0].Rule != "failsafe" || ev[0].Severity != Critical {
+		t.Errorf("events = %+v, want failsafe raised immediately", ev)
+	}
+}
+
+func TestEngine_NoSensors(t *testing.T) {
+	e := mustEngine(t, DefaultRules...)
+	snap := telemetry.Snapshot{Status: &ltm.StatusData{Vbat: 0, RSSI: 0}}
+
+	if ev := e.Evaluate(snap, time.Now()); len(ev) != 0 {
+		t.Errorf("events = %+v, want none without voltage or RSSI sensor", ev)
+	}
+}
+
+func TestNewEngine_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"no name", Rule{Field: "vbat", Op: "<"}, "no name"},
+		{"field", Rule{Name: "x", Field: "volts", Op: "<"}, "unknown field"},
+		{"op", Rule{Name: "x", Field: "vbat", Op: "=<"}, "unknown op"},
+		{"severity", Rule{Name: "x", Field: "vbat", Op: "<", Severity: "fatal"}, "unknown severity"},
+		{"hysteresis", Rule{Name: "x", Field: "vbat", Op: "<", Hysteresis: -1}, "negative"},
+	}
+	for _, tt := range tests {
+		_, err := NewEngine([]Rule{tt.rule})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	r := Rule{Name: "x", Field: "vbat", Op: "<"}
+	if _, err := NewEngine([]Rule{r, r}); err == nil {
+		t.Error("expected error for duplicate rule names")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alarms.json")
+	cfg := `{"rules": [
+		{"name": "far", "field": "distance_to_home", "op": ">", "threshold": 500,
+		 "hysteresis": 20, "debounce": "1.5s", "severity": "info", "message": "Far from home"}
+	]}`
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("rules = %+v, want 1", rules)
+	}
+	r := rules[0]
+	if r.Field != "distance_to_home" || r.Debounce.Duration != 1500*time.Millisecond || r.Severity != Info {
+		t.Errorf("rule = %+v", r)
+	}
+
+	os.WriteFile(path, []byte(`{"rules": [{"name": "x", "field": "vbat", "op": "<", "debounce": 5}]}`), 0o644)
+	if _, err := LoadRules(path); err == nil {
+		t.Error("expected error for numeric debounce")
+	}
+}
+
+func TestEngine_LinkState(t *testing.T) {
+	e := mustEngine(t,
+		Rule{Name: "stale", Field: "link_state", Op: "==", Threshold: 1},
+		Rule{Name: "lost", Field: "attitude_state", Op: ">=", Threshold: 2, Severity: Critical},
+	)
+	t0 := time.Now()
+	snap := telemetry.Snapshot{AttitudeTime: t0, StatusTime: t0, Status: &ltm.StatusData{}}
+
+	if ev := e.Evaluate(snap, t0); len(ev) != 0 {
+		t.Fatalf("events = %+v, want none while fresh", ev)
+	}
+	ev := e.Evaluate(snap, t0.Add(time.Second))
+	if len(ev) != 1 || ev[0].Rule != "stale" || ev[0].State != Raised {
+		t.Fatalf("events = %+v, want stale raised", ev)
+	}
+	ev = e.Evaluate(snap, t0.Add(5*time.Second))
+	if len(ev) != 2 || ev[0].State != Cleared || ev[1].Rule != "lost" || ev[1].State != Raised {
+		t.Errorf("events = %+v, want stale cleared and lost raised", ev)
+	}
+}
+
+func TestEngine_LinkStateProtocolRates(t *testing.T) {
+	tests := []struct {
+		protocol         string
+		attitude, status time.Duration // frame intervals
+		dropEvery        int           // drop every Nth attitude frame; 0 drops none
+	}{
+		// ATTITUDE at 2 Hz and HEARTBEAT at 1 Hz.
+		{"mavlink", 500 * time.Millisecond, time.Second, 0},
+		// Attitude at 1 Hz with a frame lost now and then: a two-second gap
+		// is a lost link at LTM's 10 Hz, but not on a slow CRSF link.
+		{"crsf", time.Second, time.Second, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			e := mustEngine(t, DefaultRules...)
+			t0 := time.Now()
+			snap := telemetry.Snapshot{Protocol: tt.protocol, Status: &ltm.StatusData{}}
+
+			// Evaluated four times a second as the station does, a healthy
+			// link raises nothing.
+			step := 250 * time.Millisecond
+			for at := time.Duration(0); at <= 30*time.Second; at += step {
+				now := t0.Add(at)
+				if n := int(at / tt.attitude); at%tt.attitude == 0 && (tt.dropEvery == 0 || n%tt.dropEvery != tt.dropEvery-1) {
+					snap.AttitudeTime = now
+				}
+				if at%tt.status == 0 {
+					snap.StatusTime = now
+				}
+				if ev := e.Evaluate(snap, now); len(ev) != 0 {
+					t.Fatalf("events at %v = %+v, want none on a healthy link", at, ev)
+				}
+			}
+
+			// Once frames stop, the link still goes stale and then lost.
+			last := t0.Add(30 * time.Second)
+			var raised []string
+			for at := step; at <= 20*time.Second; at += step {
+				for _, ev := range e.Evaluate(snap, last.Add(at)) {
+					if ev.State == Raised {
+						raised = append(raised, ev.Rule)
+					}
+				}
+			}
+			if !slices.Equal(raised, []string{"telemetry_stale", "link_lost"}) {
+				t.Errorf("raised = %v, want telemetry_stale then link_lost", raised)
+			}
+		})
+	}
+}
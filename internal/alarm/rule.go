@@ -0,0 +1,278 @@
+package alarm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/telemetry"
+)
+
+// Rule raises an alarm when Field compares true against Threshold.
+//
+// For the ordering operators, Hysteresis widens the clear condition: a
+// "<" rule with threshold 14 and hysteresis 0.3 raises below 14 V and
+// clears at or above 14.3 V. Equality operators ignore Hysteresis.
+type Rule struct {
+	Name       string   `json:"name"`
+	Field      string   `json:"field"`
+	Op         string   `json:"op"` // <, <=, >, >=, == or !=
+	Threshold  float64  `json:"threshold"`
+	Hysteresis float64  `json:"hysteresis,omitempty"`
+	Debounce   Duration `json:"debounce,omitzero"`
+	Severity   Severity `json:"severity,omitempty"` // default warning
+	Message    string   `json:"message,omitempty"`  // default Name
+}
+
+// DefaultRules are used when no rules file is given. The voltage
+// thresholds suit a 4S pack (3.5 V and 3.3 V per cell).
+var DefaultRules = []Rule{
+	{Name: "battery_low", Field: "vbat", Op: "<", Threshold: 14.0, Hysteresis: 0.3,
+		Debounce: Duration{5 * time.Second}, Severity: Warning, Message: "Battery low"},
+	{Name: "battery_critical", Field: "vbat", Op: "<", Threshold: 13.2, Hysteresis: 0.3,
+		Debounce: Duration{5 * time.Second}, Severity: Critical, Message: "Battery critical"},
+	{Name: "rssi_low", Field: "rssi", Op: "<", Threshold: 70, Hysteresis: 10,
+		Debounce: Duration{2 * time.Second}, Severity: Warning, Message: "RSSI low"},
+	{Name: "gps_fix_lost", Field: "gps_fix", Op: "<", Threshold: 2,
+		Debounce: Duration{2 * time.Second}, Severity: Warning, Message: "GPS fix lost"},
+	{Name: "failsafe", Field: "failsafe", Op: "==", Threshold: 1,
+		Severity: Critical, Message: "Failsafe"},
+	{Name: "telemetry_stale", Field: "link_state", Op: "==", Threshold: 1,
+		Debounce: Duration{time.Second}, Severity: Warning, Message: "Telemetry stale"},
+	{Name: "link_lost", Field: "link_state", Op: "==", Threshold: 2,
+		Severity: Critical, Message: "Telemetry link lost"},
+}
+
+// fields maps rule field names to their value in a snapshot. A field
+// reports false when the snapshot has no data for it.
+var fields = map[string]func(s telemetry.Snapshot, now time.Time) (float64, bool){
+	"vbat": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		// 0 V means the flight controller has no voltage sensor.
+		if s.Status == nil || s.Status.Vbat == 0 {
+			return 0, false
+		}
+		return s.Status.Vbat, true
+	},
+	"mah_drawn": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Status == nil {
+			return 0, false
+		}
+		return float64(s.Status.MAhDrawn), true
+	},
+	"rssi": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		// RSSI 0 means the flight controller has no RSSI source.
+		if s.Status == nil || s.Status.RSSI == 0 {
+			return 0, false
+		}
+		return float64(s.Status.RSSI), true
+	},
+	"armed": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Status == nil {
+			return 0, false
+		}
+		return boolValue(s.Status.Armed), true
+	},
+	"failsafe": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Status == nil {
+			return 0, false
+		}
+		return boolValue(s.Status.Failsafe), true
+	},
+	"gps_fix": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.GPS == nil {
+			return 0, false
+		}
+		return float64(s.GPS.Fix), true
+	},
+	"sats": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.GPS == nil {
+			return 0, false
+		}
+		return float64(s.GPS.Sats), true
+	},
+	"altitude": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.GPS == nil {
+			return 0, false
+		}
+		return s.GPS.Altitude, true
+	},
+	"ground_speed": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.GPS == nil {
+			return 0, false
+		}
+		return float64(s.GPS.GroundSpeed), true
+	},
+	"hdop": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Extra == nil {
+			return 0, false
+		}
+		return s.Extra.HDOP, true
+	},
+	"distance_to_home": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Derived == nil || !s.Derived.HomeValid {
+			return 0, false
+		}
+		return s.Derived.DistanceToHome, true
+	},
+	"climb_rate": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.Derived == nil {
+			return 0, false
+		}
+		return s.Derived.ClimbRate, true
+	},
+	"uplink_lq": func(s telemetry.Snapshot, _ time.Time) (float64, bool) {
+		if s.CRSFLink == nil {
+			return 0, false
+		}
+		return float64(s.CRSFLink.UplinkLQ), true
+	},
+}
+
+func init() {
+	fields["link_state"] = func(s telemetry.Snapshot, now time.Time) (float64, bool) {
+		return stateValue(s.Freshness(now).Overall)
+	}
+	for fn := range telemetry.ExpectedRate {
+		name := strings.ToLower(ltm.FrameName[fn])
+		fields[name+"_state"] = func(s telemetry.Snapshot, now time.Time) (float64, bool) {
+			return stateValue(s.Freshness(now).Streams[name].State)
+		}
+	}
+}
+
+// stateValue maps a stream state to a rule value: 0 fresh, 1 stale, 2 lost.
+// A stream that was never received has no value.
+func stateValue(st telemetry.StreamState) (float64, bool) {
+	switch st {
+	case telemetry.StreamFresh:
+		return 0, true
+	case telemetry.StreamStale:
+		return 1, true
+	case telemetry.StreamLost:
+		return 2, true
+	}
+	return 0, false
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Fields returns the field names rules may refer to, sorted.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("alarm: rule has no name")
+	}
+	if _, ok := fields[r.Field]; !ok {
+		return fmt.Errorf("alarm: rule %q: unknown field %q (want one of %s)", r.Name, r.Field, strings.Join(Fields(), ", "))
+	}
+	switch r.Op {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return fmt.Errorf("alarm: rule %q: unknown op %q", r.Name, r.Op)
+	}
+	switch r.Severity {
+	case "", Info, Warning, Critical:
+	default:
+		return fmt.Errorf("alarm: rule %q: unknown severity %q", r.Name, r.Severity)
+	}
+	if r.Hysteresis < 0 || r.Debounce.Duration < 0 {
+		return fmt.Errorf("alarm: rule %q: hysteresis and debounce must not be negative", r.Name)
+	}
+	return nil
+}
+
+// raises reports whether v meets the rule's alarm condition.
+func (r Rule) raises(v float64) bool {
+	t := r.Threshold
+	switch r.Op {
+	case "<":
+		return v < t
+	case "<=":
+		return v <= t
+	case ">":
+		return v > t
+	case ">=":
+		return v >= t
+	case "==":
+		return v == t
+	case "!=":
+		return v != t
+	}
+	return false
+}
+
+// clears reports whether v is far enough past the threshold to clear a
+// raised alarm.
+func (r Rule) clears(v float64) bool {
+	t, h := r.Threshold, r.Hysteresis
+	switch r.Op {
+	case "<", "<=":
+		return v >= t+h && !r.raises(v)
+	case ">", ">=":
+		return v <= t-h && !r.raises(v)
+	}
+	return !r.raises(v)
+}
+
+// Duration is a time.Duration that reads and writes JSON as a string
+// such as "1.5s".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"2s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// Config is the alarm rules file format.
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads a JSON rules file. The file's rules replace DefaultRules.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("alarm: parse %s: %w", path, err)
+	}
+	for _, r := range cfg.Rules {
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return cfg.Rules, nil
+}
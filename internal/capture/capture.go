@@ -0,0 +1,180 @@
+// Package capture records raw telemetry byte streams to disk and replays
+// them back through the same parsing pipeline.
+//
+// A capture file starts with an 8-byte magic followed by one record per
+// chunk read from the link:
+//
+//	offset  uint64  nanoseconds since recording started (little-endian)
+//	length  uint32  chunk size in bytes (little-endian)
+//	data    [length]byte
+//
+// Offsets are taken from the monotonic clock, so replays keep the original
+// inter-chunk timing even if the wall clock jumped while recording.
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Magic identifies a capture file and its format version.
+const Magic = "FPVCAP01"
+
+// MaxChunk is the largest chunk a record may hold. Anything larger is
+// treated as corruption rather than allocated.
+const MaxChunk = 1 << 20
+
+const recordHeaderSize = 12
+
+var ErrBadMagic = errors.New("capture: not a capture file")
+
+// Chunk is a single recorded read.
+type Chunk struct {
+	Offset time.Duration // since recording started
+	Data   []byte
+}
+
+// Writer appends timestamped chunks to a capture file. It implements
+// io.Writer so it can sit behind an io.TeeReader on the input stream.
+type Writer struct {
+	mu    sync.Mutex
+	w     io.Writer
+	c     io.Closer
+	start time.Time
+}
+
+// Create creates (or truncates) a capture file at path.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.c = f
+	return w, nil
+}
+
+// NewWriter writes the capture header to w and starts the clock.
+func NewWriter(w io.Writer) (*Writer, error) {
+	if _, err := io.WriteString(w, Magic); err != nil {
+		return nil, fmt.Errorf("capture: write header: %w", err)
+	}
+	return &Writer{w: w, start: time.Now()}, nil
+}
+
+// Write records p as one chunk. Each record is written with a single call
+// so a crash leaves at most one truncated record at the tail.
+func (w *Writer) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(p) > MaxChunk {
+		return 0, fmt.Errorf("capture: chunk too large (%d > %d)", len(p), MaxChunk)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rec := make([]byte, recordHeaderSize+len(p))
+	putRecordHeader(rec, time.Since(w.start), len(p))
+	copy(rec[recordHeaderSize:], p)
+
+	if _, err := w.w.Write(rec); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying file, if the Writer owns one.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.c == nil {
+		return nil
+	}
+	return w.c.Close()
+}
+
+// Reader reads chunks back from a capture file.
+type Reader struct {
+	r *bufio.Reader
+	c io.Closer
+}
+
+// Open opens a capture file for reading.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.c = f
+	return r, nil
+}
+
+// NewReader validates the capture header on r.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	hdr := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, hdr); err != nil || string(hdr) != Magic {
+		return nil, ErrBadMagic
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next chunk, or io.EOF at the end of the file. A truncated
+// trailing record, as left behind by a crash, is treated as end of file.
+func (r *Reader) Next() (Chunk, error) {
+	var hdr [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		return Chunk{}, eofOr(err)
+	}
+
+	n := binary.LittleEndian.Uint32(hdr[8:])
+	if n > MaxChunk {
+		return Chunk{}, fmt.Errorf("capture: chunk too large (%d > %d)", n, MaxChunk)
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Chunk{}, eofOr(err)
+	}
+	return Chunk{
+		Offset: time.Duration(binary.LittleEndian.Uint64(hdr[0:])),
+		Data:   data,
+	}, nil
+}
+
+// Close closes the underlying file, if the Reader owns one.
+func (r *Reader) Close() error {
+	if r.c == nil {
+		return nil
+	}
+	return r.c.Close()
+}
+
+func putRecordHeader(b []byte, offset time.Duration, n int) {
+	binary.LittleEndian.PutUint64(b[0:], uint64(offset))
+	binary.LittleEndian.PutUint32(b[8:], uint32(n))
+}
+
+func eofOr(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
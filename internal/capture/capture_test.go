@@ -0,0 +1,172 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriterReader_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cap")
+
+	w, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := [][]byte{
+		[]byte("$TA"),
+		{0x01, 0x02, 0x03},
+		[]byte("$TG"),
+	}
+	for _, c := range chunks {
+		if _, err := w.Write(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var last time.Duration
+	for i, want := range chunks {
+		c, err := r.Next()
+		if err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		if !bytes.Equal(c.Data, want) {
+			t.Errorf("chunk %d = %x, want %x", i, c.Data, want)
+		}
+		if c.Offset < last {
+			t.Errorf("chunk %d offset %v < previous %v", i, c.Offset, last)
+		}
+		last = c.Offset
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("after last chunk err = %v, want io.EOF", err)
+	}
+}
+
+func TestWriter_SkipsEmptyChunks(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf)
+	w.Write(nil)
+
+	if buf.Len() != len(Magic) {
+		t.Errorf("file size = %d, want header only (%d)", buf.Len(), len(Magic))
+	}
+}
+
+func TestReader_BadMagic(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("lat,lon\n")))
+	if err != ErrBadMagic {
+		t.Errorf("err = %v, want ErrBadMagic", err)
+	}
+}
+
+func TestReader_TruncatedTail(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf)
+	w.Write([]byte{0xAA, 0xBB})
+	w.Write([]byte{0xCC, 0xDD, 0xEE})
+
+	data := buf.Bytes()[:buf.Len()-2] // cut into the second record
+
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("first chunk: %v", err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("truncated chunk err = %v, want io.EOF", err)
+	}
+}
+
+func TestPlayer_AsFastAsPossible(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf)
+	w.Write([]byte("abc"))
+	w.Write([]byte("defg"))
+
+	r, _ := NewReader(&buf)
+	p := NewPlayer(context.Background(), r, 0)
+
+	got, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abcdefg" {
+		t.Errorf("replayed %q, want %q", got, "abcdefg")
+	}
+}
+
+func TestPlayer_PreservesChunkBoundaries(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf)
+	w.Write([]byte("abc"))
+	w.Write([]byte("defg"))
+
+	r, _ := NewReader(&buf)
+	p := NewPlayer(context.Background(), r, 0)
+
+	b := make([]byte, 256)
+	n, _ := p.Read(b)
+	if string(b[:n]) != "abc" {
+		t.Errorf("first read = %q, want %q", b[:n], "abc")
+	}
+	n, _ = p.Read(b)
+	if string(b[:n]) != "defg" {
+		t.Errorf("second read = %q, want %q", b[:n], "defg")
+	}
+}
+
+func TestPlayer_PacesBySpeed(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(Magic)
+	for _, off := range []time.Duration{0, 200 * time.Millisecond} {
+		rec := make([]byte, recordHeaderSize+1)
+		putRecordHeader(rec, off, 1)
+		buf.Write(rec)
+	}
+
+	r, _ := NewReader(&buf)
+	p := NewPlayer(context.Background(), r, 2) // 200ms at 2x = 100ms
+
+	start := time.Now()
+	if _, err := io.ReadAll(p); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 90*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("replay took %v, want ~100ms", elapsed)
+	}
+}
+
+func TestPlayer_ContextCancel(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(Magic)
+	for _, off := range []time.Duration{0, time.Hour} {
+		rec := make([]byte, recordHeaderSize+1)
+		putRecordHeader(rec, off, 1)
+		buf.Write(rec)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	r, _ := NewReader(&buf)
+	p := NewPlayer(ctx, r, 1)
+
+	_, err := io.ReadAll(p)
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
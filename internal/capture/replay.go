@@ -0,0 +1,65 @@
+package capture
+
+import (
+	"context"
+	"time"
+)
+
+// Player replays a capture as an io.Reader, pacing chunks by their recorded
+// offsets. Each Read returns at most one recorded chunk, so a parser sees the
+// same chunk boundaries it saw live as long as its buffer is large enough.
+type Player struct {
+	ctx     context.Context
+	r       *Reader
+	speed   float64
+	start   time.Time
+	pending []byte
+}
+
+// NewPlayer creates a Player reading from r. Speed 1 replays in real time,
+// N replays N times faster, and 0 (or less) replays as fast as possible.
+func NewPlayer(ctx context.Context, r *Reader, speed float64) *Player {
+	return &Player{ctx: ctx, r: r, speed: speed}
+}
+
+// Read implements io.Reader. It blocks until the next chunk is due and
+// returns io.EOF once the capture is exhausted.
+func (p *Player) Read(buf []byte) (int, error) {
+	if len(p.pending) == 0 {
+		c, err := p.r.Next()
+		if err != nil {
+			return 0, err
+		}
+		if err := p.wait(c.Offset); err != nil {
+			return 0, err
+		}
+		p.pending = c.Data
+	}
+
+	n := copy(buf, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *Player) wait(offset time.Duration) error {
+	if p.speed <= 0 {
+		return p.ctx.Err()
+	}
+	if p.start.IsZero() {
+		p.start = time.Now().Add(-time.Duration(float64(offset) / p.speed))
+	}
+
+	d := time.Until(p.start.Add(time.Duration(float64(offset) / p.speed)))
+	if d <= 0 {
+		return p.ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+package crsf
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// Flight mode strings sent by INAV and Betaflight → LTM flight mode.
+var flightModes = map[string]uint8{
+	"MANU": ltm.ModeManual,
+	"ACRO": ltm.ModeAcro,
+	"AIR":  ltm.ModeAcro,
+	"ANGL": ltm.ModeAngle,
+	"STAB": ltm.ModeAngle,
+	"HOR":  ltm.ModeHorizon,
+	"AH":   ltm.ModeAltHold,
+	"ALTH": ltm.ModeAltHold,
+	"HOLD": ltm.ModeGPSHold,
+	"POSH": ltm.ModeGPSHold,
+	"WP":   ltm.ModeWaypoints,
+	"RTH":  ltm.ModeRTH,
+	"LAND": ltm.ModeLand,
+	"CRS":  ltm.ModeCruise,
+	"CRUZ": ltm.ModeCruise,
+	"3CRS": ltm.ModeCruise,
+	"LNCH": ltm.ModeLaunch,
+}
+
+// minSatsForFix is the satellite count treated as a 3D fix; CRSF GPS frames
+// carry no fix type.
+const minSatsForFix = 4
+
+// Converter maps decoded CRSF frames onto the LTM frame types used by
+// telemetry.Store.
+//
+// Battery, flight mode and link statistics frames all feed the LTM status
+// frame, so the Converter keeps the merged status and emits a full copy
+// whenever one of them arrives.
+//
+// CRSF GPS altitude is above sea level, while LTM's is relative to home. The
+// Converter takes the first 3D fix, and the first after each arming, as home,
+// emits it as an origin frame and reports altitude relative to it.
+type Converter struct {
+	status ltm.StatusData
+
+	homeAlt  float64
+	haveHome bool
+	rehome   bool // armed since home was set
+}
+
+// NewConverter creates an empty Converter.
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+// Frames folds f into the converter state and returns the LTM frames it
+// updates. The result may be empty.
+func (c *Converter) Frames(f Frame) []ltm.Frame {
+	switch {
+	case f.GPS != nil:
+		g := f.GPS
+		fix := uint8(0)
+		if g.Sats >= minSatsForFix && (g.Lat != 0 || g.Lon != 0) {
+			fix = 3
+		}
+		var frames []ltm.Frame
+		if fix == 3 && (!c.haveHome || c.rehome) {
+			c.homeAlt, c.haveHome, c.rehome = float64(g.Altitude), true, false
+			frames = append(frames, ltm.Frame{
+				Function: ltm.FuncOrigin,
+				Name:     ltm.FrameName[ltm.FuncOrigin],
+				Time:     f.Time,
+				Origin: &ltm.OriginData{
+					Lat:   g.Lat,
+					Lon:   g.Lon,
+					Alt:   c.homeAlt,
+					OSDOn: true,
+					Fix:   1,
+				},
+			})
+		}
+		// Without a home there is no reference for the altitude yet.
+		alt := 0.0
+		if c.haveHome {
+			alt = float64(g.Altitude) - c.homeAlt
+		}
+		return append(frames, ltm.Frame{
+			Function: ltm.FuncGPS,
+			Name:     ltm.FrameName[ltm.FuncGPS],
+			Time:     f.Time,
+			GPS: &ltm.GPSData{
+				Lat:         g.Lat,
+				Lon:         g.Lon,
+				GroundSpeed: ltm.ClampUint8(g.GroundSpeed / 3.6),
+				Altitude:    alt,
+				Fix:         fix,
+				Sats:        g.Sats,
+			},
+		})
+
+	case f.Attitude != nil:
+		a := f.Attitude
+		return []ltm.Frame{{
+			Function: ltm.FuncAttitude,
+			Name:     ltm.FrameName[ltm.FuncAttitude],
+			Time:     f.Time,
+			Attitude: &ltm.AttitudeData{
+				Pitch:   int16(math.Round(a.Pitch)),
+				Roll:    int16(math.Round(a.Roll)),
+				Heading: int16(math.Round(math.Mod(a.Yaw+360, 360))) % 360,
+			},
+		}}
+
+	case f.Battery != nil:
+		c.status.Vbat = f.Battery.Voltage
+		c.status.MAhDrawn = uint16(min(f.Battery.Capacity, math.MaxUint16))
+		return []ltm.Frame{c.statusFrame(f.Time)}
+
+	case f.LinkStatistics != nil:
+		// LTM RSSI is 0-254; uplink link quality is the closest equivalent.
+		c.status.RSSI = uint8(min(int(f.LinkStatistics.UplinkLQ), 100) * 254 / 100)
+		return []ltm.Frame{c.statusFrame(f.Time)}
+
+	case f.Type == TypeFlightMode:
+		c.flightMode(f.FlightMode)
+		return []ltm.Frame{c.statusFrame(f.Time)}
+	}
+	return nil
+}
+
+// flightMode interprets the flight mode string. Betaflight appends '*' while
+// disarmed; INAV reports "OK", "WAIT" or "!ERR" instead of a mode name.
+func (c *Converter) flightMode(s string) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "!FS!":
+		c.status.Failsafe = true
+		return
+	case s == "OK" || s == "WAIT" || s == "!ERR":
+		c.status.Armed = false
+		c.status.Failsafe = false
+		c.status.FlightMode = ltm.ModeUnknown
+		return
+	}
+
+	armed := !strings.HasSuffix(s, "*")
+	if armed && !c.status.Armed {
+		c.rehome = true
+	}
+	c.status.Armed = armed
+	c.status.Failsafe = false
+	mode, ok := flightModes[strings.TrimSuffix(s, "*")]
+	if !ok {
+		mode = ltm.ModeUnknown
+	}
+	c.status.FlightMode = mode
+}
+
+func (c *Converter) statusFrame(t time.Time) ltm.Frame {
+	status := c.status
+	return ltm.Frame{Function: ltm.FuncStatus, Name: ltm.FrameName[ltm.FuncStatus], Time: t, Status: &status}
+}
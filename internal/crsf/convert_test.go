@@ -0,0 +1,113 @@
+package crsf
+
+import (
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func TestConverter_GPS(t *testing.T) {
+	c := NewConverter()
+	frames := c.Frames(Frame{Type: TypeGPS, Time: time.Now(), GPS: &GPSData{
+		Lat: 51.5, Lon: -0.1, GroundSpeed: 36, Altitude: 120, Sats: 9,
+	}})
+	if len(frames) != 2 || frames[0].Origin == nil || frames[1].GPS == nil {
+		t.Fatalf("frames = %+v, want origin and GPS frames", frames)
+	}
+	if o := frames[0].Origin; o.Lat != 51.5 || o.Lon != -0.1 || o.Alt != 120 {
+		t.Errorf("origin = %+v", *o)
+	}
+	g := frames[1].GPS
+	if g.GroundSpeed != 10 || g.Altitude != 0 || g.Fix != 3 || g.Sats != 9 {
+		t.Errorf("gps = %+v", *g)
+	}
+
+	frames = c.Frames(Frame{Type: TypeGPS, GPS: &GPSData{Sats: 2}})
+	if len(frames) != 1 || frames[0].GPS.Fix != 0 {
+		t.Errorf("frames = %+v, want one GPS frame without fix", frames)
+	}
+}
+
+func TestConverter_HomeAltitude(t *testing.T) {
+	c := NewConverter()
+	gps := func(alt int) []ltm.Frame {
+		return c.Frames(Frame{Type: TypeGPS, GPS: &GPSData{Lat: 51.5, Lon: -0.1, Altitude: alt, Sats: 9}})
+	}
+
+	if frames := c.Frames(Frame{Type: TypeGPS, GPS: &GPSData{Altitude: 300, Sats: 2}}); frames[0].GPS.Altitude != 0 {
+		t.Errorf("altitude before home = %v, want 0", frames[0].GPS.Altitude)
+	}
+	gps(100)
+	if frames := gps(130); len(frames) != 1 || frames[0].GPS.Altitude != 30 {
+		t.Errorf("frames = %+v, want one GPS frame at 30 m", frames)
+	}
+
+	// Arming takes the next fix as the new home.
+	c.Frames(Frame{Type: TypeFlightMode, FlightMode: "ANGL"})
+	frames := gps(110)
+	if len(frames) != 2 || frames[0].Origin.Alt != 110 || frames[1].GPS.Altitude != 0 {
+		t.Fatalf("frames after arming = %+v, want origin at 110 m and GPS at 0 m", frames)
+	}
+	c.Frames(Frame{Type: TypeFlightMode, FlightMode: "HOR"}) // mode change while armed
+	if frames := gps(150); len(frames) != 1 || frames[0].GPS.Altitude != 40 {
+		t.Errorf("frames = %+v, want one GPS frame at 40 m", frames)
+	}
+}
+
+func TestConverter_Attitude(t *testing.T) {
+	c := NewConverter()
+	frames := c.Frames(Frame{Type: TypeAttitude, Attitude: &AttitudeData{Pitch: 4.6, Roll: -10.2, Yaw: -90}})
+	a := frames[0].Attitude
+	if a.Pitch != 5 || a.Roll != -10 || a.Heading != 270 {
+		t.Errorf("attitude = %+v", *a)
+	}
+}
+
+func TestConverter_StatusMerging(t *testing.T) {
+	c := NewConverter()
+	c.Frames(Frame{Type: TypeBattery, Battery: &BatteryData{Voltage: 15.2, Capacity: 640}})
+	c.Frames(Frame{Type: TypeLinkStatistics, LinkStatistics: &LinkStatistics{UplinkLQ: 50}})
+	frames := c.Frames(Frame{Type: TypeFlightMode, FlightMode: "RTH"})
+
+	s := frames[0].Status
+	if s.Vbat != 15.2 || s.MAhDrawn != 640 || s.RSSI != 127 {
+		t.Errorf("status = %+v", *s)
+	}
+	if !s.Armed || ltm.FlightModeName[s.FlightMode] != "RTH" {
+		t.Errorf("armed = %v, mode = %q", s.Armed, ltm.FlightModeName[s.FlightMode])
+	}
+}
+
+func TestConverter_FlightModeStrings(t *testing.T) {
+	tests := []struct {
+		mode     string
+		armed    bool
+		failsafe bool
+		name     string
+	}{
+		{"ACRO", true, false, "Acro"},
+		{"ANGL*", false, false, "Angle"}, // Betaflight disarmed
+		{"OK", false, false, "Unknown"},  // INAV disarmed
+		{"WAIT", false, false, "Unknown"},
+		{"CRUZ", true, false, "Cruise"},
+		{"XYZ", true, false, "Unknown"},
+	}
+	for _, tt := range tests {
+		c := NewConverter()
+		s := c.Frames(Frame{Type: TypeFlightMode, FlightMode: tt.mode})[0].Status
+		if s.Armed != tt.armed || s.Failsafe != tt.failsafe || ltm.FlightModeName[s.FlightMode] != tt.name {
+			t.Errorf("%q: armed=%v failsafe=%v mode=%q, want %v %v %q",
+				tt.mode, s.Armed, s.Failsafe, ltm.FlightModeName[s.FlightMode], tt.armed, tt.failsafe, tt.name)
+		}
+	}
+}
+
+func TestConverter_FailsafeKeepsMode(t *testing.T) {
+	c := NewConverter()
+	c.Frames(Frame{Type: TypeFlightMode, FlightMode: "HOLD"})
+	s := c.Frames(Frame{Type: TypeFlightMode, FlightMode: "!FS!"})[0].Status
+	if !s.Failsafe || !s.Armed || ltm.FlightModeName[s.FlightMode] != "GPS Hold" {
+		t.Errorf("status = %+v", *s)
+	}
+}
@@ -0,0 +1,17 @@
+package crsf
+
+// crc8 returns the CRC-8/DVB-S2 checksum (polynomial 0xD5) of data.
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0xD5
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,94 @@
+package crsf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Decode interprets a RawFrame's payload and returns a typed Frame.
+// Multi-byte CRSF fields are big-endian.
+func Decode(raw RawFrame) (Frame, error) {
+	f := Frame{
+		Type: raw.Type,
+		Name: FrameName[raw.Type],
+		Time: time.Now(),
+	}
+
+	if size, ok := PayloadSize[raw.Type]; ok && len(raw.Payload) < size {
+		return f, fmt.Errorf("crsf: %s payload is %d bytes, want %d", f.Name, len(raw.Payload), size)
+	}
+
+	p := raw.Payload
+	switch raw.Type {
+	case TypeGPS:
+		f.GPS = decodeGPS(p)
+	case TypeBattery:
+		f.Battery = decodeBattery(p)
+	case TypeLinkStatistics:
+		f.LinkStatistics = decodeLinkStatistics(p)
+	case TypeAttitude:
+		f.Attitude = decodeAttitude(p)
+	case TypeFlightMode:
+		if i := bytes.IndexByte(p, 0); i >= 0 {
+			p = p[:i]
+		}
+		f.FlightMode = string(p)
+	default:
+		return f, fmt.Errorf("crsf: unknown frame type 0x%02X", raw.Type)
+	}
+	return f, nil
+}
+
+func decodeGPS(p []byte) *GPSData {
+	return &GPSData{
+		Lat:         float64(int32(binary.BigEndian.Uint32(p[0:]))) / 1e7,
+		Lon:         float64(int32(binary.BigEndian.Uint32(p[4:]))) / 1e7,
+		GroundSpeed: float64(binary.BigEndian.Uint16(p[8:])) / 10,
+		Heading:     float64(binary.BigEndian.Uint16(p[10:])) / 100,
+		Altitude:    int(binary.BigEndian.Uint16(p[12:])) - 1000,
+		Sats:        p[14],
+	}
+}
+
+func decodeBattery(p []byte) *BatteryData {
+	return &BatteryData{
+		Voltage:   float64(binary.BigEndian.Uint16(p[0:])) / 10,
+		Current:   float64(binary.BigEndian.Uint16(p[2:])) / 10,
+		Capacity:  uint32(p[4])<<16 | uint32(p[5])<<8 | uint32(p[6]),
+		Remaining: p[7],
+	}
+}
+
+func decodeLinkStatistics(p []byte) *LinkStatistics {
+	l := &LinkStatistics{
+		UplinkRSSI1:   -int(p[0]),
+		UplinkRSSI2:   -int(p[1]),
+		UplinkLQ:      p[2],
+		UplinkSNR:     int8(p[3]),
+		ActiveAntenna: p[4],
+		RFMode:        p[5],
+		DownlinkRSSI:  -int(p[7]),
+		DownlinkLQ:    p[8],
+		DownlinkSNR:   int8(p[9]),
+	}
+	if int(p[6]) < len(TxPowerMW) {
+		l.UplinkTxPower = TxPowerMW[p[6]]
+	}
+	return l
+}
+
+func decodeAttitude(p []byte) *AttitudeData {
+	return &AttitudeData{
+		Pitch: angle(p[0:]),
+		Roll:  angle(p[2:]),
+		Yaw:   angle(p[4:]),
+	}
+}
+
+// angle converts a big-endian int16 in radians × 10000 to degrees.
+func angle(b []byte) float64 {
+	return float64(int16(binary.BigEndian.Uint16(b))) / 10000 * 180 / math.Pi
+}
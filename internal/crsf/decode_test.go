@@ -0,0 +1,75 @@
+package crsf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecodeGPS(t *testing.T) {
+	p := []byte{
+		0x1E, 0xB2, 0x46, 0xC0, // 51.5 * 1e7 = 515000000
+		0xFF, 0xEC, 0x7F, 0xD0, // -0.1278 * 1e7 = -1278000
+		0x01, 0xF4, // 50.0 km/h
+		0x46, 0x50, // 180.00 deg
+		0x04, 0x4C, // 100 m (+1000 offset)
+		12,
+	}
+	f, err := Decode(RawFrame{Type: TypeGPS, Payload: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := f.GPS
+	if g.Lat != 51.5 || math.Abs(g.Lon+0.1278) > 1e-9 {
+		t.Errorf("position = %f,%f", g.Lat, g.Lon)
+	}
+	if g.GroundSpeed != 50 || g.Heading != 180 || g.Altitude != 100 || g.Sats != 12 {
+		t.Errorf("gps = %+v", *g)
+	}
+}
+
+func TestDecodeBattery(t *testing.T) {
+	p := []byte{0x00, 0xA8, 0x00, 0x7B, 0x00, 0x04, 0xD2, 64}
+	f, _ := Decode(RawFrame{Type: TypeBattery, Payload: p})
+	b := f.Battery
+	if b.Voltage != 16.8 || b.Current != 12.3 || b.Capacity != 1234 || b.Remaining != 64 {
+		t.Errorf("battery = %+v", *b)
+	}
+}
+
+func TestDecodeLinkStatistics(t *testing.T) {
+	p := []byte{72, 80, 98, 0xF6, 1, 4, 3, 65, 100, 9}
+	f, _ := Decode(RawFrame{Type: TypeLinkStatistics, Payload: p})
+	l := f.LinkStatistics
+	if l.UplinkRSSI1 != -72 || l.UplinkRSSI2 != -80 || l.UplinkLQ != 98 || l.UplinkSNR != -10 {
+		t.Errorf("uplink = %+v", *l)
+	}
+	if l.UplinkTxPower != 100 || l.RFMode != 4 || l.ActiveAntenna != 1 {
+		t.Errorf("tx = %+v", *l)
+	}
+	if l.DownlinkRSSI != -65 || l.DownlinkLQ != 100 || l.DownlinkSNR != 9 {
+		t.Errorf("downlink = %+v", *l)
+	}
+}
+
+func TestDecodeAttitude(t *testing.T) {
+	// pitch 0.1 rad, roll -0.2 rad, yaw 1.5708 rad
+	p := []byte{0x03, 0xE8, 0xF8, 0x30, 0x3D, 0x5C}
+	f, _ := Decode(RawFrame{Type: TypeAttitude, Payload: p})
+	a := f.Attitude
+	if math.Abs(a.Pitch-5.7296) > 0.001 || math.Abs(a.Roll+11.4592) > 0.001 || math.Abs(a.Yaw-90) > 0.01 {
+		t.Errorf("attitude = %+v", *a)
+	}
+}
+
+func TestDecodeFlightMode(t *testing.T) {
+	f, _ := Decode(RawFrame{Type: TypeFlightMode, Payload: []byte("ANGL\x00")})
+	if f.FlightMode != "ANGL" {
+		t.Errorf("flight mode = %q, want ANGL", f.FlightMode)
+	}
+}
+
+func TestDecode_ShortPayload(t *testing.T) {
+	if _, err := Decode(RawFrame{Type: TypeGPS, Payload: make([]byte, 10)}); err == nil {
+		t.Error("expected error for short payload")
+	}
+}
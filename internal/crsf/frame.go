@@ -0,0 +1,102 @@
+package crsf
+
+import "time"
+
+// Device addresses that may start a frame. Telemetry from the flight
+// controller is addressed to the handset or TX module, so all of them are
+// accepted as sync bytes.
+const (
+	AddrFlightController = 0xC8
+	AddrRadioTransmitter = 0xEA
+	AddrReceiver         = 0xEC
+	AddrTransmitter      = 0xEE
+)
+
+// Frame type bytes decoded by this package.
+const (
+	TypeGPS            byte = 0x02
+	TypeBattery        byte = 0x08
+	TypeLinkStatistics byte = 0x14
+	TypeAttitude       byte = 0x1E
+	TypeFlightMode     byte = 0x21
+)
+
+// PayloadSize maps each fixed-size frame type to its payload length.
+// Flight mode frames carry a variable-length string.
+var PayloadSize = map[byte]int{
+	TypeGPS:            15,
+	TypeBattery:        8,
+	TypeLinkStatistics: 10,
+	TypeAttitude:       6,
+}
+
+// FrameName maps each frame type to a human-readable name.
+var FrameName = map[byte]string{
+	TypeGPS:            "GPS",
+	TypeBattery:        "Battery",
+	TypeLinkStatistics: "Link Statistics",
+	TypeAttitude:       "Attitude",
+	TypeFlightMode:     "Flight Mode",
+}
+
+// TxPowerMW maps the link statistics TX power index to milliwatts.
+var TxPowerMW = []uint16{0, 10, 25, 100, 500, 1000, 2000, 250, 50}
+
+// RawFrame is a CRC-validated CRSF frame before payload decoding.
+type RawFrame struct {
+	Addr    byte
+	Type    byte
+	Payload []byte
+}
+
+// GPSData is decoded from a GPS frame (0x02).
+type GPSData struct {
+	Lat         float64 `json:"lat"`          // degrees
+	Lon         float64 `json:"lon"`          // degrees
+	GroundSpeed float64 `json:"ground_speed"` // km/h
+	Heading     float64 `json:"heading"`      // degrees
+	Altitude    int     `json:"altitude"`     // m
+	Sats        uint8   `json:"sats"`
+}
+
+// BatteryData is decoded from a battery sensor frame (0x08).
+type BatteryData struct {
+	Voltage   float64 `json:"voltage"`   // V
+	Current   float64 `json:"current"`   // A
+	Capacity  uint32  `json:"capacity"`  // mAh drawn
+	Remaining uint8   `json:"remaining"` // %
+}
+
+// LinkStatistics is decoded from a link statistics frame (0x14).
+type LinkStatistics struct {
+	UplinkRSSI1   int    `json:"uplink_rssi_1"` // dBm
+	UplinkRSSI2   int    `json:"uplink_rssi_2"` // dBm
+	UplinkLQ      uint8  `json:"uplink_lq"`     // %
+	UplinkSNR     int8   `json:"uplink_snr"`    // dB
+	ActiveAntenna uint8  `json:"active_antenna"`
+	RFMode        uint8  `json:"rf_mode"`
+	UplinkTxPower uint16 `json:"uplink_tx_power"` // mW
+	DownlinkRSSI  int    `json:"downlink_rssi"`   // dBm
+	DownlinkLQ    uint8  `json:"downlink_lq"`     // %
+	DownlinkSNR   int8   `json:"downlink_snr"`    // dB
+}
+
+// AttitudeData is decoded from an attitude frame (0x1E).
+type AttitudeData struct {
+	Pitch float64 `json:"pitch"` // degrees
+	Roll  float64 `json:"roll"`  // degrees
+	Yaw   float64 `json:"yaw"`   // degrees
+}
+
+// Frame is a decoded CRSF frame. Exactly one data pointer is non-nil.
+type Frame struct {
+	Type byte      `json:"type"`
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+
+	GPS            *GPSData        `json:"gps,omitempty"`
+	Battery        *BatteryData    `json:"battery,omitempty"`
+	LinkStatistics *LinkStatistics `json:"link_statistics,omitempty"`
+	Attitude       *AttitudeData   `json:"attitude,omitempty"`
+	FlightMode     string          `json:"flight_mode,omitempty"`
+}
@@ -0,0 +1,91 @@
+package crsf
+
+import "errors"
+
+var ErrChecksum = errors.New("crsf: checksum mismatch")
+
+// Frame length limits. The length byte counts the type, payload and CRC.
+const (
+	maxFrameSize = 64
+	minLen       = 2
+	maxLen       = maxFrameSize - 2
+)
+
+// Parser is a push-model CRSF frame parser implementing io.Writer.
+//
+// Frames of types this package does not decode are CRC-checked and then
+// skipped silently. After a checksum failure the parser resyncs on the
+// byte following the bad sync byte.
+type Parser struct {
+	buf     []byte
+	Handler func(RawFrame)
+	OnError func(error)
+}
+
+// NewParser creates a Parser that calls handler for each valid frame.
+func NewParser(handler func(RawFrame), onError func(error)) *Parser {
+	return &Parser{
+		buf:     make([]byte, 0, 2*maxFrameSize),
+		Handler: handler,
+		OnError: onError,
+	}
+}
+
+// Write implements io.Writer.
+func (p *Parser) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	off := p.scan()
+	p.buf = p.buf[:copy(p.buf, p.buf[off:])]
+	return len(data), nil
+}
+
+// scan consumes complete frames from p.buf and returns the offset of the
+// first unconsumed byte.
+func (p *Parser) scan() int {
+	off := 0
+	for {
+		for off < len(p.buf) && !isSync(p.buf[off]) {
+			off++
+		}
+		b := p.buf[off:]
+		if len(b) < 2 {
+			return off
+		}
+
+		n := int(b[1])
+		if n < minLen || n > maxLen {
+			off++ // not a frame start
+			continue
+		}
+		if len(b) < n+2 {
+			return off // need more bytes
+		}
+
+		frame := b[:n+2]
+		if crc8(frame[2:n+1]) != frame[n+1] {
+			if p.OnError != nil {
+				p.OnError(ErrChecksum)
+			}
+			off++
+			continue
+		}
+		off += n + 2
+
+		if _, ok := FrameName[frame[2]]; !ok {
+			continue
+		}
+		if p.Handler != nil {
+			payload := make([]byte, n-2)
+			copy(payload, frame[3:n+1])
+			p.Handler(RawFrame{Addr: frame[0], Type: frame[2], Payload: payload})
+		}
+	}
+}
+
+func isSync(b byte) bool {
+	switch b {
+	case AddrFlightController, AddrRadioTransmitter, AddrReceiver, AddrTransmitter:
+		return true
+	}
+	return false
+}
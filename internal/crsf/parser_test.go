@@ -0,0 +1,101 @@
+package crsf
+
+import (
+	"bytes"
+	"testing"
+)
+
+// build constructs a valid CRSF frame.
+func build(addr, typ byte, payload []byte) []byte {
+	f := []byte{addr, byte(len(payload) + 2), typ}
+	f = append(f, payload...)
+	return append(f, crc8(f[2:]))
+}
+
+func TestCRC8_KnownVector(t *testing.T) {
+	// CRC-8/DVB-S2 check value for "123456789".
+	if got := crc8([]byte("123456789")); got != 0xBC {
+		t.Errorf("crc = 0x%02X, want 0xBC", got)
+	}
+}
+
+func TestParser_ValidFrame(t *testing.T) {
+	payload := []byte{0, 100, 1, 2, 3, 4}
+	var got []RawFrame
+	p := NewParser(func(f RawFrame) { got = append(got, f) }, nil)
+	p.Write(build(AddrFlightController, TypeAttitude, payload))
+
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+	if got[0].Type != TypeAttitude || got[0].Addr != AddrFlightController {
+		t.Errorf("frame = %+v", got[0])
+	}
+	if !bytes.Equal(got[0].Payload, payload) {
+		t.Errorf("payload = %x, want %x", got[0].Payload, payload)
+	}
+}
+
+func TestParser_ByteAtATime(t *testing.T) {
+	data := build(AddrRadioTransmitter, TypeBattery, make([]byte, 8))
+	var got []RawFrame
+	p := NewParser(func(f RawFrame) { got = append(got, f) }, nil)
+	for _, b := range data {
+		p.Write([]byte{b})
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+}
+
+func TestParser_ChecksumFailureAndResync(t *testing.T) {
+	bad := build(AddrFlightController, TypeBattery, make([]byte, 8))
+	bad[len(bad)-1] ^= 0xFF
+	good := build(AddrFlightController, TypeAttitude, make([]byte, 6))
+
+	var frames []RawFrame
+	var errs []error
+	p := NewParser(func(f RawFrame) { frames = append(frames, f) }, func(e error) { errs = append(errs, e) })
+	p.Write(append(bad, good...))
+
+	if len(errs) != 1 || errs[0] != ErrChecksum {
+		t.Errorf("errors = %v, want [ErrChecksum]", errs)
+	}
+	if len(frames) != 1 || frames[0].Type != TypeAttitude {
+		t.Fatalf("frames = %+v, want one attitude frame", frames)
+	}
+}
+
+func TestParser_SkipsUnknownTypes(t *testing.T) {
+	data := build(AddrFlightController, 0x16, make([]byte, 22)) // RC channels
+	data = append(data, build(AddrFlightController, TypeFlightMode, []byte("ACRO\x00"))...)
+
+	var got []RawFrame
+	var errs []error
+	p := NewParser(func(f RawFrame) { got = append(got, f) }, func(e error) { errs = append(errs, e) })
+	p.Write(data)
+
+	if len(errs) != 0 {
+		t.Errorf("errors = %v, want none", errs)
+	}
+	if len(got) != 1 || got[0].Type != TypeFlightMode {
+		t.Fatalf("got %+v, want one flight mode frame", got)
+	}
+}
+
+func TestParser_Garbage(t *testing.T) {
+	// 0xC8 followed by an impossible length must not stall the parser.
+	garbage := []byte{0x11, AddrFlightController, 0xFF, 0x22, AddrTransmitter, 0x00}
+	good := build(AddrFlightController, TypeAttitude, make([]byte, 6))
+
+	var got []RawFrame
+	p := NewParser(func(f RawFrame) { got = append(got, f) }, nil)
+	p.Write(append(garbage, good...))
+
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+	if len(p.buf) != 0 {
+		t.Errorf("parser retained %d bytes after a complete frame", len(p.buf))
+	}
+}
@@ -0,0 +1,136 @@
+// Package detect identifies the telemetry protocol on an unknown byte stream.
+package detect
+
+import (
+	"io"
+	"time"
+
+	"fpv-ground-station/internal/crsf"
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/mavlink"
+)
+
+// Protocol names reported by the Detector.
+const (
+	LTM     = "ltm"
+	MAVLink = "mavlink"
+	CRSF    = "crsf"
+	MSP     = "msp"
+)
+
+// Defaults for Detector.
+const (
+	DefaultMinFrames = 5
+	DefaultWindow    = 3 * time.Second
+
+	// maxBuffer bounds the bytes held for replay while sniffing.
+	maxBuffer = 64 << 10
+)
+
+// candidate scores one protocol by counting checksum-valid frames.
+type candidate struct {
+	name   string
+	w      io.Writer
+	frames int
+}
+
+// Detector is an io.Writer that sniffs the start of a stream, scores each
+// candidate protocol by the number of checksum-valid frames it parses, and
+// locks onto the winner. Bytes seen while sniffing are replayed into the
+// winner's parser, so no frames are lost to detection.
+//
+// A candidate wins as soon as it reaches MinFrames, or once Window has
+// passed since the first byte if it has the most frames at that point.
+type Detector struct {
+	MinFrames int
+	Window    time.Duration
+
+	// OnDetect is called once with the winning protocol.
+	OnDetect func(protocol string)
+	// OnError is called if open fails for the winning protocol; the rest
+	// of the stream is then discarded.
+	OnError func(error)
+
+	open       func(protocol string) (io.Writer, error)
+	candidates []*candidate
+	buf        []byte
+	start      time.Time
+	locked     io.Writer
+	protocol   string
+}
+
+// New creates a Detector that calls open to build the parser for the
+// detected protocol.
+func New(open func(protocol string) (io.Writer, error)) *Detector {
+	d := &Detector{
+		MinFrames: DefaultMinFrames,
+		Window:    DefaultWindow,
+		open:      open,
+	}
+
+	ltmC := &candidate{name: LTM}
+	ltmC.w = ltm.NewParser(func(ltm.RawFrame) { ltmC.frames++ }, nil)
+	mavC := &candidate{name: MAVLink}
+	mavC.w = mavlink.NewParser(func(mavlink.RawMessage) { mavC.frames++ }, nil)
+	crsfC := &candidate{name: CRSF}
+	crsfC.w = crsf.NewParser(func(crsf.RawFrame) { crsfC.frames++ }, nil)
+	mspC := &candidate{name: MSP}
+	mspC.w = &mspCounter{frames: &mspC.frames}
+
+	d.candidates = []*candidate{ltmC, mavC, crsfC, mspC}
+	return d
+}
+
+// Protocol returns the detected protocol, or "" while still sniffing.
+func (d *Detector) Protocol() string {
+	return d.protocol
+}
+
+// Write implements io.Writer.
+func (d *Detector) Write(data []byte) (int, error) {
+	if d.locked != nil {
+		return d.locked.Write(data)
+	}
+	if d.start.IsZero() {
+		d.start = time.Now()
+	}
+
+	d.buf = append(d.buf, data...)
+	if over := len(d.buf) - maxBuffer; over > 0 {
+		d.buf = d.buf[:copy(d.buf, d.buf[over:])]
+	}
+
+	var best *candidate
+	for _, c := range d.candidates {
+		c.w.Write(data)
+		if best == nil || c.frames > best.frames {
+			best = c
+		}
+	}
+
+	if best.frames >= d.MinFrames || (best.frames > 0 && time.Since(d.start) >= d.Window) {
+		d.lock(best.name)
+	}
+	return len(data), nil
+}
+
+func (d *Detector) lock(protocol string) {
+	d.protocol = protocol
+	d.candidates = nil
+	if d.OnDetect != nil {
+		d.OnDetect(protocol)
+	}
+
+	w, err := d.open(protocol)
+	if err != nil {
+		if d.OnError != nil {
+			d.OnError(err)
+		}
+		w = io.Discard
+	}
+	d.locked = w
+
+	buf := d.buf
+	d.buf = nil
+	w.Write(buf)
+}
@@ -0,0 +1,158 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func ltmStream(n int) []byte {
+	var b []byte
+	for i := range n {
+		f, _ := ltm.Encode(ltm.Frame{Attitude: &ltm.AttitudeData{Roll: int16(i)}})
+		b = append(b, f...)
+	}
+	return b
+}
+
+func x25(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		tmp := b ^ byte(crc)
+		tmp ^= tmp << 4
+		crc = crc>>8 ^ uint16(tmp)<<8 ^ uint16(tmp)<<3 ^ uint16(tmp)>>4
+	}
+	return crc
+}
+
+func mavlinkStream(n int) []byte {
+	var b []byte
+	for i := range n {
+		pkt := []byte{0xFE, 9, byte(i), 1, 1, 0}
+		pkt = append(pkt, 0, 0, 0, 0, 2, 3, 0x81, 4, 3)
+		crc := x25(append(pkt[1:], 50)) // HEARTBEAT crc_extra
+		b = append(b, binary.LittleEndian.AppendUint16(pkt, crc)...)
+	}
+	return b
+}
+
+func dvbS2(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0xD5
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func crsfStream(n int) []byte {
+	var b []byte
+	for range n {
+		f := []byte{0xC8, 8, 0x1E, 0, 1, 0, 2, 0, 3}
+		b = append(append(b, f...), dvbS2(f[2:]))
+	}
+	return b
+}
+
+func mspStream(n int) []byte {
+	var b []byte
+	for range n {
+		f := []byte{'$', 'M', '>', 2, 101, 0xAA, 0xBB}
+		chk := byte(0)
+		for _, c := range f[3:] {
+			chk ^= c
+		}
+		b = append(append(b, f...), chk)
+	}
+	return b
+}
+
+func TestDetector_Protocols(t *testing.T) {
+	tests := []struct {
+		want string
+		data []byte
+	}{
+		{LTM, ltmStream(10)},
+		{MAVLink, mavlinkStream(10)},
+		{CRSF, crsfStream(10)},
+		{MSP, mspStream(10)},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		var detected []string
+		d := New(func(string) (io.Writer, error) { return &out, nil })
+		d.OnDetect = func(p string) { detected = append(detected, p) }
+
+		// Leading noise and byte-sized writes must not matter.
+		d.Write([]byte{0x00, 0x55, 0xFF})
+		for _, b := range tt.data {
+			d.Write([]byte{b})
+		}
+
+		if len(detected) != 1 || detected[0] != tt.want {
+			t.Errorf("%s: detected %v", tt.want, detected)
+			continue
+		}
+		if d.Protocol() != tt.want {
+			t.Errorf("Protocol() = %q, want %q", d.Protocol(), tt.want)
+		}
+		if !bytes.HasSuffix(out.Bytes(), tt.data) {
+			t.Errorf("%s: downstream parser did not receive the full stream", tt.want)
+		}
+	}
+}
+
+func TestDetector_WaitsForEnoughFrames(t *testing.T) {
+	d := New(func(string) (io.Writer, error) { return io.Discard, nil })
+	d.Write(ltmStream(DefaultMinFrames - 1))
+	if d.Protocol() != "" {
+		t.Errorf("locked on %q before MinFrames", d.Protocol())
+	}
+	d.Write(ltmStream(1))
+	if d.Protocol() != LTM {
+		t.Errorf("Protocol() = %q, want ltm", d.Protocol())
+	}
+}
+
+func TestDetector_WindowFallback(t *testing.T) {
+	d := New(func(string) (io.Writer, error) { return io.Discard, nil })
+	d.MinFrames = 100
+	d.Window = 0
+	d.Write(crsfStream(2))
+	if d.Protocol() != CRSF {
+		t.Errorf("Protocol() = %q, want crsf after window", d.Protocol())
+	}
+}
+
+func TestDetector_NoiseNeverLocks(t *testing.T) {
+	d := New(func(string) (io.Writer, error) { return io.Discard, nil })
+	d.Window = 0
+	d.Write(bytes.Repeat([]byte{0x00, 0x11, 0x22}, 1000))
+	if d.Protocol() != "" {
+		t.Errorf("locked on %q from noise", d.Protocol())
+	}
+}
+
+func TestDetector_OpenError(t *testing.T) {
+	var gotErr error
+	d := New(func(string) (io.Writer, error) { return nil, errors.New("unsupported") })
+	d.OnError = func(err error) { gotErr = err }
+
+	d.Write(mspStream(10))
+	if gotErr == nil {
+		t.Fatal("OnError not called")
+	}
+	if _, err := d.Write(mspStream(1)); err != nil {
+		t.Errorf("write after failed open: %v", err)
+	}
+}
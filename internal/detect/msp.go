@@ -0,0 +1,74 @@
+package detect
+
+// MSP v1 parser states.
+const (
+	mspIdle = iota
+	mspHeaderM
+	mspDirection
+	mspSize
+	mspCmd
+	mspPayload
+	mspChecksum
+)
+
+// mspCounter counts checksum-valid MSP v1 frames ("$M<", "$M>" or "$M!").
+// MSP is only scored for detection; there is no MSP telemetry decoder.
+type mspCounter struct {
+	state  int
+	remain int
+	chk    byte
+	frames *int
+}
+
+// Write implements io.Writer.
+func (m *mspCounter) Write(data []byte) (int, error) {
+	for _, b := range data {
+		m.feed(b)
+	}
+	return len(data), nil
+}
+
+func (m *mspCounter) feed(b byte) {
+	switch m.state {
+	case mspIdle:
+		if b == '$' {
+			m.state = mspHeaderM
+		}
+	case mspHeaderM:
+		switch b {
+		case 'M':
+			m.state = mspDirection
+		case '$':
+		default:
+			m.state = mspIdle
+		}
+	case mspDirection:
+		if b == '<' || b == '>' || b == '!' {
+			m.state = mspSize
+		} else {
+			m.state = mspIdle
+		}
+	case mspSize:
+		m.remain = int(b)
+		m.chk = b
+		m.state = mspCmd
+	case mspCmd:
+		m.chk ^= b
+		if m.remain == 0 {
+			m.state = mspChecksum
+		} else {
+			m.state = mspPayload
+		}
+	case mspPayload:
+		m.chk ^= b
+		m.remain--
+		if m.remain == 0 {
+			m.state = mspChecksum
+		}
+	case mspChecksum:
+		if b == m.chk {
+			*m.frames++
+		}
+		m.state = mspIdle
+	}
+}
@@ -0,0 +1,95 @@
+// Package export converts recorded tracks to GPX 1.1 and KML 2.2 for use
+// in mapping tools such as Google Earth.
+package export
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fpv-ground-station/internal/geo"
+	"fpv-ground-station/internal/telemetry"
+)
+
+// Creator is written as the GPX creator attribute.
+const Creator = "fpv-ground-station"
+
+// Home is the takeoff position. Alt is MSL altitude in metres, or 0 when
+// unknown.
+type Home struct {
+	Lat float64
+	Lon float64
+	Alt float64
+}
+
+// Track is a recorded track ready for export.
+type Track struct {
+	Name   string
+	Home   *Home // optional; defaults to the first point with a fix
+	Points []telemetry.TrackPoint
+}
+
+// home returns the track's home, falling back to the first point with a
+// 2D fix or better, then to the first point.
+func (t Track) home() *Home {
+	if t.Home != nil {
+		return t.Home
+	}
+	for _, p := range t.Points {
+		if p.Fix >= 2 {
+			return &Home{Lat: p.Lat, Lon: p.Lon}
+		}
+	}
+	if len(t.Points) > 0 {
+		return &Home{Lat: t.Points[0].Lat, Lon: t.Points[0].Lon}
+	}
+	return nil
+}
+
+// stats are the flight metadata written alongside the track.
+type stats struct {
+	Start, End  time.Time // zero for tracks without timestamps
+	Distance    float64   // m along the track
+	MaxDistance float64   // m from home
+	MaxAlt      float64   // m above home
+	MaxSpeed    float64   // m/s
+}
+
+func (t Track) stats() stats {
+	var s stats
+	home := t.home()
+	for i, p := range t.Points {
+		if !p.Time.IsZero() {
+			if s.Start.IsZero() {
+				s.Start = p.Time
+			}
+			s.End = p.Time
+		}
+		if i > 0 {
+			prev := t.Points[i-1]
+			s.Distance += geo.Distance(prev.Lat, prev.Lon, p.Lat, p.Lon)
+		}
+		if home != nil {
+			s.MaxDistance = max(s.MaxDistance, geo.Distance(home.Lat, home.Lon, p.Lat, p.Lon))
+		}
+		s.MaxAlt = max(s.MaxAlt, p.Alt)
+		s.MaxSpeed = max(s.MaxSpeed, p.Speed)
+	}
+	return s
+}
+
+// description summarizes the flight in one line.
+func (s stats) description(points int) string {
+	d := fmt.Sprintf("%d points, %.2f km flown, max %.0f m from home, max altitude %.0f m, max speed %.0f m/s",
+		points, s.Distance/1000, s.MaxDistance, s.MaxAlt, s.MaxSpeed)
+	if !s.Start.IsZero() {
+		d = fmt.Sprintf("%s, %s", s.End.Sub(s.Start).Round(time.Second), d)
+	}
+	return d
+}
+
+// round7 rounds a coordinate to the 1e-7 degree resolution of the source
+// telemetry.
+func round7(v float64) float64 {
+	return math.Round(v*1e7) / 1e7
+}
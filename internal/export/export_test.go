@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/telemetry"
+)
+
+func testTrack() Track {
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return Track{
+		Name: "Flight 3",
+		Home: &Home{Lat: 51.5, Lon: -0.1, Alt: 35},
+		Points: []telemetry.TrackPoint{
+			{Time: t0, Lat: 51.5, Lon: -0.1, Alt: 0, Fix: 3, Sats: 12},
+			{Time: t0.Add(10 * time.Second), Lat: 51.5009, Lon: -0.1, Alt: 40, Speed: 10, Fix: 3, Sats: 13},
+			{Time: t0.Add(20 * time.Second), Lat: 51.5018, Lon: -0.1, Alt: 60, Speed: 12, Fix: 2, Sats: 6},
+		},
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGPX(&buf, testTrack()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Error("missing XML declaration")
+	}
+
+	var doc gpxDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "1.1" || doc.XMLNS != "http://www.topografix.com/GPX/1/1" {
+		t.Errorf("gpx version/namespace = %q / %q", doc.Version, doc.XMLNS)
+	}
+	if len(doc.Waypoint) != 1 || doc.Waypoint[0].Name != "Home" {
+		t.Errorf("waypoints = %+v, want home", doc.Waypoint)
+	}
+
+	pts := doc.Track.Segment.Points
+	if len(pts) != 3 {
+		t.Fatalf("track points = %d, want 3", len(pts))
+	}
+	p := pts[1]
+	if *p.Ele != 75 {
+		t.Errorf("ele = %v, want 75 (home 35 + 40)", *p.Ele)
+	}
+	if p.Time != "2026-05-01T12:00:10Z" || p.Fix != "3d" || *p.Sat != 13 {
+		t.Errorf("point = %+v", p)
+	}
+	if pts[2].Fix != "2d" {
+		t.Errorf("fix = %q, want 2d", pts[2].Fix)
+	}
+
+	if doc.Metadata.Time != "2026-05-01T12:00:00Z" || !strings.Contains(doc.Metadata.Desc, "20s") {
+		t.Errorf("metadata = %+v", doc.Metadata)
+	}
+}
+
+func TestWriteGPX_Version1Points(t *testing.T) {
+	tr := Track{Points: []telemetry.TrackPoint{{Lat: 51.5, Lon: -0.1}, {Lat: 51.501, Lon: -0.1}}}
+
+	var buf bytes.Buffer
+	if err := WriteGPX(&buf, tr); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<time>") || strings.Contains(out, "<fix>") || strings.Contains(out, "<sat>") {
+		t.Errorf("points without time or fix should omit them:\n%s", out)
+	}
+	if !strings.Contains(out, `<wpt lat="51.5" lon="-0.1">`) {
+		t.Errorf("home should fall back to the first point:\n%s", out)
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteKML(&buf, testTrack()); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc kmlDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	d := doc.Document
+	if d.Name != "Flight 3" || len(d.Placemarks) != 2 {
+		t.Fatalf("document = %+v", d)
+	}
+	if home := d.Placemarks[0]; home.Name != "Home" || home.Point == nil || home.Point.Coordinates != "-0.1000000,51.5000000,0" {
+		t.Errorf("home placemark = %+v", home)
+	}
+
+	line := d.Placemarks[1].LineString
+	if line == nil || line.Extrude != 1 || line.AltitudeMode != "relativeToGround" {
+		t.Fatalf("line = %+v, want extruded relativeToGround", line)
+	}
+	coords := strings.Fields(line.Coordinates)
+	if len(coords) != 3 || coords[1] != "-0.1000000,51.5009000,40.0" {
+		t.Errorf("coordinates = %v", coords)
+	}
+	if ts := d.Placemarks[1].TimeSpan; ts == nil || ts.Begin != "2026-05-01T12:00:00Z" || ts.End != "2026-05-01T12:00:20Z" {
+		t.Errorf("time span = %+v", ts)
+	}
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type gpxDoc struct {
+	XMLName  xml.Name    `xml:"gpx"`
+	Version  string      `xml:"version,attr"`
+	Creator  string      `xml:"creator,attr"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	Metadata gpxMetadata `xml:"metadata"`
+	Waypoint []gpxPoint  `xml:"wpt"`
+	Track    gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name,omitempty"`
+	Desc    string     `xml:"desc,omitempty"`
+	Segment gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// gpxPoint is a wptType; GPX 1.1 fixes the child element order.
+type gpxPoint struct {
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele,omitempty"`
+	Time string   `xml:"time,omitempty"`
+	Name string   `xml:"name,omitempty"`
+	Fix  string   `xml:"fix,omitempty"`
+	Sat  *uint8   `xml:"sat,omitempty"`
+}
+
+// gpxFix maps an LTM fix type to the GPX fix element. Points without a fix
+// value (version 1 track records) leave it out.
+var gpxFix = map[uint8]string{1: "none", 2: "2d", 3: "3d"}
+
+func gpxTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// WriteGPX writes t as a GPX 1.1 document with one track segment and a
+// home waypoint. Elevations are MSL when the home altitude is known and
+// relative to home otherwise.
+func WriteGPX(w io.Writer, t Track) error {
+	st := t.stats()
+	home := t.home()
+	homeAlt := 0.0
+	if home != nil {
+		homeAlt = home.Alt
+	}
+
+	doc := gpxDoc{
+		Version: "1.1",
+		Creator: Creator,
+		XMLNS:   "http://www.topografix.com/GPX/1/1",
+		Metadata: gpxMetadata{
+			Name: t.Name,
+			Desc: st.description(len(t.Points)),
+			Time: gpxTime(st.Start),
+		},
+		Track: gpxTrack{Name: t.Name, Desc: st.description(len(t.Points))},
+	}
+	if home != nil {
+		ele := home.Alt
+		doc.Waypoint = []gpxPoint{{Lat: home.Lat, Lon: home.Lon, Ele: &ele, Name: "Home"}}
+	}
+
+	pts := make([]gpxPoint, len(t.Points))
+	for i, p := range t.Points {
+		ele := homeAlt + p.Alt
+		pts[i] = gpxPoint{
+			Lat:  round7(p.Lat),
+			Lon:  round7(p.Lon),
+			Ele:  &ele,
+			Time: gpxTime(p.Time),
+			Fix:  gpxFix[p.Fix],
+		}
+		if p.Fix != 0 {
+			sats := p.Sats
+			pts[i].Sat = &sats
+		}
+	}
+	doc.Track.Segment.Points = pts
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type kmlDoc struct {
+	XMLName  xml.Name    `xml:"kml"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name        string         `xml:"name,omitempty"`
+	Description string         `xml:"description,omitempty"`
+	Style       kmlStyle       `xml:"Style"`
+	Placemarks  []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlStyle struct {
+	ID        string       `xml:"id,attr"`
+	LineStyle kmlLineStyle `xml:"LineStyle"`
+	PolyStyle kmlPolyStyle `xml:"PolyStyle"`
+}
+
+type kmlLineStyle struct {
+	Color string  `xml:"color"` // aabbggrr
+	Width float64 `xml:"width"`
+}
+
+type kmlPolyStyle struct {
+	Color string `xml:"color"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	TimeSpan    *kmlTimeSpan   `xml:"TimeSpan,omitempty"`
+	StyleURL    string         `xml:"styleUrl,omitempty"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlTimeSpan struct {
+	Begin string `xml:"begin,omitempty"`
+	End   string `xml:"end,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Extrude      int    `xml:"extrude"`
+	Tessellate   int    `xml:"tessellate"`
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+// WriteKML writes t as a KML 2.2 document with a home placemark and the
+// track as an extruded 3D line. Track altitudes are relative to the
+// ground, which at the takeoff point is what LTM's home-relative GPS
+// altitude measures.
+func WriteKML(w io.Writer, t Track) error {
+	st := t.stats()
+	desc := st.description(len(t.Points))
+
+	var coords strings.Builder
+	for i, p := range t.Points {
+		if i > 0 {
+			coords.WriteByte(' ')
+		}
+		fmt.Fprintf(&coords, "%.7f,%.7f,%.1f", p.Lon, p.Lat, p.Alt)
+	}
+
+	track := kmlPlacemark{
+		Name:        "Flight path",
+		Description: desc,
+		StyleURL:    "#track",
+		LineString: &kmlLineString{
+			Extrude:      1,
+			Tessellate:   0,
+			AltitudeMode: "relativeToGround",
+			Coordinates:  coords.String(),
+		},
+	}
+	if !st.Start.IsZero() {
+		track.TimeSpan = &kmlTimeSpan{Begin: gpxTime(st.Start), End: gpxTime(st.End)}
+	}
+
+	doc := kmlDoc{
+		XMLNS: "http://www.opengis.net/kml/2.2",
+		Document: kmlDocument{
+			Name:        t.Name,
+			Description: desc,
+			Style: kmlStyle{
+				ID:        "track",
+				LineStyle: kmlLineStyle{Color: "ff00aaff", Width: 3},
+				PolyStyle: kmlPolyStyle{Color: "4000aaff"},
+			},
+		},
+	}
+	if home := t.home(); home != nil {
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlPlacemark{
+			Name:  "Home",
+			Point: &kmlPoint{Coordinates: fmt.Sprintf("%.7f,%.7f,0", home.Lon, home.Lat)},
+		})
+	}
+	doc.Document.Placemarks = append(doc.Document.Placemarks, track)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
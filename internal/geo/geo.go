@@ -0,0 +1,32 @@
+// Package geo provides great-circle helpers for WGS84 coordinates.
+package geo
+
+import "math"
+
+// EarthRadius is the mean Earth radius in meters.
+const EarthRadius = 6371008.8
+
+// Distance returns the great-circle distance in meters between two points
+// given in degrees, using the haversine formula.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	p1, p2 := radians(lat1), radians(lat2)
+	dLat := p2 - p1
+	dLon := radians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Bearing returns the initial bearing in degrees [0, 360) from the first
+// point to the second.
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	p1, p2 := radians(lat1), radians(lat2)
+	dLon := radians(lon2 - lon1)
+
+	y := math.Sin(dLon) * math.Cos(p2)
+	x := math.Cos(p1)*math.Sin(p2) - math.Sin(p1)*math.Cos(p2)*math.Cos(dLon)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
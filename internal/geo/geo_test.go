@@ -0,0 +1,44 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want, tol              float64
+	}{
+		{"same point", 51.5, -0.1278, 51.5, -0.1278, 0, 1e-9},
+		{"one degree latitude", 0, 0, 1, 0, 111195, 1},
+		{"london to paris", 51.5074, -0.1278, 48.8566, 2.3522, 343556, 500},
+		{"short hop", 51.5, -0.1278, 51.5009, -0.1278, 100.08, 0.1},
+	}
+	for _, tt := range tests {
+		got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > tt.tol {
+			t.Errorf("%s: distance = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"north", 0, 0, 1, 0, 0},
+		{"east", 0, 0, 0, 1, 90},
+		{"south", 1, 0, 0, 0, 180},
+		{"west", 0, 1, 0, 0, 270},
+	}
+	for _, tt := range tests {
+		got := Bearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: bearing = %.4f, want %.0f", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package logbook stores flights, decoded frames and alarms on disk so
+// they outlive the process. Data lives in two append-only segment logs:
+// frames, which are bulky and subject to a size limit, and events (flights
+// and alarms), which are small and kept forever.
+package logbook
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/telemetry"
+)
+
+// Record kinds.
+const (
+	kindFlight byte = 1
+	kindAlarm  byte = 2
+	kindFrame  byte = 3
+)
+
+// DefaultSegmentSize is the frame segment size unless Options says
+// otherwise. Retention drops frame data a segment at a time.
+const DefaultSegmentSize = 8 << 20
+
+// ErrNotFound is returned for a query on an unknown flight ID.
+var ErrNotFound = errors.New("flight not found")
+
+// Options configure a logbook. Zero values pick defaults.
+type Options struct {
+	SegmentSize   int64 // frame segment size in bytes
+	MaxFrameBytes int64 // drop the oldest frame segments beyond this total; 0 keeps all
+}
+
+// DB is an open logbook, safe for concurrent use.
+type DB struct {
+	mu     sync.Mutex
+	events *segmentLog
+	frames *segmentLog
+
+	flights  []telemetry.Flight // by logbook ID - 1, latest record wins; no tracks
+	sessions map[int]int        // this run's session flight ID → logbook ID
+}
+
+// Open opens or creates the logbook in dir. Flights left active by a
+// process that did not shut down cleanly are closed at their last frame.
+func Open(dir string, opts Options) (*DB, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	events, err := openSegmentLog(filepath.Join(dir, "events"), opts.SegmentSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := openSegmentLog(filepath.Join(dir, "frames"), opts.SegmentSize, opts.MaxFrameBytes)
+	if err != nil {
+		events.close()
+		return nil, err
+	}
+	db := &DB{events: events, frames: frames, sessions: make(map[int]int)}
+
+	err = scanSegments(events.snapshot(), time.Time{}, time.Time{}, func(rec record) bool {
+		if rec.kind != kindFlight {
+			return true
+		}
+		var f telemetry.Flight
+		if json.Unmarshal(rec.body, &f) == nil && f.ID > 0 {
+			db.setFlight(f)
+		}
+		return true
+	})
+	if err == nil {
+		err = db.closeStale()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *DB) setFlight(f telemetry.Flight) {
+	for len(db.flights) < f.ID {
+		db.flights = append(db.flights, telemetry.Flight{})
+	}
+	db.flights[f.ID-1] = f
+}
+
+// closeStale ends flights still marked active from an earlier run at their
+// last frame before the next flight started.
+func (db *DB) closeStale() error {
+	for i, f := range db.flights {
+		if !f.Active {
+			continue
+		}
+		var until time.Time
+		for _, next := range db.flights[i+1:] {
+			if next.ID != 0 && next.Start.After(f.Start) {
+				until = next.Start
+				break
+			}
+		}
+		f.Active = false
+		f.End = f.Start
+		err := scanSegments(db.frames.snapshot(), f.Start, until, func(rec record) bool {
+			if !until.IsZero() && !rec.time.Before(until) {
+				return false
+			}
+			f.End = rec.time
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		f.Summary.DurationSec = f.End.Sub(f.Start).Seconds()
+		if err := db.putFlight(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putFlight stores f without its track, which Flight rebuilds from the
+// frames; a whole track would outgrow a record on a long flight.
+func (db *DB) putFlight(f telemetry.Flight) error {
+	f.Track = nil
+	body, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	t := f.End
+	if t.IsZero() {
+		t = f.Start
+	}
+	if err := db.events.append(record{kind: kindFlight, time: t, body: body}); err != nil {
+		return err
+	}
+	db.setFlight(f)
+	return nil
+}
+
+// RecordFlight stores the latest state of a flight from
+// telemetry.Sessions. The session's flight IDs restart with every run, so
+// each new one is given the next logbook ID; later updates to it, such as
+// the end of the flight, replace the stored record.
+func (db *DB) RecordFlight(f telemetry.Flight) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	id, ok := db.sessions[f.ID]
+	if !ok {
+		id = len(db.flights) + 1
+		db.sessions[f.ID] = id
+	}
+	f.ID = id
+	return db.putFlight(f)
+}
+
+// RecordFrame stores a decoded frame.
+func (db *DB) RecordFrame(f ltm.Frame) error {
+	body, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.frames.append(record{kind: kindFrame, time: f.Time, body: body})
+}
+
+// RecordAlarm stores an alarm event.
+func (db *DB) RecordAlarm(ev alarm.Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.events.append(record{kind: kindAlarm, time: ev.Time, body: body})
+}
+
+// Flights returns all stored flights, oldest first, without their tracks.
+func (db *DB) Flights() []telemetry.Flight {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	out := make([]telemetry.Flight, 0, len(db.flights))
+	for _, f := range db.flights {
+		if f.ID == 0 {
+			continue // lost to a torn write
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// Flight returns the stored flight with the given ID, or ErrNotFound. Its
+// track is rebuilt from the GPS frames recorded during the flight, so it
+// is cut short if frame retention has dropped the start.
+func (db *DB) Flight(id int) (telemetry.Flight, error) {
+	f, ok := db.flight(id)
+	if !ok {
+		return f, ErrNotFound
+	}
+	until := f.End
+	if f.Active {
+		until = time.Time{}
+	}
+	db.mu.Lock()
+	segs := db.frames.snapshot()
+	db.mu.Unlock()
+
+	err := scanSegments(segs, f.Start, until, func(rec record) bool {
+		var fr ltm.Frame
+		if json.Unmarshal(rec.body, &fr) != nil {
+			return true
+		}
+		// The same positions telemetry.Sessions adds to a live track.
+		if g := fr.GPS; g != nil && g.Fix >= 2 && (g.Lat != 0 || g.Lon != 0) {
+			f.Track = append(f.Track, [2]float64{g.Lat, g.Lon})
+		}
+		return true
+	})
+	return f, err
+}
+
+// flight returns the stored flight record with the given ID.
+func (db *DB) flight(id int) (telemetry.Flight, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if id < 1 || id > len(db.flights) || db.flights[id-1].ID == 0 {
+		return telemetry.Flight{}, false
+	}
+	return db.flights[id-1], true
+}
+
+// Query selects stored frames or alarms. A FlightID narrows the time range
+// to that flight's; zero times are open bounds.
+type Query struct {
+	Since    time.Time
+	Until    time.Time
+	FlightID int
+	Limit    int // 0 for no limit
+}
+
+// bounds resolves the query's time range.
+func (db *DB) bounds(q Query) (since, until time.Time, err error) {
+	since, until = q.Since, q.Until
+	if q.FlightID == 0 {
+		return since, until, nil
+	}
+	f, ok := db.flight(q.FlightID)
+	if !ok {
+		return since, until, ErrNotFound
+	}
+	if since.IsZero() || since.Before(f.Start) {
+		since = f.Start
+	}
+	if !f.Active && (until.IsZero() || until.After(f.End)) {
+		until = f.End
+	}
+	return since, until, nil
+}
+
+// Frames returns the stored frames selected by q, oldest first.
+func (db *DB) Frames(q Query) ([]ltm.Frame, error) {
+	since, until, err := db.bounds(q)
+	if err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	segs := db.frames.snapshot()
+	db.mu.Unlock()
+
+	out := []ltm.Frame{}
+	err = scanSegments(segs, since, until, func(rec record) bool {
+		var f ltm.Frame
+		if json.Unmarshal(rec.body, &f) == nil {
+			out = append(out, f)
+		}
+		return q.Limit <= 0 || len(out) < q.Limit
+	})
+	return out, err
+}
+
+// Alarms returns the stored alarm events selected by q, oldest first.
+func (db *DB) Alarms(q Query) ([]alarm.Event, error) {
+	since, until, err := db.bounds(q)
+	if err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	segs := db.events.snapshot()
+	db.mu.Unlock()
+
+	out := []alarm.Event{}
+	err = scanSegments(segs, since, until, func(rec record) bool {
+		if rec.kind != kindAlarm {
+			return true
+		}
+		var ev alarm.Event
+		if json.Unmarshal(rec.body, &ev) == nil {
+			out = append(out, ev)
+		}
+		return q.Limit <= 0 || len(out) < q.Limit
+	})
+	return out, err
+}
+
+// Close closes the logbook files.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return errors.Join(db.events.close(), db.frames.close())
+}
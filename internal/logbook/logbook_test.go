@@ -0,0 +1,184 @@
+package logbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/ltm"
+	"fpv-ground-station/internal/telemetry"
+)
+
+var t0 = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func at(sec int) time.Time { return t0.Add(time.Duration(sec) * time.Second) }
+
+func gpsFrame(sec int) ltm.Frame {
+	return ltm.Frame{Function: ltm.FuncGPS, Name: "GPS", Time: at(sec), GPS: &ltm.GPSData{Lat: 51.5, Lon: -0.1, Altitude: float64(sec), Fix: 3}}
+}
+
+func openDB(t *testing.T, dir string, opts Options) *DB {
+	t.Helper()
+	db, err := Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLogbook_RecordAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, dir, Options{})
+
+	// Session 1 of the first run: armed from 10 s to 20 s.
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: at(10), Active: true})
+	for sec := range 30 {
+		db.RecordFrame(gpsFrame(sec))
+	}
+	db.RecordAlarm(alarm.Event{Rule: "battery_low", State: alarm.Raised, Severity: alarm.Warning, Time: at(15)})
+	db.RecordAlarm(alarm.Event{Rule: "battery_low", State: alarm.Cleared, Severity: alarm.Warning, Time: at(25)})
+	// The session's track is left to the frames, however long it grows.
+	if err := db.RecordFlight(telemetry.Flight{ID: 1, Start: at(10), End: at(20), Track: make([][2]float64, 100_000)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second run numbers its sessions from 1 again.
+	db = openDB(t, dir, Options{})
+	defer db.Close()
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: at(100), Active: true})
+
+	flights := db.Flights()
+	if len(flights) != 2 || flights[0].ID != 1 || flights[1].ID != 2 || flights[1].Start != at(100) {
+		t.Fatalf("flights = %+v", flights)
+	}
+	if flights[0].Active || flights[0].End != at(20) || flights[0].Track != nil {
+		t.Errorf("flight 1 = %+v, want ended without track in the list", flights[0])
+	}
+	if f, err := db.Flight(1); err != nil || len(f.Track) != 11 || f.Track[0] != [2]float64{51.5, -0.1} {
+		t.Errorf("Flight(1) = %+v, %v, want its track from the GPS frames", f, err)
+	}
+	if _, err := db.Flight(3); err != ErrNotFound {
+		t.Errorf("Flight(3) error = %v, want ErrNotFound", err)
+	}
+
+	frames, err := db.Frames(Query{FlightID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 11 || !frames[0].Time.Equal(at(10)) || frames[10].GPS.Altitude != 20 {
+		t.Errorf("flight 1 frames = %d, first at %v", len(frames), frames[0].Time)
+	}
+	frames, _ = db.Frames(Query{Since: at(25), Limit: 3})
+	if len(frames) != 3 || !frames[0].Time.Equal(at(25)) {
+		t.Errorf("frames since 25 s = %d", len(frames))
+	}
+	if _, err := db.Frames(Query{FlightID: 9}); err != ErrNotFound {
+		t.Errorf("unknown flight error = %v", err)
+	}
+
+	alarms, _ := db.Alarms(Query{FlightID: 1})
+	if len(alarms) != 1 || alarms[0].State != alarm.Raised || alarms[0].Severity != alarm.Warning {
+		t.Errorf("flight 1 alarms = %+v", alarms)
+	}
+	if alarms, _ := db.Alarms(Query{}); len(alarms) != 2 {
+		t.Errorf("all alarms = %d, want 2", len(alarms))
+	}
+}
+
+func TestLogbook_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, dir, Options{})
+	for sec := range 5 {
+		db.RecordFrame(gpsFrame(sec))
+	}
+	db.Close()
+
+	// Simulate a crash halfway through a write.
+	path := filepath.Join(dir, "frames", "00000001.seg")
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-5)
+
+	db = openDB(t, dir, Options{})
+	defer db.Close()
+	db.RecordFrame(gpsFrame(5))
+	frames, err := db.Frames(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 5 || !frames[4].Time.Equal(at(5)) {
+		t.Errorf("frames after repair = %d, want 4 intact + 1 new", len(frames))
+	}
+}
+
+func TestLogbook_ClosesStaleFlights(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, dir, Options{})
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: at(0), Active: true})
+	for sec := range 42 {
+		db.RecordFrame(gpsFrame(sec))
+	}
+	db.Close() // no disarm: the process died mid-flight
+
+	db = openDB(t, dir, Options{})
+	defer db.Close()
+	f, err := db.Flight(1)
+	if err != nil || f.Active || !f.End.Equal(at(41)) || f.Summary.DurationSec != 41 {
+		t.Errorf("stale flight = %+v", f)
+	}
+}
+
+func TestLogbook_StaleFlightEndsBeforeNext(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, dir, Options{})
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: at(0), Active: true})
+	for sec := range 20 {
+		db.RecordFrame(gpsFrame(sec))
+	}
+	db.RecordFlight(telemetry.Flight{ID: 2, Start: at(10), End: at(19)})
+	db.Close()
+
+	db = openDB(t, dir, Options{})
+	defer db.Close()
+	if f, _ := db.Flight(1); f.Active || !f.End.Equal(at(9)) || len(f.Track) != 10 {
+		t.Errorf("stale flight = %+v, want it ended at the last frame before flight 2", f)
+	}
+}
+
+func TestLogbook_FrameRetention(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, dir, Options{SegmentSize: 1000, MaxFrameBytes: 3000})
+	defer db.Close()
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: at(0), Active: true})
+	for sec := range 200 {
+		db.RecordFrame(gpsFrame(sec))
+	}
+
+	segs, _ := os.ReadDir(filepath.Join(dir, "frames"))
+	var total int64
+	for _, e := range segs {
+		info, _ := e.Info()
+		total += info.Size()
+	}
+	if total > 3000+1000 || len(segs) < 2 {
+		t.Errorf("%d frame segments, %d bytes, want within the limit", len(segs), total)
+	}
+
+	frames, _ := db.Frames(Query{})
+	if len(frames) == 0 || len(frames) >= 200 || !frames[len(frames)-1].Time.Equal(at(199)) {
+		t.Errorf("frames = %d, want the newest ones only", len(frames))
+	}
+	// Skipping segments by time still finds the right frames.
+	frames, _ = db.Frames(Query{Since: at(195)})
+	if len(frames) != 5 {
+		t.Errorf("frames since 195 s = %d, want 5", len(frames))
+	}
+	// Flights survive frame retention.
+	if len(db.Flights()) != 1 {
+		t.Error("flight lost to frame retention")
+	}
+}
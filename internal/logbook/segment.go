@@ -0,0 +1,309 @@
+package logbook
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record layout, little-endian:
+//
+//	u32 payload length
+//	u32 CRC-32 (IEEE) of the payload
+//	payload: u8 kind, i64 Unix nanoseconds, JSON body
+const (
+	recordHeader  = 8
+	payloadHeader = 9
+	maxRecord     = 1 << 20
+)
+
+const segmentExt = ".seg"
+
+// errCorrupt marks a record that failed its length or checksum test. Only
+// a torn write at the end of the newest segment is expected.
+var errCorrupt = errors.New("corrupt record")
+
+// record is one entry of a segment log.
+type record struct {
+	kind byte
+	time time.Time
+	body []byte
+}
+
+// segment is one file of a segment log. first is the time of its first
+// record, zero while it is empty.
+type segment struct {
+	seq   int
+	path  string
+	size  int64
+	first time.Time
+}
+
+// segmentLog is an append-only log of time-ordered records split across
+// numbered segment files, so old data can be dropped a file at a time.
+type segmentLog struct {
+	dir         string
+	segmentSize int64 // rotate once the active segment reaches this size
+	maxBytes    int64 // drop the oldest segments beyond this total; 0 keeps all
+
+	segs []segment // oldest first; the last one is active
+	file *os.File
+}
+
+// openSegmentLog opens the log in dir, creating it if needed. A torn
+// record at the end of the newest segment is cut off.
+func openSegmentLog(dir string, segmentSize, maxBytes int64) (*segmentLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	l := &segmentLog{dir: dir, segmentSize: segmentSize, maxBytes: maxBytes}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		var seq int
+		if _, err := fmt.Sscanf(name, "%08d"+segmentExt, &seq); err != nil {
+			continue
+		}
+		seg := segment{seq: seq, path: filepath.Join(dir, name)}
+		if info, err := e.Info(); err == nil {
+			seg.size = info.Size()
+		}
+		l.segs = append(l.segs, seg)
+	}
+	sort.Slice(l.segs, func(i, j int) bool { return l.segs[i].seq < l.segs[j].seq })
+
+	for i := range l.segs {
+		if err := l.segs[i].readFirst(); err != nil && err != io.EOF && !errors.Is(err, errCorrupt) {
+			return nil, err
+		}
+	}
+
+	if len(l.segs) == 0 {
+		return l, l.create(1)
+	}
+	if err := l.repair(); err != nil {
+		return nil, err
+	}
+	last := &l.segs[len(l.segs)-1]
+	l.file, err = os.OpenFile(last.path, os.O_RDWR|os.O_APPEND, 0644)
+	return l, err
+}
+
+// readFirst sets the segment's first record time.
+func (s *segment) readFirst() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rec, err := readRecord(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	s.first = rec.time
+	return nil
+}
+
+// repair truncates the newest segment after its last intact record.
+func (l *segmentLog) repair() error {
+	last := &l.segs[len(l.segs)-1]
+	f, err := os.Open(last.path)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	var valid int64
+	for {
+		rec, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		valid += int64(recordHeader + payloadHeader + len(rec.body))
+	}
+	f.Close()
+	if valid == last.size {
+		return nil
+	}
+	last.size = valid
+	return os.Truncate(last.path, valid)
+}
+
+func (l *segmentLog) create(seq int) error {
+	path := filepath.Join(l.dir, fmt.Sprintf("%08d"+segmentExt, seq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	l.file = f
+	l.segs = append(l.segs, segment{seq: seq, path: path})
+	return nil
+}
+
+// append writes one record, rotating and pruning segments as needed.
+func (l *segmentLog) append(rec record) error {
+	n := payloadHeader + len(rec.body)
+	if n > maxRecord {
+		return fmt.Errorf("logbook record of %d bytes exceeds %d", n, maxRecord)
+	}
+	active := &l.segs[len(l.segs)-1]
+	if l.segmentSize > 0 && active.size > 0 && active.size+int64(recordHeader+n) > l.segmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+		active = &l.segs[len(l.segs)-1]
+	}
+
+	buf := make([]byte, recordHeader+n)
+	payload := buf[recordHeader:]
+	payload[0] = rec.kind
+	binary.LittleEndian.PutUint64(payload[1:9], uint64(rec.time.UnixNano()))
+	copy(payload[payloadHeader:], rec.body)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(n))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+
+	if _, err := l.file.Write(buf); err != nil {
+		return err
+	}
+	if active.size == 0 {
+		active.first = rec.time
+	}
+	active.size += int64(len(buf))
+	return nil
+}
+
+func (l *segmentLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if err := l.create(l.segs[len(l.segs)-1].seq + 1); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// prune drops the oldest segments while the log exceeds maxBytes. The
+// active segment is always kept.
+func (l *segmentLog) prune() error {
+	if l.maxBytes <= 0 {
+		return nil
+	}
+	var total int64
+	for _, s := range l.segs {
+		total += s.size
+	}
+	for len(l.segs) > 1 && total > l.maxBytes {
+		if err := os.Remove(l.segs[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= l.segs[0].size
+		l.segs = l.segs[1:]
+	}
+	return nil
+}
+
+// snapshot returns the current segments for scanSegments. It must be
+// called under the same lock as append; the scan itself need not be.
+func (l *segmentLog) snapshot() []segment {
+	return append([]segment(nil), l.segs...)
+}
+
+// scanSegments calls fn for every record in [since, until] until it returns
+// false. Zero bounds are open. Segments that end before since are skipped;
+// records are assumed to be appended in roughly time order.
+func scanSegments(segs []segment, since, until time.Time, fn func(record) bool) error {
+	for i, s := range segs {
+		if !until.IsZero() && !s.first.IsZero() && s.first.After(until) {
+			break
+		}
+		if !since.IsZero() && i+1 < len(segs) && !segs[i+1].first.IsZero() && segs[i+1].first.Before(since) {
+			continue
+		}
+		more, err := scanSegment(s.path, s.size, since, until, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanSegment reads up to size bytes of a segment, so records appended
+// while it is read are left for the next scan.
+func scanSegment(path string, size int64, since, until time.Time, fn func(record) bool) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return true, nil // pruned since the scan started
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(io.LimitReader(f, size))
+	for {
+		rec, err := readRecord(r)
+		if err == io.EOF || errors.Is(err, errCorrupt) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if (!since.IsZero() && rec.time.Before(since)) || (!until.IsZero() && rec.time.After(until)) {
+			continue
+		}
+		if !fn(rec) {
+			return false, nil
+		}
+	}
+}
+
+// readRecord reads and verifies one record. A clean end of input is io.EOF;
+// a short or damaged record is errCorrupt.
+func readRecord(r *bufio.Reader) (record, error) {
+	var hdr [recordHeader]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return record{}, errCorrupt
+		}
+		return record{}, err
+	}
+	n := binary.LittleEndian.Uint32(hdr[0:4])
+	if n < payloadHeader || n > maxRecord {
+		return record{}, errCorrupt
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return record{}, errCorrupt
+		}
+		return record{}, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[4:8]) {
+		return record{}, errCorrupt
+	}
+	return record{
+		kind: payload[0],
+		time: time.Unix(0, int64(binary.LittleEndian.Uint64(payload[1:9]))),
+		body: payload[payloadHeader:],
+	}, nil
+}
+
+func (l *segmentLog) close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,109 @@
+package ltm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var ErrEmptyFrame = errors.New("ltm: frame has no data")
+
+// Encode serializes a decoded Frame back into a complete LTM frame:
+// '$' 'T' function payload checksum. It is the inverse of Decode.
+func Encode(f Frame) ([]byte, error) {
+	var fn byte
+	var payload []byte
+
+	switch {
+	case f.GPS != nil:
+		fn, payload = FuncGPS, encodeGPS(f.GPS)
+	case f.Attitude != nil:
+		fn, payload = FuncAttitude, encodeAttitude(f.Attitude)
+	case f.Status != nil:
+		fn, payload = FuncStatus, encodeStatus(f.Status)
+	case f.Origin != nil:
+		fn, payload = FuncOrigin, encodeOrigin(f.Origin)
+	case f.Nav != nil:
+		fn, payload = FuncNav, encodeNav(f.Nav)
+	case f.Extra != nil:
+		fn, payload = FuncExtra, encodeExtra(f.Extra)
+	default:
+		return nil, ErrEmptyFrame
+	}
+
+	out := make([]byte, 0, 3+len(payload)+1)
+	out = append(out, Header1, Header2, fn)
+	out = append(out, payload...)
+	out = append(out, xorChecksum(payload))
+	return out, nil
+}
+
+func encodeGPS(d *GPSData) []byte {
+	p := make([]byte, 14)
+	binary.LittleEndian.PutUint32(p[0:], uint32(scaleInt32(d.Lat, 1e7)))
+	binary.LittleEndian.PutUint32(p[4:], uint32(scaleInt32(d.Lon, 1e7)))
+	p[8] = d.GroundSpeed
+	binary.LittleEndian.PutUint32(p[9:], uint32(scaleInt32(d.Altitude, 100)))
+	p[13] = d.Sats<<2 | d.Fix&0x03
+	return p
+}
+
+func encodeAttitude(d *AttitudeData) []byte {
+	p := make([]byte, 6)
+	binary.LittleEndian.PutUint16(p[0:], uint16(d.Pitch))
+	binary.LittleEndian.PutUint16(p[2:], uint16(d.Roll))
+	binary.LittleEndian.PutUint16(p[4:], uint16(d.Heading))
+	return p
+}
+
+func encodeStatus(d *StatusData) []byte {
+	p := make([]byte, 7)
+	binary.LittleEndian.PutUint16(p[0:], uint16(math.Round(d.Vbat*1000)))
+	binary.LittleEndian.PutUint16(p[2:], d.MAhDrawn)
+	p[4] = d.RSSI
+	p[5] = d.Airspeed
+	p[6] = d.FlightMode << 2
+	if d.Armed {
+		p[6] |= 0x01
+	}
+	if d.Failsafe {
+		p[6] |= 0x02
+	}
+	return p
+}
+
+func encodeOrigin(d *OriginData) []byte {
+	p := make([]byte, 14)
+	binary.LittleEndian.PutUint32(p[0:], uint32(scaleInt32(d.Lat, 1e7)))
+	binary.LittleEndian.PutUint32(p[4:], uint32(scaleInt32(d.Lon, 1e7)))
+	binary.LittleEndian.PutUint32(p[8:], uint32(scaleInt32(d.Alt, 100)))
+	if d.OSDOn {
+		p[12] = 0x01
+	}
+	p[13] = d.Fix
+	return p
+}
+
+func encodeNav(d *NavData) []byte {
+	return []byte{d.GPSMode, d.NavMode, d.NavAction, d.WaypointNum, d.NavError, d.Flags}
+}
+
+func encodeExtra(d *ExtraData) []byte {
+	p := make([]byte, 6)
+	binary.LittleEndian.PutUint16(p[0:], uint16(math.Round(d.HDOP*100)))
+	p[2] = d.HWStatus
+	p[3] = d.XCounter
+	p[4] = d.DisarmReason
+	return p
+}
+
+// scaleInt32 converts a float in display units to the scaled wire integer.
+func scaleInt32(v, scale float64) int32 {
+	return int32(math.Round(v * scale))
+}
+
+// ClampUint8 rounds v to the nearest byte value, saturating at 0 and 255.
+// Converters use it for one-byte LTM fields such as speeds in m/s.
+func ClampUint8(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
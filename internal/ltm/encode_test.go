@@ -0,0 +1,112 @@
+package ltm
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestEncode_RoundTrip(t *testing.T) {
+	frames := []Frame{
+		{GPS: &GPSData{Lat: 51.5012345, Lon: -0.1278, GroundSpeed: 15, Altitude: -5.25, Fix: 3, Sats: 12}},
+		{Attitude: &AttitudeData{Pitch: -15, Roll: 30, Heading: 270}},
+		{Status: &StatusData{Vbat: 11.8, MAhDrawn: 1200, RSSI: 200, Airspeed: 25, Armed: true, Failsafe: true, FlightMode: 10}},
+		{Origin: &OriginData{Lat: 51.5, Lon: -0.1278, Alt: 50, OSDOn: true, Fix: 3}},
+		{Nav: &NavData{GPSMode: 2, NavMode: 5, NavAction: 1, WaypointNum: 7, NavError: 0, Flags: 0xFF}},
+		{Extra: &ExtraData{HDOP: 1.5, HWStatus: 1, XCounter: 42, DisarmReason: 3}},
+	}
+
+	for _, in := range frames {
+		data, err := Encode(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []RawFrame
+		p := NewParser(func(f RawFrame) { got = append(got, f) }, func(err error) {
+			t.Errorf("parser error: %v", err)
+		})
+		p.Write(data)
+		if len(got) != 1 {
+			t.Fatalf("parsed %d frames, want 1", len(got))
+		}
+
+		out, err := Decode(got[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case in.GPS != nil:
+			g := out.GPS
+			if math.Abs(g.Lat-in.GPS.Lat) > 1e-7 || math.Abs(g.Lon-in.GPS.Lon) > 1e-7 {
+				t.Errorf("gps position = %f,%f, want %f,%f", g.Lat, g.Lon, in.GPS.Lat, in.GPS.Lon)
+			}
+			if math.Abs(g.Altitude-in.GPS.Altitude) > 0.01 {
+				t.Errorf("gps altitude = %f, want %f", g.Altitude, in.GPS.Altitude)
+			}
+			if g.GroundSpeed != in.GPS.GroundSpeed || g.Fix != in.GPS.Fix || g.Sats != in.GPS.Sats {
+				t.Errorf("gps = %+v, want %+v", *g, *in.GPS)
+			}
+		case in.Attitude != nil:
+			if *out.Attitude != *in.Attitude {
+				t.Errorf("attitude = %+v, want %+v", *out.Attitude, *in.Attitude)
+			}
+		case in.Status != nil:
+			if *out.Status != *in.Status {
+				t.Errorf("status = %+v, want %+v", *out.Status, *in.Status)
+			}
+		case in.Origin != nil:
+			if *out.Origin != *in.Origin {
+				t.Errorf("origin = %+v, want %+v", *out.Origin, *in.Origin)
+			}
+		case in.Nav != nil:
+			if *out.Nav != *in.Nav {
+				t.Errorf("nav = %+v, want %+v", *out.Nav, *in.Nav)
+			}
+		case in.Extra != nil:
+			if *out.Extra != *in.Extra {
+				t.Errorf("extra = %+v, want %+v", *out.Extra, *in.Extra)
+			}
+		}
+	}
+}
+
+func TestEncode_MatchesBuildLTMFrame(t *testing.T) {
+	a := &AttitudeData{Pitch: 1, Roll: 0, Heading: 0x167}
+	got, err := Encode(Frame{Attitude: a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := buildLTMFrame(FuncAttitude, []byte{0x01, 0x00, 0x00, 0x00, 0x67, 0x01})
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode = %x, want %x", got, want)
+	}
+}
+
+func TestEncode_PayloadSizes(t *testing.T) {
+	frames := []Frame{
+		{GPS: &GPSData{}},
+		{Attitude: &AttitudeData{}},
+		{Status: &StatusData{}},
+		{Origin: &OriginData{}},
+		{Nav: &NavData{}},
+		{Extra: &ExtraData{}},
+	}
+	for _, f := range frames {
+		data, err := Encode(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn := data[2]
+		if len(data) != 3+PayloadSize[fn]+1 {
+			t.Errorf("%s frame length = %d, want %d", FrameName[fn], len(data), 3+PayloadSize[fn]+1)
+		}
+	}
+}
+
+func TestEncode_Empty(t *testing.T) {
+	if _, err := Encode(Frame{}); err != ErrEmptyFrame {
+		t.Errorf("err = %v, want ErrEmptyFrame", err)
+	}
+}
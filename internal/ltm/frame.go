@@ -39,29 +39,55 @@ var FrameName = map[byte]string{
 }
 
 // Flight mode enum (0-21).
+const (
+	ModeManual      = 0
+	ModeRate        = 1
+	ModeAngle       = 2
+	ModeHorizon     = 3
+	ModeAcro        = 4
+	ModeStabilized1 = 5
+	ModeStabilized2 = 6
+	ModeStabilized3 = 7
+	ModeAltHold     = 8
+	ModeGPSHold     = 9
+	ModeWaypoints   = 10
+	ModeHeadFree    = 11
+	ModeCircle      = 12
+	ModeRTH         = 13
+	ModeFollowMe    = 14
+	ModeLand        = 15
+	ModeFBWA        = 16
+	ModeFBWB        = 17
+	ModeCruise      = 18
+	ModeUnknown     = 19
+	ModeLaunch      = 20
+	ModeAutotune    = 21
+)
+
+// FlightModeName maps each flight mode to a human-readable name.
 var FlightModeName = map[uint8]string{
-	0:  "Manual",
-	1:  "Rate",
-	2:  "Angle",
-	3:  "Horizon",
-	4:  "Acro",
-	5:  "Stabilized1",
-	6:  "Stabilized2",
-	7:  "Stabilized3",
-	8:  "Altitude Hold",
-	9:  "GPS Hold",
-	10: "Waypoints",
-	11: "Head Free",
-	12: "Circle",
-	13: "RTH",
-	14: "Follow Me",
-	15: "Land",
-	16: "Fly By Wire A",
-	17: "Fly By Wire B",
-	18: "Cruise",
-	19: "Unknown",
-	20: "Launch",
-	21: "Autotune",
+	ModeManual:      "Manual",
+	ModeRate:        "Rate",
+	ModeAngle:       "Angle",
+	ModeHorizon:     "Horizon",
+	ModeAcro:        "Acro",
+	ModeStabilized1: "Stabilized1",
+	ModeStabilized2: "Stabilized2",
+	ModeStabilized3: "Stabilized3",
+	ModeAltHold:     "Altitude Hold",
+	ModeGPSHold:     "GPS Hold",
+	ModeWaypoints:   "Waypoints",
+	ModeHeadFree:    "Head Free",
+	ModeCircle:      "Circle",
+	ModeRTH:         "RTH",
+	ModeFollowMe:    "Follow Me",
+	ModeLand:        "Land",
+	ModeFBWA:        "Fly By Wire A",
+	ModeFBWB:        "Fly By Wire B",
+	ModeCruise:      "Cruise",
+	ModeUnknown:     "Unknown",
+	ModeLaunch:      "Launch",
+	ModeAutotune:    "Autotune",
 }
 
 // GPS mode enum (0-3).
@@ -121,6 +147,19 @@ var NavErrorName = map[uint8]string{
 	11: "Landing Check",
 }
 
+// Disarm reason enum (0-8).
+var DisarmReasonName = map[uint8]string{
+	0: "None",
+	1: "Timeout",
+	2: "Sticks",
+	3: "Switch 3D",
+	4: "Switch",
+	5: "Kill Switch",
+	6: "Failsafe",
+	7: "Navigation",
+	8: "Landing",
+}
+
 // GPSData from the G-frame (14 bytes, 5 Hz).
 type GPSData struct {
 	Lat         float64 `json:"lat"`          // degrees (int32/1e7)
@@ -0,0 +1,293 @@
+package mavlink
+
+import (
+	"math"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// MAVLink enum values used by the converter.
+const (
+	autopilotArduPilot = 3
+	autopilotInvalid   = 8
+
+	modeFlagCustomModeEnabled = 0x01
+	modeFlagSafetyArmed       = 0x80
+
+	stateCritical  = 5
+	stateEmergency = 6
+
+	typeFixedWing = 1
+	typeVTOLFirst = 19
+	typeVTOLLast  = 25
+
+	fixType2D = 2
+	fixType3D = 3
+)
+
+// ArduCopter custom_mode → LTM flight mode.
+var copterModes = map[uint32]uint8{
+	0:  ltm.ModeAngle,     // STABILIZE
+	1:  ltm.ModeAcro,      // ACRO
+	2:  ltm.ModeAltHold,   // ALT_HOLD
+	3:  ltm.ModeWaypoints, // AUTO
+	4:  ltm.ModeGPSHold,   // GUIDED
+	5:  ltm.ModeGPSHold,   // LOITER
+	6:  ltm.ModeRTH,       // RTL
+	7:  ltm.ModeCircle,    // CIRCLE
+	9:  ltm.ModeLand,      // LAND
+	11: ltm.ModeHorizon,   // DRIFT
+	13: ltm.ModeRate,      // SPORT
+	14: ltm.ModeAcro,      // FLIP
+	15: ltm.ModeAutotune,  // AUTOTUNE
+	16: ltm.ModeGPSHold,   // POSHOLD
+	17: ltm.ModeGPSHold,   // BRAKE
+	18: ltm.ModeLaunch,    // THROW
+	21: ltm.ModeRTH,       // SMART_RTL
+	23: ltm.ModeFollowMe,  // FOLLOW
+}
+
+// ArduPlane custom_mode → LTM flight mode.
+var planeModes = map[uint32]uint8{
+	0:  ltm.ModeManual,    // MANUAL
+	1:  ltm.ModeCircle,    // CIRCLE
+	2:  ltm.ModeAngle,     // STABILIZE
+	3:  ltm.ModeHorizon,   // TRAINING
+	4:  ltm.ModeAcro,      // ACRO
+	5:  ltm.ModeFBWA,      // FLY_BY_WIRE_A
+	6:  ltm.ModeFBWB,      // FLY_BY_WIRE_B
+	7:  ltm.ModeCruise,    // CRUISE
+	8:  ltm.ModeAutotune,  // AUTOTUNE
+	10: ltm.ModeWaypoints, // AUTO
+	11: ltm.ModeRTH,       // RTL
+	12: ltm.ModeGPSHold,   // LOITER
+	13: ltm.ModeLaunch,    // TAKEOFF
+	15: ltm.ModeGPSHold,   // GUIDED
+	17: ltm.ModeAngle,     // QSTABILIZE
+	18: ltm.ModeAltHold,   // QHOVER
+	19: ltm.ModeGPSHold,   // QLOITER
+	20: ltm.ModeLand,      // QLAND
+	21: ltm.ModeRTH,       // QRTL
+}
+
+// maxCurrentGap bounds the interval over which battery current is integrated,
+// so a telemetry dropout does not add a large step to mAh drawn.
+const maxCurrentGap = 5 * time.Second
+
+// Converter maps decoded MAVLink messages onto the LTM frame types used by
+// telemetry.Store, so MAVLink vehicles show up on the same dashboard.
+//
+// Several MAVLink messages contribute to one LTM frame (HEARTBEAT and
+// SYS_STATUS both feed the status frame), so the Converter keeps the merged
+// state and emits a full copy whenever a contributing message arrives.
+// Messages from systems other than the first autopilot heard are ignored,
+// which keeps GCS and companion-computer heartbeats from clobbering state.
+type Converter struct {
+	sysID  uint8
+	locked bool
+
+	gps    ltm.GPSData
+	att    ltm.AttitudeData
+	status ltm.StatusData
+	nav    ltm.NavData
+	extra  ltm.ExtraData
+
+	homeAlt      float64
+	haveHome     bool
+	haveFusedPos bool
+
+	// refAlt is the MSL altitude of the first GPS fix, which GPS_RAW_INT
+	// altitudes are reported against until HOME_POSITION arrives.
+	refAlt  float64
+	haveRef bool
+
+	mah      float64
+	lastCurr time.Time
+}
+
+// NewConverter creates an empty Converter.
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+// Frames folds m into the converter state and returns the LTM frames it
+// updates. The result may be empty.
+func (c *Converter) Frames(m Message) []ltm.Frame {
+	if m.Heartbeat != nil && !c.locked && m.Heartbeat.Autopilot != autopilotInvalid {
+		c.sysID, c.locked = m.SysID, true
+	}
+	if c.locked && m.SysID != c.sysID {
+		return nil
+	}
+
+	switch {
+	case m.Heartbeat != nil:
+		if m.Heartbeat.Autopilot == autopilotInvalid {
+			return nil
+		}
+		c.heartbeat(m.Heartbeat)
+		return []ltm.Frame{c.statusFrame(m.Time)}
+
+	case m.SysStatus != nil:
+		c.sysStatus(m.SysStatus, m.Time)
+		return []ltm.Frame{c.statusFrame(m.Time)}
+
+	case m.GPSRawInt != nil:
+		c.gpsRaw(m.GPSRawInt)
+		extra := c.extra
+		return []ltm.Frame{
+			c.gpsFrame(m.Time),
+			{Function: ltm.FuncExtra, Name: ltm.FrameName[ltm.FuncExtra], Time: m.Time, Extra: &extra},
+		}
+
+	case m.GlobalPositionInt != nil:
+		g := m.GlobalPositionInt
+		c.haveFusedPos = true
+		c.gps.Lat = float64(g.Lat) / 1e7
+		c.gps.Lon = float64(g.Lon) / 1e7
+		c.gps.Altitude = float64(g.RelativeAlt) / 1000
+		return []ltm.Frame{c.gpsFrame(m.Time)}
+
+	case m.Attitude != nil:
+		a := m.Attitude
+		c.att.Roll = int16(math.Round(degrees(a.Roll)))
+		c.att.Pitch = int16(math.Round(degrees(a.Pitch)))
+		c.att.Heading = int16(math.Round(math.Mod(degrees(a.Yaw)+360, 360)))
+		att := c.att
+		return []ltm.Frame{{Function: ltm.FuncAttitude, Name: ltm.FrameName[ltm.FuncAttitude], Time: m.Time, Attitude: &att}}
+
+	case m.VFRHUD != nil:
+		// VFR_HUD refines fields carried by other frames; it is folded in
+		// without emitting so it does not inflate GPS/status frame rates.
+		c.gps.GroundSpeed = ltm.ClampUint8(float64(m.VFRHUD.Groundspeed))
+		c.status.Airspeed = ltm.ClampUint8(float64(m.VFRHUD.Airspeed))
+		return nil
+
+	case m.HomePosition != nil:
+		h := m.HomePosition
+		c.homeAlt, c.haveHome = float64(h.Altitude)/1000, true
+		return []ltm.Frame{{
+			Function: ltm.FuncOrigin,
+			Name:     ltm.FrameName[ltm.FuncOrigin],
+			Time:     m.Time,
+			Origin: &ltm.OriginData{
+				Lat:   float64(h.Latitude) / 1e7,
+				Lon:   float64(h.Longitude) / 1e7,
+				Alt:   c.homeAlt,
+				OSDOn: true,
+				Fix:   1,
+			},
+		}}
+
+	case m.NavControllerOutput != nil:
+		// LTM's nav frame has no distance or bearing fields; the message
+		// only signals that the autopilot is navigating in the current mode.
+		nav := c.nav
+		return []ltm.Frame{{Function: ltm.FuncNav, Name: ltm.FrameName[ltm.FuncNav], Time: m.Time, Nav: &nav}}
+	}
+	return nil
+}
+
+func (c *Converter) heartbeat(h *Heartbeat) {
+	c.status.Armed = h.BaseMode&modeFlagSafetyArmed != 0
+	c.status.Failsafe = h.SystemStatus == stateCritical || h.SystemStatus == stateEmergency
+
+	mode := uint8(ltm.ModeUnknown)
+	if h.Autopilot == autopilotArduPilot && h.BaseMode&modeFlagCustomModeEnabled != 0 {
+		table := copterModes
+		if h.Type == typeFixedWing || (h.Type >= typeVTOLFirst && h.Type <= typeVTOLLast) {
+			table = planeModes
+		}
+		if m, ok := table[h.CustomMode]; ok {
+			mode = m
+		}
+	}
+	c.status.FlightMode = mode
+	c.nav = navFor(mode)
+}
+
+func (c *Converter) sysStatus(s *SysStatus, now time.Time) {
+	if s.VoltageBattery != math.MaxUint16 {
+		c.status.Vbat = float64(s.VoltageBattery) / 1000
+	}
+	if s.CurrentBattery >= 0 {
+		if !c.lastCurr.IsZero() {
+			if dt := now.Sub(c.lastCurr); dt > 0 && dt <= maxCurrentGap {
+				c.mah += float64(s.CurrentBattery) / 100 * dt.Hours() * 1000
+			}
+		}
+		c.lastCurr = now
+		c.status.MAhDrawn = uint16(math.Min(c.mah, math.MaxUint16))
+	}
+}
+
+func (c *Converter) gpsRaw(g *GPSRawInt) {
+	switch {
+	case g.FixType >= fixType3D:
+		c.gps.Fix = 3
+	case g.FixType == fixType2D:
+		c.gps.Fix = 2
+	default:
+		c.gps.Fix = 0
+	}
+	if g.SatellitesVisible != math.MaxUint8 {
+		c.gps.Sats = g.SatellitesVisible
+	}
+	if g.Vel != math.MaxUint16 {
+		c.gps.GroundSpeed = ltm.ClampUint8(float64(g.Vel) / 100)
+	}
+	if g.EPH != math.MaxUint16 {
+		c.extra.HDOP = float64(g.EPH) / 100
+	}
+
+	// Prefer the EKF position from GLOBAL_POSITION_INT when it is streamed.
+	if !c.haveFusedPos {
+		c.gps.Lat = float64(g.Lat) / 1e7
+		c.gps.Lon = float64(g.Lon) / 1e7
+		// LTM altitude is relative to home. GPS_RAW_INT altitude is MSL, so
+		// before HOME_POSITION the first fix stands in for home, and without
+		// a fix there is no reference at all.
+		alt := float64(g.Alt) / 1000
+		if !c.haveRef && c.gps.Fix >= 2 {
+			c.refAlt, c.haveRef = alt, true
+		}
+		switch {
+		case c.haveHome:
+			c.gps.Altitude = alt - c.homeAlt
+		case c.haveRef:
+			c.gps.Altitude = alt - c.refAlt
+		default:
+			c.gps.Altitude = 0
+		}
+	}
+}
+
+func (c *Converter) gpsFrame(t time.Time) ltm.Frame {
+	gps := c.gps
+	return ltm.Frame{Function: ltm.FuncGPS, Name: ltm.FrameName[ltm.FuncGPS], Time: t, GPS: &gps}
+}
+
+func (c *Converter) statusFrame(t time.Time) ltm.Frame {
+	status := c.status
+	return ltm.Frame{Function: ltm.FuncStatus, Name: ltm.FrameName[ltm.FuncStatus], Time: t, Status: &status}
+}
+
+// navFor derives LTM GPS/nav mode fields from the current flight mode.
+func navFor(mode uint8) ltm.NavData {
+	switch mode {
+	case ltm.ModeGPSHold, ltm.ModeCircle:
+		return ltm.NavData{GPSMode: 1, NavMode: 3, NavAction: 2}
+	case ltm.ModeRTH:
+		return ltm.NavData{GPSMode: 2, NavMode: 2, NavAction: 4}
+	case ltm.ModeWaypoints:
+		return ltm.NavData{GPSMode: 3, NavMode: 5, NavAction: 1}
+	case ltm.ModeLand:
+		return ltm.NavData{NavMode: 9, NavAction: 8}
+	}
+	return ltm.NavData{}
+}
+
+func degrees(rad float32) float64 {
+	return float64(rad) * 180 / math.Pi
+}
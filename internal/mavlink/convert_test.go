@@ -0,0 +1,194 @@
+package mavlink
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func heartbeat(sysID uint8, vehicleType uint8, mode uint32, armed bool) Message {
+	base := uint8(modeFlagCustomModeEnabled)
+	if armed {
+		base |= modeFlagSafetyArmed
+	}
+	return Message{SysID: sysID, Time: time.Now(), Heartbeat: &Heartbeat{
+		CustomMode: mode,
+		Type:       vehicleType,
+		Autopilot:  autopilotArduPilot,
+		BaseMode:   base,
+	}}
+}
+
+func TestConverter_HeartbeatStatus(t *testing.T) {
+	c := NewConverter()
+	frames := c.Frames(heartbeat(1, 2, 6, true)) // copter RTL
+
+	if len(frames) != 1 || frames[0].Status == nil {
+		t.Fatalf("frames = %+v, want one status frame", frames)
+	}
+	s := frames[0].Status
+	if !s.Armed {
+		t.Error("armed = false, want true")
+	}
+	if ltm.FlightModeName[s.FlightMode] != "RTH" {
+		t.Errorf("flight mode = %q, want RTH", ltm.FlightModeName[s.FlightMode])
+	}
+}
+
+func TestConverter_PlaneModes(t *testing.T) {
+	c := NewConverter()
+	frames := c.Frames(heartbeat(1, typeFixedWing, 5, false))
+	if got := ltm.FlightModeName[frames[0].Status.FlightMode]; got != "Fly By Wire A" {
+		t.Errorf("flight mode = %q, want Fly By Wire A", got)
+	}
+}
+
+func TestConverter_FailsafeFromSystemStatus(t *testing.T) {
+	c := NewConverter()
+	m := heartbeat(1, 2, 0, true)
+	m.Heartbeat.SystemStatus = stateCritical
+	frames := c.Frames(m)
+	if !frames[0].Status.Failsafe {
+		t.Error("failsafe = false, want true for MAV_STATE_CRITICAL")
+	}
+}
+
+func TestConverter_IgnoresOtherSystems(t *testing.T) {
+	c := NewConverter()
+	c.Frames(heartbeat(1, 2, 0, true))
+
+	gcs := Message{SysID: 255, Time: time.Now(), Heartbeat: &Heartbeat{Autopilot: autopilotInvalid}}
+	if frames := c.Frames(gcs); len(frames) != 0 {
+		t.Errorf("GCS heartbeat produced %d frames", len(frames))
+	}
+
+	other := Message{SysID: 2, Time: time.Now(), Attitude: &Attitude{Roll: 1}}
+	if frames := c.Frames(other); len(frames) != 0 {
+		t.Errorf("second vehicle produced %d frames", len(frames))
+	}
+}
+
+func TestConverter_SysStatusIntegratesCurrent(t *testing.T) {
+	c := NewConverter()
+	t0 := time.Now()
+
+	c.Frames(Message{Time: t0, SysStatus: &SysStatus{VoltageBattery: 16000, CurrentBattery: 3600}})
+	frames := c.Frames(Message{Time: t0.Add(time.Second), SysStatus: &SysStatus{VoltageBattery: 15900, CurrentBattery: 3600}})
+
+	s := frames[0].Status
+	if math.Abs(s.Vbat-15.9) > 0.001 {
+		t.Errorf("vbat = %f, want 15.9", s.Vbat)
+	}
+	// 36 A for one second = 10 mAh
+	if s.MAhDrawn != 10 {
+		t.Errorf("mah = %d, want 10", s.MAhDrawn)
+	}
+
+	// A long dropout must not be integrated.
+	frames = c.Frames(Message{Time: t0.Add(time.Minute), SysStatus: &SysStatus{VoltageBattery: 15900, CurrentBattery: 3600}})
+	if frames[0].Status.MAhDrawn != 10 {
+		t.Errorf("mah after gap = %d, want 10", frames[0].Status.MAhDrawn)
+	}
+}
+
+func TestConverter_GPS(t *testing.T) {
+	c := NewConverter()
+	now := time.Now()
+
+	c.Frames(Message{Time: now, HomePosition: &HomePosition{Latitude: 515000000, Longitude: -1278000, Altitude: 30000}})
+
+	frames := c.Frames(Message{Time: now, GPSRawInt: &GPSRawInt{
+		Lat: 515001000, Lon: -1277000, Alt: 80000,
+		EPH: 90, Vel: 1240, FixType: 6, SatellitesVisible: 17,
+	}})
+	if len(frames) != 2 || frames[0].GPS == nil || frames[1].Extra == nil {
+		t.Fatalf("frames = %+v, want GPS + Extra", frames)
+	}
+	g := frames[0].GPS
+	if g.Fix != 3 || g.Sats != 17 || g.GroundSpeed != 12 {
+		t.Errorf("gps = %+v", *g)
+	}
+	if math.Abs(g.Altitude-50) > 0.001 {
+		t.Errorf("altitude = %f, want 50 (relative to home)", g.Altitude)
+	}
+	if math.Abs(frames[1].Extra.HDOP-0.9) > 0.001 {
+		t.Errorf("hdop = %f, want 0.9", frames[1].Extra.HDOP)
+	}
+
+	frames = c.Frames(Message{Time: now, GlobalPositionInt: &GlobalPositionInt{
+		Lat: 515002000, Lon: -1276000, RelativeAlt: 42500,
+	}})
+	g = frames[0].GPS
+	if math.Abs(g.Lat-51.5002) > 1e-7 || math.Abs(g.Altitude-42.5) > 0.001 {
+		t.Errorf("fused gps = %+v", *g)
+	}
+	if g.Sats != 17 {
+		t.Error("fused position should keep satellite count from GPS_RAW_INT")
+	}
+
+	// Once fused positions are streamed, raw GPS no longer moves the marker.
+	frames = c.Frames(Message{Time: now, GPSRawInt: &GPSRawInt{Lat: 1, Lon: 1, FixType: 3}})
+	if math.Abs(frames[0].GPS.Lat-51.5002) > 1e-7 {
+		t.Errorf("raw GPS overrode fused position: lat = %f", frames[0].GPS.Lat)
+	}
+}
+
+func TestConverter_GPSAltitudeBeforeHome(t *testing.T) {
+	c := NewConverter()
+	now := time.Now()
+	raw := func(alt int32, fix uint8) float64 {
+		frames := c.Frames(Message{Time: now, GPSRawInt: &GPSRawInt{Lat: 515000000, Lon: -1278000, Alt: alt, FixType: fix}})
+		return frames[0].GPS.Altitude
+	}
+
+	// MSL altitude is never reported as height above home.
+	if alt := raw(412000, 1); alt != 0 {
+		t.Errorf("altitude without fix = %f, want 0", alt)
+	}
+	if alt := raw(300000, 3); alt != 0 {
+		t.Errorf("altitude at first fix = %f, want 0", alt)
+	}
+	if alt := raw(325000, 3); math.Abs(alt-25) > 0.001 {
+		t.Errorf("altitude = %f, want 25 above the first fix", alt)
+	}
+
+	// HOME_POSITION replaces the first fix as the reference.
+	c.Frames(Message{Time: now, HomePosition: &HomePosition{Latitude: 515000000, Longitude: -1278000, Altitude: 298000}})
+	if alt := raw(325000, 3); math.Abs(alt-27) > 0.001 {
+		t.Errorf("altitude = %f, want 27 above home", alt)
+	}
+}
+
+func TestConverter_AttitudeAndHUD(t *testing.T) {
+	c := NewConverter()
+	frames := c.Frames(Message{Time: time.Now(), Attitude: &Attitude{
+		Roll:  float32(10 * math.Pi / 180),
+		Pitch: float32(-5 * math.Pi / 180),
+		Yaw:   float32(-90 * math.Pi / 180),
+	}})
+	a := frames[0].Attitude
+	if a.Roll != 10 || a.Pitch != -5 || a.Heading != 270 {
+		t.Errorf("attitude = %+v", *a)
+	}
+
+	if frames := c.Frames(Message{Time: time.Now(), VFRHUD: &VFRHUD{Airspeed: 18.4, Groundspeed: 15.6}}); len(frames) != 0 {
+		t.Errorf("VFR_HUD emitted %d frames, want 0", len(frames))
+	}
+	frames = c.Frames(Message{Time: time.Now(), SysStatus: &SysStatus{VoltageBattery: 12000, CurrentBattery: -1}})
+	if frames[0].Status.Airspeed != 18 {
+		t.Errorf("airspeed = %d, want 18", frames[0].Status.Airspeed)
+	}
+}
+
+func TestConverter_NavFromMode(t *testing.T) {
+	c := NewConverter()
+	c.Frames(heartbeat(1, 2, 3, true)) // AUTO
+
+	frames := c.Frames(Message{SysID: 1, Time: time.Now(), NavControllerOutput: &NavControllerOutput{WPDist: 120}})
+	n := frames[0].Nav
+	if ltm.NavModeName[n.NavMode] != "WP Enroute" || ltm.GPSModeName[n.GPSMode] != "Mission" {
+		t.Errorf("nav = %+v", *n)
+	}
+}
@@ -0,0 +1,18 @@
+package mavlink
+
+// crcAccumulate adds one byte to a CRC-16/MCRF4XX (X.25) checksum, as used
+// by MAVLink.
+func crcAccumulate(b byte, crc uint16) uint16 {
+	tmp := b ^ byte(crc)
+	tmp ^= tmp << 4
+	return crc>>8 ^ uint16(tmp)<<8 ^ uint16(tmp)<<3 ^ uint16(tmp)>>4
+}
+
+// crcCalculate returns the X.25 checksum of data.
+func crcCalculate(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc = crcAccumulate(b, crc)
+	}
+	return crc
+}
@@ -0,0 +1,131 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Decode interprets a RawMessage's payload and returns a typed Message.
+func Decode(m RawMessage) (Message, error) {
+	r := Message{
+		MsgID:  m.MsgID,
+		Name:   MessageName[m.MsgID],
+		SysID:  m.SysID,
+		CompID: m.CompID,
+		Time:   time.Now(),
+	}
+
+	info, ok := messages[m.MsgID]
+	if !ok {
+		return r, fmt.Errorf("mavlink: unknown message %d", m.MsgID)
+	}
+
+	// MAVLink 2 strips trailing zero bytes; restore them before decoding.
+	p := m.Payload
+	if len(p) < info.size {
+		p = make([]byte, info.size)
+		copy(p, m.Payload)
+	}
+
+	switch m.MsgID {
+	case MsgHeartbeat:
+		r.Heartbeat = decodeHeartbeat(p)
+	case MsgSysStatus:
+		r.SysStatus = decodeSysStatus(p)
+	case MsgGPSRawInt:
+		r.GPSRawInt = decodeGPSRawInt(p)
+	case MsgAttitude:
+		r.Attitude = decodeAttitude(p)
+	case MsgGlobalPositionInt:
+		r.GlobalPositionInt = decodeGlobalPositionInt(p)
+	case MsgNavControllerOutput:
+		r.NavControllerOutput = decodeNavControllerOutput(p)
+	case MsgVFRHUD:
+		r.VFRHUD = decodeVFRHUD(p)
+	case MsgHomePosition:
+		r.HomePosition = decodeHomePosition(p)
+	}
+	return r, nil
+}
+
+func decodeHeartbeat(p []byte) *Heartbeat {
+	return &Heartbeat{
+		CustomMode:   binary.LittleEndian.Uint32(p[0:]),
+		Type:         p[4],
+		Autopilot:    p[5],
+		BaseMode:     p[6],
+		SystemStatus: p[7],
+	}
+}
+
+func decodeSysStatus(p []byte) *SysStatus {
+	return &SysStatus{
+		VoltageBattery:   binary.LittleEndian.Uint16(p[14:]),
+		CurrentBattery:   int16(binary.LittleEndian.Uint16(p[16:])),
+		BatteryRemaining: int8(p[30]),
+	}
+}
+
+func decodeGPSRawInt(p []byte) *GPSRawInt {
+	return &GPSRawInt{
+		Lat:               int32(binary.LittleEndian.Uint32(p[8:])),
+		Lon:               int32(binary.LittleEndian.Uint32(p[12:])),
+		Alt:               int32(binary.LittleEndian.Uint32(p[16:])),
+		EPH:               binary.LittleEndian.Uint16(p[20:]),
+		Vel:               binary.LittleEndian.Uint16(p[24:]),
+		COG:               binary.LittleEndian.Uint16(p[26:]),
+		FixType:           p[28],
+		SatellitesVisible: p[29],
+	}
+}
+
+func decodeAttitude(p []byte) *Attitude {
+	return &Attitude{
+		Roll:  float32At(p, 4),
+		Pitch: float32At(p, 8),
+		Yaw:   float32At(p, 12),
+	}
+}
+
+func decodeGlobalPositionInt(p []byte) *GlobalPositionInt {
+	return &GlobalPositionInt{
+		Lat:         int32(binary.LittleEndian.Uint32(p[4:])),
+		Lon:         int32(binary.LittleEndian.Uint32(p[8:])),
+		Alt:         int32(binary.LittleEndian.Uint32(p[12:])),
+		RelativeAlt: int32(binary.LittleEndian.Uint32(p[16:])),
+		Hdg:         binary.LittleEndian.Uint16(p[26:]),
+	}
+}
+
+func decodeNavControllerOutput(p []byte) *NavControllerOutput {
+	return &NavControllerOutput{
+		NavBearing:    int16(binary.LittleEndian.Uint16(p[20:])),
+		TargetBearing: int16(binary.LittleEndian.Uint16(p[22:])),
+		WPDist:        binary.LittleEndian.Uint16(p[24:]),
+	}
+}
+
+func decodeVFRHUD(p []byte) *VFRHUD {
+	return &VFRHUD{
+		Airspeed:    float32At(p, 0),
+		Groundspeed: float32At(p, 4),
+		Alt:         float32At(p, 8),
+		Climb:       float32At(p, 12),
+		Heading:     int16(binary.LittleEndian.Uint16(p[16:])),
+		Throttle:    binary.LittleEndian.Uint16(p[18:]),
+	}
+}
+
+func decodeHomePosition(p []byte) *HomePosition {
+	return &HomePosition{
+		Latitude:  int32(binary.LittleEndian.Uint32(p[0:])),
+		Longitude: int32(binary.LittleEndian.Uint32(p[4:])),
+		Altitude:  int32(binary.LittleEndian.Uint32(p[8:])),
+	}
+}
+
+func float32At(p []byte, off int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(p[off:]))
+}
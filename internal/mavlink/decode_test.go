@@ -0,0 +1,154 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func putF32(p []byte, off int, v float32) {
+	binary.LittleEndian.PutUint32(p[off:], math.Float32bits(v))
+}
+
+func TestDecodeHeartbeat(t *testing.T) {
+	p := make([]byte, 9)
+	binary.LittleEndian.PutUint32(p[0:], 6) // RTL
+	p[4] = 2                                // quadrotor
+	p[5] = 3                                // ArduPilot
+	p[6] = 0x81                             // armed + custom mode
+	p[7] = 4                                // active
+
+	m, err := Decode(RawMessage{MsgID: MsgHeartbeat, Payload: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := m.Heartbeat
+	if h == nil {
+		t.Fatal("heartbeat is nil")
+	}
+	if h.CustomMode != 6 || h.Type != 2 || h.Autopilot != 3 || h.BaseMode != 0x81 || h.SystemStatus != 4 {
+		t.Errorf("heartbeat = %+v", *h)
+	}
+	if m.Name != "HEARTBEAT" {
+		t.Errorf("name = %q", m.Name)
+	}
+}
+
+func TestDecodeSysStatus(t *testing.T) {
+	p := make([]byte, 31)
+	binary.LittleEndian.PutUint16(p[14:], 11800)
+	binary.LittleEndian.PutUint16(p[16:], 1250)
+	p[30] = 87
+
+	m, _ := Decode(RawMessage{MsgID: MsgSysStatus, Payload: p})
+	s := m.SysStatus
+	if s.VoltageBattery != 11800 || s.CurrentBattery != 1250 || s.BatteryRemaining != 87 {
+		t.Errorf("sys_status = %+v", *s)
+	}
+}
+
+func TestDecodeGPSRawInt(t *testing.T) {
+	p := make([]byte, 30)
+	binary.LittleEndian.PutUint32(p[8:], uint32(515000000))
+	lon := int32(-1278000)
+	binary.LittleEndian.PutUint32(p[12:], uint32(lon))
+	binary.LittleEndian.PutUint32(p[16:], 45000)
+	binary.LittleEndian.PutUint16(p[20:], 120)
+	binary.LittleEndian.PutUint16(p[24:], 1550)
+	p[28] = 3
+	p[29] = 14
+
+	m, _ := Decode(RawMessage{MsgID: MsgGPSRawInt, Payload: p})
+	g := m.GPSRawInt
+	if g.Lat != 515000000 || g.Lon != -1278000 || g.Alt != 45000 {
+		t.Errorf("position = %d,%d,%d", g.Lat, g.Lon, g.Alt)
+	}
+	if g.EPH != 120 || g.Vel != 1550 || g.FixType != 3 || g.SatellitesVisible != 14 {
+		t.Errorf("gps_raw_int = %+v", *g)
+	}
+}
+
+func TestDecodeAttitude(t *testing.T) {
+	p := make([]byte, 28)
+	putF32(p, 4, 0.5)
+	putF32(p, 8, -0.25)
+	putF32(p, 12, 3.0)
+
+	m, _ := Decode(RawMessage{MsgID: MsgAttitude, Payload: p})
+	a := m.Attitude
+	if a.Roll != 0.5 || a.Pitch != -0.25 || a.Yaw != 3.0 {
+		t.Errorf("attitude = %+v", *a)
+	}
+}
+
+func TestDecodeGlobalPositionInt(t *testing.T) {
+	p := make([]byte, 28)
+	binary.LittleEndian.PutUint32(p[4:], 515000000)
+	binary.LittleEndian.PutUint32(p[16:], 12500)
+	binary.LittleEndian.PutUint16(p[26:], 9000)
+
+	m, _ := Decode(RawMessage{MsgID: MsgGlobalPositionInt, Payload: p})
+	g := m.GlobalPositionInt
+	if g.Lat != 515000000 || g.RelativeAlt != 12500 || g.Hdg != 9000 {
+		t.Errorf("global_position_int = %+v", *g)
+	}
+}
+
+func TestDecodeNavControllerOutput(t *testing.T) {
+	p := make([]byte, 26)
+	binary.LittleEndian.PutUint16(p[20:], 90)
+	binary.LittleEndian.PutUint16(p[22:], uint16(0xFFFF-44)) // -45
+	binary.LittleEndian.PutUint16(p[24:], 350)
+
+	m, _ := Decode(RawMessage{MsgID: MsgNavControllerOutput, Payload: p})
+	n := m.NavControllerOutput
+	if n.NavBearing != 90 || n.TargetBearing != -45 || n.WPDist != 350 {
+		t.Errorf("nav_controller_output = %+v", *n)
+	}
+}
+
+func TestDecodeVFRHUD(t *testing.T) {
+	p := make([]byte, 20)
+	putF32(p, 0, 14.5)
+	putF32(p, 4, 12.25)
+	putF32(p, 12, -1.5)
+	binary.LittleEndian.PutUint16(p[16:], 270)
+	binary.LittleEndian.PutUint16(p[18:], 55)
+
+	m, _ := Decode(RawMessage{MsgID: MsgVFRHUD, Payload: p})
+	v := m.VFRHUD
+	if v.Airspeed != 14.5 || v.Groundspeed != 12.25 || v.Climb != -1.5 || v.Heading != 270 || v.Throttle != 55 {
+		t.Errorf("vfr_hud = %+v", *v)
+	}
+}
+
+func TestDecodeHomePosition(t *testing.T) {
+	p := make([]byte, 52)
+	binary.LittleEndian.PutUint32(p[0:], 515000000)
+	binary.LittleEndian.PutUint32(p[8:], 30000)
+
+	m, _ := Decode(RawMessage{MsgID: MsgHomePosition, Payload: p})
+	h := m.HomePosition
+	if h.Latitude != 515000000 || h.Altitude != 30000 {
+		t.Errorf("home_position = %+v", *h)
+	}
+}
+
+func TestDecode_TruncatedV2Payload(t *testing.T) {
+	// A v2 HEARTBEAT with trailing zeros stripped down to 5 bytes.
+	p := []byte{6, 0, 0, 0, 2}
+
+	m, err := Decode(RawMessage{Version: 2, MsgID: MsgHeartbeat, Payload: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Heartbeat.CustomMode != 6 || m.Heartbeat.Type != 2 || m.Heartbeat.BaseMode != 0 {
+		t.Errorf("heartbeat = %+v", *m.Heartbeat)
+	}
+}
+
+func TestDecode_UnknownMessage(t *testing.T) {
+	if _, err := Decode(RawMessage{MsgID: 9999}); err == nil {
+		t.Error("expected error for unknown message")
+	}
+}
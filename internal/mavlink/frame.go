@@ -0,0 +1,147 @@
+package mavlink
+
+import "time"
+
+// Frame start markers.
+const (
+	STXv1 = 0xFE
+	STXv2 = 0xFD
+)
+
+// Message IDs decoded by this package.
+const (
+	MsgHeartbeat           uint32 = 0
+	MsgSysStatus           uint32 = 1
+	MsgGPSRawInt           uint32 = 24
+	MsgAttitude            uint32 = 30
+	MsgGlobalPositionInt   uint32 = 33
+	MsgNavControllerOutput uint32 = 62
+	MsgVFRHUD              uint32 = 74
+	MsgHomePosition        uint32 = 242
+)
+
+// msgInfo holds the per-message CRC seed and base (non-extension) payload
+// length from the common dialect.
+type msgInfo struct {
+	crcExtra byte
+	size     int
+}
+
+var messages = map[uint32]msgInfo{
+	MsgHeartbeat:           {50, 9},
+	MsgSysStatus:           {124, 31},
+	MsgGPSRawInt:           {24, 30},
+	MsgAttitude:            {39, 28},
+	MsgGlobalPositionInt:   {104, 28},
+	MsgNavControllerOutput: {183, 26},
+	MsgVFRHUD:              {20, 20},
+	MsgHomePosition:        {104, 52},
+}
+
+// MessageName maps each decoded message ID to its MAVLink name.
+var MessageName = map[uint32]string{
+	MsgHeartbeat:           "HEARTBEAT",
+	MsgSysStatus:           "SYS_STATUS",
+	MsgGPSRawInt:           "GPS_RAW_INT",
+	MsgAttitude:            "ATTITUDE",
+	MsgGlobalPositionInt:   "GLOBAL_POSITION_INT",
+	MsgNavControllerOutput: "NAV_CONTROLLER_OUTPUT",
+	MsgVFRHUD:              "VFR_HUD",
+	MsgHomePosition:        "HOME_POSITION",
+}
+
+// RawMessage is a checksum-validated MAVLink packet before payload decoding.
+type RawMessage struct {
+	Version byte // 1 or 2
+	Seq     uint8
+	SysID   uint8
+	CompID  uint8
+	MsgID   uint32
+	Payload []byte
+}
+
+// Heartbeat (#0).
+type Heartbeat struct {
+	CustomMode   uint32 `json:"custom_mode"`
+	Type         uint8  `json:"type"`
+	Autopilot    uint8  `json:"autopilot"`
+	BaseMode     uint8  `json:"base_mode"`
+	SystemStatus uint8  `json:"system_status"`
+}
+
+// SysStatus (#1), battery fields only.
+type SysStatus struct {
+	VoltageBattery   uint16 `json:"voltage_battery"`   // mV, UINT16_MAX = unknown
+	CurrentBattery   int16  `json:"current_battery"`   // cA, -1 = unknown
+	BatteryRemaining int8   `json:"battery_remaining"` // %, -1 = unknown
+}
+
+// GPSRawInt (#24).
+type GPSRawInt struct {
+	Lat               int32  `json:"lat"` // degE7
+	Lon               int32  `json:"lon"` // degE7
+	Alt               int32  `json:"alt"` // mm MSL
+	EPH               uint16 `json:"eph"` // HDOP * 100, UINT16_MAX = unknown
+	Vel               uint16 `json:"vel"` // cm/s, UINT16_MAX = unknown
+	COG               uint16 `json:"cog"` // cdeg
+	FixType           uint8  `json:"fix_type"`
+	SatellitesVisible uint8  `json:"satellites_visible"` // 255 = unknown
+}
+
+// Attitude (#30).
+type Attitude struct {
+	Roll  float32 `json:"roll"`  // rad
+	Pitch float32 `json:"pitch"` // rad
+	Yaw   float32 `json:"yaw"`   // rad
+}
+
+// GlobalPositionInt (#33).
+type GlobalPositionInt struct {
+	Lat         int32  `json:"lat"`          // degE7
+	Lon         int32  `json:"lon"`          // degE7
+	Alt         int32  `json:"alt"`          // mm MSL
+	RelativeAlt int32  `json:"relative_alt"` // mm above home
+	Hdg         uint16 `json:"hdg"`          // cdeg, UINT16_MAX = unknown
+}
+
+// NavControllerOutput (#62).
+type NavControllerOutput struct {
+	NavBearing    int16  `json:"nav_bearing"`    // deg
+	TargetBearing int16  `json:"target_bearing"` // deg
+	WPDist        uint16 `json:"wp_dist"`        // m
+}
+
+// VFRHUD (#74).
+type VFRHUD struct {
+	Airspeed    float32 `json:"airspeed"`    // m/s
+	Groundspeed float32 `json:"groundspeed"` // m/s
+	Alt         float32 `json:"alt"`         // m MSL
+	Climb       float32 `json:"climb"`       // m/s
+	Heading     int16   `json:"heading"`     // deg
+	Throttle    uint16  `json:"throttle"`    // %
+}
+
+// HomePosition (#242).
+type HomePosition struct {
+	Latitude  int32 `json:"latitude"`  // degE7
+	Longitude int32 `json:"longitude"` // degE7
+	Altitude  int32 `json:"altitude"`  // mm MSL
+}
+
+// Message is a decoded MAVLink message. Exactly one data pointer is non-nil.
+type Message struct {
+	MsgID  uint32    `json:"msg_id"`
+	Name   string    `json:"name"`
+	SysID  uint8     `json:"sys_id"`
+	CompID uint8     `json:"comp_id"`
+	Time   time.Time `json:"time"`
+
+	Heartbeat           *Heartbeat           `json:"heartbeat,omitempty"`
+	SysStatus           *SysStatus           `json:"sys_status,omitempty"`
+	GPSRawInt           *GPSRawInt           `json:"gps_raw_int,omitempty"`
+	Attitude            *Attitude            `json:"attitude,omitempty"`
+	GlobalPositionInt   *GlobalPositionInt   `json:"global_position_int,omitempty"`
+	NavControllerOutput *NavControllerOutput `json:"nav_controller_output,omitempty"`
+	VFRHUD              *VFRHUD              `json:"vfr_hud,omitempty"`
+	HomePosition        *HomePosition        `json:"home_position,omitempty"`
+}
@@ -0,0 +1,158 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrChecksum      = errors.New("mavlink: checksum mismatch")
+	ErrIncompatFlags = errors.New("mavlink: unsupported incompatibility flags")
+)
+
+// Header sizes, including the start marker.
+const (
+	headerV1 = 6
+	headerV2 = 10
+
+	signatureSize = 13
+	flagSigned    = 0x01
+)
+
+// Parser is a push-model MAVLink v1/v2 packet parser implementing io.Writer.
+//
+// Only messages listed in this package can be checksum-validated, because
+// the CRC is seeded with a per-message constant. Packets with other message
+// IDs are skipped whole only if a start marker follows them; otherwise, as
+// after a checksum failure, the parser resyncs on the byte following the
+// marker, so a stray 0xFE/0xFD in the stream cannot swallow the real
+// packet behind it.
+type Parser struct {
+	buf     []byte
+	Handler func(RawMessage)
+	OnError func(error)
+}
+
+// NewParser creates a Parser that calls handler for each valid packet.
+func NewParser(handler func(RawMessage), onError func(error)) *Parser {
+	return &Parser{
+		buf:     make([]byte, 0, 512),
+		Handler: handler,
+		OnError: onError,
+	}
+}
+
+// Write implements io.Writer.
+func (p *Parser) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	off := p.scan()
+	p.buf = p.buf[:copy(p.buf, p.buf[off:])]
+	return len(data), nil
+}
+
+// scan consumes complete packets from p.buf and returns the offset of the
+// first unconsumed byte.
+func (p *Parser) scan() int {
+	off := 0
+	for {
+		// Skip to the next start marker.
+		for off < len(p.buf) && p.buf[off] != STXv1 && p.buf[off] != STXv2 {
+			off++
+		}
+		b := p.buf[off:]
+
+		n, ok := packetLen(b)
+		if !ok || len(b) < n {
+			return off // need more bytes
+		}
+
+		msg, err := parsePacket(b[:n])
+		switch {
+		case err == errUnknownMsg:
+			// The length of an unknown message can't be checked, so only
+			// trust it if another packet starts right after.
+			switch {
+			case len(b) == n:
+				return off // need the next byte
+			case b[n] == STXv1 || b[n] == STXv2:
+				off += n
+			default:
+				off++
+			}
+		case err != nil:
+			if p.OnError != nil {
+				p.OnError(err)
+			}
+			off++ // resync after the bad marker
+		default:
+			off += n
+			if p.Handler != nil {
+				p.Handler(msg)
+			}
+		}
+	}
+}
+
+var errUnknownMsg = errors.New("mavlink: unknown message")
+
+// packetLen returns the full length of the packet starting at b[0], or
+// false if more header bytes are needed.
+func packetLen(b []byte) (int, bool) {
+	if len(b) < 3 {
+		return 0, false
+	}
+	plen := int(b[1])
+	if b[0] == STXv1 {
+		return headerV1 + plen + 2, true
+	}
+	n := headerV2 + plen + 2
+	if b[2]&flagSigned != 0 {
+		n += signatureSize
+	}
+	return n, true
+}
+
+// parsePacket validates one complete packet.
+func parsePacket(b []byte) (RawMessage, error) {
+	plen := int(b[1])
+
+	var m RawMessage
+	var hdr int
+	if b[0] == STXv1 {
+		hdr = headerV1
+		m = RawMessage{
+			Version: 1,
+			Seq:     b[2],
+			SysID:   b[3],
+			CompID:  b[4],
+			MsgID:   uint32(b[5]),
+		}
+	} else {
+		if b[2]&^flagSigned != 0 {
+			return m, ErrIncompatFlags
+		}
+		hdr = headerV2
+		m = RawMessage{
+			Version: 2,
+			Seq:     b[4],
+			SysID:   b[5],
+			CompID:  b[6],
+			MsgID:   uint32(b[7]) | uint32(b[8])<<8 | uint32(b[9])<<16,
+		}
+	}
+
+	info, ok := messages[m.MsgID]
+	if !ok {
+		return m, errUnknownMsg
+	}
+
+	crc := crcCalculate(b[1 : hdr+plen])
+	crc = crcAccumulate(info.crcExtra, crc)
+	if crc != binary.LittleEndian.Uint16(b[hdr+plen:]) {
+		return m, ErrChecksum
+	}
+
+	m.Payload = make([]byte, plen)
+	copy(m.Payload, b[hdr:hdr+plen])
+	return m, nil
+}
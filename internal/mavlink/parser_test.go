@@ -0,0 +1,201 @@
+package mavlink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildV1 constructs a valid MAVLink v1 packet.
+func buildV1(msgID uint8, sysID uint8, payload []byte) []byte {
+	pkt := []byte{STXv1, byte(len(payload)), 0, sysID, 1, msgID}
+	pkt = append(pkt, payload...)
+	crc := crcCalculate(pkt[1:])
+	crc = crcAccumulate(messages[uint32(msgID)].crcExtra, crc)
+	return binary.LittleEndian.AppendUint16(pkt, crc)
+}
+
+// buildV2 constructs a valid MAVLink v2 packet, truncating trailing zero
+// payload bytes as the spec requires.
+func buildV2(msgID uint32, sysID uint8, payload []byte) []byte {
+	for len(payload) > 1 && payload[len(payload)-1] == 0 {
+		payload = payload[:len(payload)-1]
+	}
+	pkt := []byte{STXv2, byte(len(payload)), 0, 0, 0, sysID, 1,
+		byte(msgID), byte(msgID >> 8), byte(msgID >> 16)}
+	pkt = append(pkt, payload...)
+	crc := crcCalculate(pkt[1:])
+	crc = crcAccumulate(messages[msgID].crcExtra, crc)
+	return binary.LittleEndian.AppendUint16(pkt, crc)
+}
+
+func TestCRC_KnownVector(t *testing.T) {
+	// CRC-16/MCRF4XX check value for "123456789".
+	if got := crcCalculate([]byte("123456789")); got != 0x6F91 {
+		t.Errorf("crc = 0x%04X, want 0x6F91", got)
+	}
+}
+
+func TestParser_V1(t *testing.T) {
+	payload := make([]byte, 9)
+	payload[6] = 0x81
+	pkt := buildV1(uint8(MsgHeartbeat), 7, payload)
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(pkt)
+
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	m := got[0]
+	if m.Version != 1 || m.MsgID != MsgHeartbeat || m.SysID != 7 {
+		t.Errorf("message = %+v", m)
+	}
+	if !bytes.Equal(m.Payload, payload) {
+		t.Errorf("payload = %x, want %x", m.Payload, payload)
+	}
+}
+
+func TestParser_V2(t *testing.T) {
+	payload := make([]byte, 52)
+	binary.LittleEndian.PutUint32(payload[0:], 515000000)
+	pkt := buildV2(MsgHomePosition, 1, payload)
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(pkt)
+
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	if got[0].Version != 2 || got[0].MsgID != MsgHomePosition {
+		t.Errorf("message = %+v", got[0])
+	}
+	if len(got[0].Payload) >= 52 {
+		t.Errorf("payload should arrive truncated, got %d bytes", len(got[0].Payload))
+	}
+}
+
+func TestParser_V2Signed(t *testing.T) {
+	pkt := buildV2(MsgAttitude, 1, make([]byte, 28))
+	pkt[2] = flagSigned
+	// Signature is not part of the CRC; recompute with the flag set.
+	plen := int(pkt[1])
+	crc := crcCalculate(pkt[1 : headerV2+plen])
+	crc = crcAccumulate(messages[MsgAttitude].crcExtra, crc)
+	binary.LittleEndian.PutUint16(pkt[headerV2+plen:], crc)
+	pkt = append(pkt, make([]byte, signatureSize)...)
+	pkt = append(pkt, buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))...)
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(pkt)
+
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got))
+	}
+	if got[1].MsgID != MsgHeartbeat {
+		t.Errorf("signature bytes were not skipped")
+	}
+}
+
+func TestParser_ByteAtATime(t *testing.T) {
+	pkt := buildV2(MsgVFRHUD, 1, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20})
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	for _, b := range pkt {
+		p.Write([]byte{b})
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+}
+
+func TestParser_ChecksumFailureAndResync(t *testing.T) {
+	bad := buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))
+	bad[len(bad)-1] ^= 0xFF
+	good := buildV1(uint8(MsgSysStatus), 1, make([]byte, 31))
+
+	var data []byte
+	data = append(data, bad...)
+	data = append(data, good...)
+
+	var frames []RawMessage
+	var errs []error
+	p := NewParser(func(m RawMessage) { frames = append(frames, m) }, func(e error) { errs = append(errs, e) })
+	p.Write(data)
+
+	if len(errs) != 1 || errs[0] != ErrChecksum {
+		t.Errorf("errors = %v, want [ErrChecksum]", errs)
+	}
+	if len(frames) != 1 || frames[0].MsgID != MsgSysStatus {
+		t.Fatalf("frames = %+v, want one SYS_STATUS", frames)
+	}
+}
+
+func TestParser_StrayMarkerBeforePacket(t *testing.T) {
+	// A stray 0xFE claiming a long payload must not swallow the real packet.
+	data := []byte{0x00, STXv1, 0x05, 0x00, 0x01, 0x01, byte(MsgHeartbeat)}
+	data = append(data, buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))...)
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(data)
+
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+}
+
+func TestParser_SkipsUnknownMessages(t *testing.T) {
+	unknown := []byte{STXv1, 2, 0, 1, 1, 200, 0xAA, 0xBB, 0x00, 0x00}
+	good := buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))
+
+	var got []RawMessage
+	var errs []error
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, func(e error) { errs = append(errs, e) })
+	p.Write(append(unknown, good...))
+
+	if len(errs) != 0 {
+		t.Errorf("errors = %v, want none for unknown message", errs)
+	}
+	if len(got) != 1 || got[0].MsgID != MsgHeartbeat {
+		t.Fatalf("got %+v, want one HEARTBEAT", got)
+	}
+}
+
+func TestParser_StrayMarkerWithUnknownID(t *testing.T) {
+	// A stray 0xFD in garbage reads as a header with an unknown message ID
+	// and a long payload; the packets it overlaps must still be found.
+	data := []byte{0x00, STXv2, 0x40, 0x00, 0x00, 0x00, 0x01, 0x01, 0xEE, 0xEE, 0xEE}
+	data = append(data, buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))...)
+	data = append(data, buildV2(MsgAttitude, 1, make([]byte, 28))...)
+	data = append(data, buildV1(uint8(MsgHeartbeat), 1, make([]byte, 9))...)
+	data = append(data, make([]byte, 64)...) // padding past the bogus length
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(data)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d messages, want 3", len(got))
+	}
+}
+
+func TestParser_Garbage(t *testing.T) {
+	garbage := []byte{0x11, 0x22, 0x33, '$', 'T', 0x44}
+	good := buildV2(MsgHeartbeat, 1, []byte{0, 0, 0, 0, 2, 3, 0x81, 4, 3})
+
+	var got []RawMessage
+	p := NewParser(func(m RawMessage) { got = append(got, m) }, nil)
+	p.Write(append(garbage, good...))
+
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	if len(p.buf) != 0 {
+		t.Errorf("parser retained %d bytes after a complete packet", len(p.buf))
+	}
+}
@@ -0,0 +1,126 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// ErrNoBaud is returned by ProbeBaud when no rate produced valid LTM frames.
+var ErrNoBaud = errors.New("serial: no baud rate produced valid LTM frames")
+
+// CommonBauds are the rates tried by ProbeBaud, fastest first.
+var CommonBauds = []int{115200, 57600, 38400, 19200, 9600, 4800, 2400, 1200}
+
+// Probe defaults.
+const (
+	DefaultProbeDwell = 1500 * time.Millisecond
+
+	// minProbeFrames is the fewest valid frames a rate needs to be chosen.
+	minProbeFrames = 3
+	// confidentFrames ends the probe early once a rate has this many valid
+	// frames and no errors.
+	confidentFrames = 20
+)
+
+// ProbeResult is the outcome of listening at one baud rate.
+type ProbeResult struct {
+	Baud   int
+	Bytes  int
+	Frames int // checksum-valid LTM frames
+	Errors int // checksum and framing errors
+}
+
+// Ratio returns the fraction of parsed frames that were valid.
+func (r ProbeResult) Ratio() float64 {
+	if r.Frames+r.Errors == 0 {
+		return 0
+	}
+	return float64(r.Frames) / float64(r.Frames+r.Errors)
+}
+
+// better reports whether r should be preferred over o. Valid frames rank
+// first: a wrong rate can produce a few clean frames by chance, but not
+// hundreds, so a perfect ratio over a handful of frames must not beat the
+// true rate with an occasional CRC error. The ratio breaks ties.
+func (r ProbeResult) better(o ProbeResult) bool {
+	if r.Frames != o.Frames {
+		return r.Frames > o.Frames
+	}
+	return r.Ratio() > o.Ratio()
+}
+
+// ProbeBaud opens the named port at each rate in bauds (CommonBauds if
+// empty), listens for dwell, and scores the rate by its valid LTM frames,
+// then by their ratio to errors. It returns the best rate along with every
+// result in probe order.
+func ProbeBaud(ctx context.Context, name string, bauds []int, dwell time.Duration) (int, []ProbeResult, error) {
+	return probeBaud(ctx, name, bauds, dwell, func(c Config) (conn, error) { return Open(c) })
+}
+
+func probeBaud(ctx context.Context, name string, bauds []int, dwell time.Duration, open func(Config) (conn, error)) (int, []ProbeResult, error) {
+	if len(bauds) == 0 {
+		bauds = CommonBauds
+	}
+	if dwell <= 0 {
+		dwell = DefaultProbeDwell
+	}
+
+	var results []ProbeResult
+	var best ProbeResult
+	for _, baud := range bauds {
+		r, err := probeOne(ctx, Config{Name: name, Baud: baud}, dwell, open)
+		if err != nil {
+			return 0, results, err
+		}
+		results = append(results, r)
+
+		if r.Frames >= minProbeFrames && r.better(best) {
+			best = r
+		}
+		if r.Frames >= confidentFrames && r.Errors == 0 {
+			break
+		}
+	}
+
+	if best.Baud == 0 {
+		return 0, results, ErrNoBaud
+	}
+	return best.Baud, results, nil
+}
+
+func probeOne(ctx context.Context, cfg Config, dwell time.Duration, open func(Config) (conn, error)) (ProbeResult, error) {
+	r := ProbeResult{Baud: cfg.Baud}
+
+	p, err := open(cfg)
+	if err != nil {
+		return r, err
+	}
+	defer p.Close()
+	p.ResetInputBuffer()
+
+	parser := ltm.NewParser(
+		func(ltm.RawFrame) { r.Frames++ },
+		func(error) { r.Errors++ },
+	)
+
+	deadline := time.Now().Add(dwell)
+	buf := make([]byte, 256)
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return r, err
+		}
+		n, err := p.Read(buf)
+		r.Bytes += n
+		parser.Write(buf[:n])
+		if err != nil {
+			return r, err
+		}
+		if r.Frames >= confidentFrames && r.Errors == 0 {
+			break
+		}
+	}
+	return r, nil
+}
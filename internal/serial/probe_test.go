@@ -0,0 +1,139 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// streamConn replays data in small chunks and then idles like a port with
+// a read timeout.
+type streamConn struct {
+	data []byte
+}
+
+func (s *streamConn) Read(buf []byte) (int, error) {
+	if len(s.data) == 0 {
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	}
+	n := copy(buf[:min(len(buf), 16)], s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
+
+func (s *streamConn) Close() error            { return nil }
+func (s *streamConn) ResetInputBuffer() error { return nil }
+
+func ltmFrames(n int) []byte {
+	var b []byte
+	for i := range n {
+		f, _ := ltm.Encode(ltm.Frame{Attitude: &ltm.AttitudeData{Heading: int16(i)}})
+		b = append(b, f...)
+	}
+	return b
+}
+
+// garbled simulates a wrong baud rate: frame-like bytes with bad checksums.
+func garbled(n int) []byte {
+	b := ltmFrames(n)
+	for i := 9; i < len(b); i += 10 {
+		b[i] ^= 0x5A
+	}
+	return b
+}
+
+func TestProbeBaud_PicksValidRate(t *testing.T) {
+	streams := map[int][]byte{
+		57600: garbled(10),
+		19200: ltmFrames(8),
+		9600:  append(garbled(3), ltmFrames(5)...),
+	}
+	var opened []int
+	open := func(c Config) (conn, error) {
+		opened = append(opened, c.Baud)
+		return &streamConn{data: streams[c.Baud]}, nil
+	}
+
+	baud, results, err := probeBaud(context.Background(), "/dev/fake", []int{57600, 19200, 9600}, 20*time.Millisecond, open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baud != 19200 {
+		t.Errorf("baud = %d, want 19200", baud)
+	}
+	if len(results) != 3 || len(opened) != 3 {
+		t.Fatalf("probed %v, want all three rates", opened)
+	}
+	if results[1].Ratio() != 1 || results[1].Frames != 8 {
+		t.Errorf("19200 result = %+v", results[1])
+	}
+}
+
+func TestProbeBaud_FramesOutrankRatio(t *testing.T) {
+	// A wrong rate with a few clean frames by chance, and the true rate
+	// with one CRC error among hundreds of frames.
+	streams := map[int][]byte{
+		115200: ltmFrames(3),
+		57600:  append(garbled(1), ltmFrames(500)...),
+	}
+	open := func(c Config) (conn, error) { return &streamConn{data: streams[c.Baud]}, nil }
+
+	baud, results, err := probeBaud(context.Background(), "/dev/fake", []int{115200, 57600}, 50*time.Millisecond, open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baud != 57600 {
+		t.Errorf("baud = %d, want 57600; results %+v", baud, results)
+	}
+	if r := results[1]; r.Frames != 500 || r.Errors == 0 {
+		t.Errorf("57600 result = %+v, want 500 frames and an error", r)
+	}
+}
+
+func TestProbeBaud_StopsEarlyWhenConfident(t *testing.T) {
+	var opened []int
+	open := func(c Config) (conn, error) {
+		opened = append(opened, c.Baud)
+		return &streamConn{data: ltmFrames(confidentFrames)}, nil
+	}
+
+	baud, _, err := probeBaud(context.Background(), "/dev/fake", []int{115200, 57600}, time.Second, open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baud != 115200 || len(opened) != 1 {
+		t.Errorf("baud = %d after probing %v, want 115200 only", baud, opened)
+	}
+}
+
+func TestProbeBaud_NoValidRate(t *testing.T) {
+	open := func(Config) (conn, error) { return &streamConn{data: garbled(5)}, nil }
+
+	_, results, err := probeBaud(context.Background(), "/dev/fake", []int{9600, 4800}, 10*time.Millisecond, open)
+	if !errors.Is(err, ErrNoBaud) {
+		t.Errorf("err = %v, want ErrNoBaud", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+}
+
+func TestProbeBaud_OpenError(t *testing.T) {
+	open := func(Config) (conn, error) { return nil, errors.New("no such device") }
+	if _, _, err := probeBaud(context.Background(), "/dev/fake", nil, time.Millisecond, open); err == nil {
+		t.Error("expected open error")
+	}
+}
+
+func TestProbeBaud_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	open := func(Config) (conn, error) { return &streamConn{}, nil }
+	if _, _, err := probeBaud(ctx, "/dev/fake", nil, time.Second, open); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
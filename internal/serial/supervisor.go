@@ -0,0 +1,192 @@
+package serial
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Supervisor.Read after Close.
+var ErrClosed = errors.New("serial: supervisor closed")
+
+// LinkState describes the health of a supervised serial link.
+type LinkState int
+
+const (
+	Connected    LinkState = iota // port open and readable
+	Reconnecting                  // device lost, retrying
+	Lost                          // still retrying, but past LostAfter attempts
+)
+
+func (s LinkState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Reconnecting:
+		return "reconnecting"
+	case Lost:
+		return "lost"
+	}
+	return "unknown"
+}
+
+// Supervisor defaults.
+const (
+	DefaultMinBackoff = 250 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+	DefaultLostAfter  = 10
+)
+
+// conn is the subset of *Port the supervisor needs; tests substitute it.
+type conn interface {
+	io.ReadCloser
+	ResetInputBuffer() error
+}
+
+// Supervisor reads from a serial port and transparently reopens it with the
+// same Config when the device disappears (e.g. a USB adapter is unplugged).
+// Read blocks while reconnecting and only fails once the Supervisor is closed.
+type Supervisor struct {
+	cfg Config
+
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	LostAfter  int // failed attempts before the state becomes Lost
+
+	// OnState is called on every state change and every failed attempt.
+	OnState func(state LinkState, retries int, err error)
+
+	open func(Config) (conn, error)
+
+	mu     sync.Mutex
+	port   conn
+	closed bool
+	done   chan struct{}
+}
+
+// NewSupervisor opens the port described by cfg. The initial open is not
+// retried so a mistyped device name still fails fast.
+func NewSupervisor(cfg Config) (*Supervisor, error) {
+	s := newSupervisor(cfg, func(c Config) (conn, error) { return Open(c) })
+	p, err := s.open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.ResetInputBuffer()
+	s.port = p
+	return s, nil
+}
+
+func newSupervisor(cfg Config, open func(Config) (conn, error)) *Supervisor {
+	return &Supervisor{
+		cfg:        cfg,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		LostAfter:  DefaultLostAfter,
+		open:       open,
+		done:       make(chan struct{}),
+	}
+}
+
+// Read implements io.Reader.
+func (s *Supervisor) Read(buf []byte) (int, error) {
+	s.mu.Lock()
+	port, closed := s.port, s.closed
+	s.mu.Unlock()
+
+	if closed {
+		return 0, ErrClosed
+	}
+	if port == nil {
+		return 0, s.reconnect(nil)
+	}
+
+	n, err := port.Read(buf)
+	if err == nil {
+		return n, nil
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return n, ErrClosed
+	}
+	s.port = nil
+	s.mu.Unlock()
+	port.Close()
+
+	return n, s.reconnect(err)
+}
+
+// reconnect retries Open with exponential backoff until it succeeds or the
+// Supervisor is closed. A nil return means the port is open again.
+func (s *Supervisor) reconnect(cause error) error {
+	s.notify(Reconnecting, 0, cause)
+
+	backoff := s.MinBackoff
+	for retries := 1; ; retries++ {
+		select {
+		case <-s.done:
+			return ErrClosed
+		case <-time.After(backoff):
+		}
+
+		p, err := s.open(s.cfg)
+		if err == nil {
+			p.ResetInputBuffer()
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				p.Close()
+				return ErrClosed
+			}
+			s.port = p
+			s.mu.Unlock()
+			s.notify(Connected, retries, nil)
+			return nil
+		}
+
+		state := Reconnecting
+		if retries >= s.LostAfter {
+			state = Lost
+		}
+		s.notify(state, retries, err)
+
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+func (s *Supervisor) notify(state LinkState, retries int, err error) {
+	if s.OnState != nil {
+		s.OnState(state, retries, err)
+	}
+}
+
+// ResetInputBuffer discards any data in the current port's input buffer.
+func (s *Supervisor) ResetInputBuffer() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port == nil {
+		return nil
+	}
+	return s.port.ResetInputBuffer()
+}
+
+// Close closes the port and stops any reconnect in progress.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	if s.port == nil {
+		return nil
+	}
+	return s.port.Close()
+}
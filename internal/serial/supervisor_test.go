@@ -0,0 +1,161 @@
+package serial
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeConn struct {
+	data   []byte
+	err    error
+	closed bool
+}
+
+func (f *fakeConn) Read(buf []byte) (int, error) {
+	if len(f.data) > 0 {
+		n := copy(buf, f.data)
+		f.data = f.data[n:]
+		return n, nil
+	}
+	return 0, f.err
+}
+
+func (f *fakeConn) Close() error            { f.closed = true; return nil }
+func (f *fakeConn) ResetInputBuffer() error { return nil }
+
+type stateEvent struct {
+	state   LinkState
+	retries int
+}
+
+func TestSupervisor_ReconnectsAfterDeviceLoss(t *testing.T) {
+	unplugged := errors.New("port has been closed")
+	first := &fakeConn{data: []byte("ab"), err: unplugged}
+	second := &fakeConn{data: []byte("cd")}
+
+	attempts := 0
+	s := newSupervisor(Config{Name: "/dev/fake", Baud: 19200}, func(Config) (conn, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("no such device")
+		}
+		return second, nil
+	})
+	s.port = first
+	s.MinBackoff = time.Millisecond
+	s.MaxBackoff = 2 * time.Millisecond
+
+	var mu sync.Mutex
+	var events []stateEvent
+	s.OnState = func(st LinkState, retries int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, stateEvent{st, retries})
+	}
+
+	buf := make([]byte, 8)
+	n, err := s.Read(buf)
+	if err != nil || string(buf[:n]) != "ab" {
+		t.Fatalf("first read = %q, %v", buf[:n], err)
+	}
+
+	// Device loss: blocks until the third open attempt succeeds.
+	if _, err := s.Read(buf); err != nil {
+		t.Fatalf("read during reconnect: %v", err)
+	}
+	if !first.closed {
+		t.Error("lost port was not closed")
+	}
+
+	n, err = s.Read(buf)
+	if err != nil || string(buf[:n]) != "cd" {
+		t.Fatalf("read after reconnect = %q, %v", buf[:n], err)
+	}
+
+	want := []stateEvent{
+		{Reconnecting, 0},
+		{Reconnecting, 1},
+		{Reconnecting, 2},
+		{Connected, 3},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event[%d] = %v, want %v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestSupervisor_ReportsLostAfterRetries(t *testing.T) {
+	s := newSupervisor(Config{Name: "/dev/fake"}, func(Config) (conn, error) {
+		return nil, errors.New("no such device")
+	})
+	s.MinBackoff = time.Millisecond
+	s.MaxBackoff = time.Millisecond
+	s.LostAfter = 2
+
+	lost := make(chan int, 1)
+	s.OnState = func(st LinkState, retries int, err error) {
+		if st == Lost {
+			select {
+			case lost <- retries:
+			default:
+			}
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Read(make([]byte, 8))
+		done <- err
+	}()
+
+	select {
+	case retries := <-lost:
+		if retries != 2 {
+			t.Errorf("lost after %d retries, want 2", retries)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("never reported Lost")
+	}
+
+	s.Close()
+	select {
+	case err := <-done:
+		if err != ErrClosed {
+			t.Errorf("err = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop reconnect")
+	}
+}
+
+func TestSupervisor_ReadAfterClose(t *testing.T) {
+	s := newSupervisor(Config{}, nil)
+	s.port = &fakeConn{}
+	s.Close()
+
+	if _, err := s.Read(make([]byte, 8)); err != ErrClosed {
+		t.Errorf("err = %v, want ErrClosed", err)
+	}
+}
+
+func TestLinkState_String(t *testing.T) {
+	tests := map[LinkState]string{
+		Connected:     "connected",
+		Reconnecting:  "reconnecting",
+		Lost:          "lost",
+		LinkState(42): "unknown",
+	}
+	for st, want := range tests {
+		if got := st.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", st, got, want)
+		}
+	}
+}
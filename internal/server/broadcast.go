@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRate is the highest telemetry message rate per client unless
+// Config.MaxRate says otherwise. Frames arriving faster are coalesced.
+const DefaultMaxRate = 30
+
+// refreshInterval is how often the time-dependent sections, freshness and
+// stats, are sent when nothing else changes.
+const refreshInterval = 250 * time.Millisecond
+
+// broadcastLoop pushes telemetry to clients as the store changes. Each
+// client gets at most one message per interval; changes in between are
+// coalesced into the next one.
+func (s *Server) broadcastLoop(ctx context.Context) {
+	refresh := time.NewTicker(refreshInterval)
+	defer refresh.Stop()
+	wake := time.NewTimer(0)
+	wake.Stop()
+	changed := s.store.Changed()
+
+	for {
+		due := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+			changed = s.store.Changed()
+		case <-refresh.C:
+			due = true
+		case <-wake.C:
+		case <-s.kick:
+		}
+		if next := s.push(time.Now(), due); !next.IsZero() {
+			wake.Reset(time.Until(next))
+		}
+	}
+}
+
+// push queues a telemetry message for every client whose selected sections
+// changed since its last one. Clients still within their interval are
+// skipped; push returns the earliest time one of them is due, or zero.
+func (s *Server) push(now time.Time, refresh bool) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.clients) == 0 {
+		return time.Time{}
+	}
+
+	var msg *Message
+	var wake time.Time
+	encoded := make(map[string][]byte)
+	for c := range s.clients {
+		if refresh {
+			c.refresh = true
+		}
+		if !c.sub.topics.telemetry() {
+			continue
+		}
+		if now.Before(c.next) {
+			if wake.IsZero() || c.next.Before(wake) {
+				wake = c.next
+			}
+			continue
+		}
+		if msg == nil {
+			m := s.buildMessage()
+			msg = &m
+		}
+
+		out, key := c.message(*msg)
+		if key == "" {
+			continue // nothing new for this client
+		}
+		data, ok := encoded[key]
+		if !ok {
+			var err error
+			if data, err = json.Marshal(out); err != nil {
+				log.Printf("ws marshal: %v", err)
+				return time.Time{}
+			}
+			encoded[key] = data
+		}
+		// A message dropped for a slow client is folded into the next delta.
+		if c.queue(outbound{data: data}) {
+			c.sent = *msg
+			c.refresh = false
+		}
+		c.next = now.Add(max(c.sub.interval, s.interval))
+	}
+	return wake
+}
+
+// message cuts the client's next message from m: the whole selection for
+// the first message or a full subscription, otherwise only the changed
+// sections. key identifies the result among clients, and is "" if nothing
+// selected has changed.
+func (c *client) message(m Message) (out Message, key string) {
+	first := c.sent.Timestamp == 0
+	var changed []string
+	for _, name := range messageTopics {
+		if c.sub.topics.has(name) && (first || c.changed(name, m)) {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return Message{}, ""
+	}
+	if first || !c.sub.delta {
+		return m.filter(c.sub.topics), "full:" + c.sub.topics.key()
+	}
+
+	sections := make(topicSet, len(changed))
+	for _, name := range changed {
+		sections[name] = true
+	}
+	m.Delta = true
+	if sections["alarms"] && len(m.Alarms) == 0 {
+		m.Cleared = []string{"alarms"}
+	}
+	return m.filter(sections), "delta:" + strings.Join(changed, ",")
+}
+
+// changed reports whether a section of m differs from the client's last
+// message. Frame sections are compared by pointer, as the store replaces
+// them on every update.
+func (c *client) changed(name string, m Message) bool {
+	last := c.sent
+	switch name {
+	case "gps":
+		return m.GPS != last.GPS
+	case "attitude":
+		return m.Attitude != last.Attitude
+	case "status":
+		return m.Status != last.Status
+	case "origin":
+		return m.Origin != last.Origin
+	case "nav":
+		return m.Nav != last.Nav
+	case "extra":
+		return m.Extra != last.Extra
+	case "crsf_link":
+		return m.CRSFLink != last.CRSFLink
+	case "derived":
+		return m.Derived != last.Derived
+	case "alarms":
+		return !slices.Equal(m.Alarms, last.Alarms)
+	case "freshness", "stats":
+		return c.refresh
+	}
+	return false
+}
+
+// queue hands o to the client's writer. It reports false if the client is
+// slow and o was dropped.
+func (c *client) queue(o outbound) bool {
+	select {
+	case c.send <- o:
+		return true
+	default:
+		return false
+	}
+}
+
+// publish sends an event to every client subscribed to it.
+func (s *Server) publish(em EventMessage) {
+	data, err := json.Marshal(em)
+	if err != nil {
+		log.Printf("ws marshal: %v", err)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for c := range s.clients {
+		if c.sub.topics.has(em.Event) {
+			c.queue(outbound{event: em.Event, data: data})
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"fpv-ground-station/internal/logbook"
+)
+
+// defaultLogbookLimit bounds logbook frame and alarm responses unless the
+// request sets limit.
+const defaultLogbookLimit = 10000
+
+// parseLogbookQuery reads the since, until, flight and limit parameters of
+// a logbook request.
+func parseLogbookQuery(v url.Values) (logbook.Query, error) {
+	q := logbook.Query{Limit: defaultLogbookLimit}
+	var err error
+	if q.Since, err = parseTime(v.Get("since")); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseTime(v.Get("until")); err != nil {
+		return q, err
+	}
+	if q.FlightID, err = parseCount("flight", v.Get("flight")); err != nil {
+		return q, err
+	}
+	if v.Has("limit") {
+		if q.Limit, err = parseCount("limit", v.Get("limit")); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// logbookRequest checks the logbook and method shared by every logbook
+// endpoint, writing the error response if either is wrong.
+func (s *Server) logbookRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.logbook == nil {
+		http.Error(w, "logbook not configured", http.StatusServiceUnavailable)
+		return false
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleLogbookFlights(w http.ResponseWriter, r *http.Request) {
+	if !s.logbookRequest(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.logbook.Flights())
+}
+
+func (s *Server) handleLogbookFlight(w http.ResponseWriter, r *http.Request) {
+	if !s.logbookRequest(w, r) {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid flight id", http.StatusBadRequest)
+		return
+	}
+	flight, err := s.logbook.Flight(id)
+	if err != nil {
+		logbookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flight)
+}
+
+// handleLogbookFrames serves stored frames in the same shape as the -json
+// console output.
+func (s *Server) handleLogbookFrames(w http.ResponseWriter, r *http.Request) {
+	if !s.logbookRequest(w, r) {
+		return
+	}
+	q, err := parseLogbookQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	frames, err := s.logbook.Frames(q)
+	if err != nil {
+		logbookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(frames)
+}
+
+// handleLogbookAlarms serves stored alarm events in the WebSocket alarm
+// payload shape.
+func (s *Server) handleLogbookAlarms(w http.ResponseWriter, r *http.Request) {
+	if !s.logbookRequest(w, r) {
+		return
+	}
+	q, err := parseLogbookQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := s.logbook.Alarms(q)
+	if err != nil {
+		logbookError(w, err)
+		return
+	}
+	out := make([]AlarmPayload, len(events))
+	for i, ev := range events {
+		out[i] = alarmPayload(ev)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func logbookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, logbook.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
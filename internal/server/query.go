@@ -0,0 +1,117 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/telemetry"
+)
+
+// parseTime parses a query time given as Unix milliseconds or RFC 3339.
+// An empty string is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: want Unix milliseconds or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// parseCount parses a non-negative integer query value; empty is 0.
+func parseCount(name, s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return n, nil
+}
+
+// parseMetres parses a non-negative distance query value; empty is 0.
+func parseMetres(name, s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m, err := strconv.ParseFloat(s, 64)
+	if err != nil || m < 0 || math.IsInf(m, 0) {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return m, nil
+}
+
+// defaultTrackMaxPoints bounds /api/track responses unless the request
+// sets max_points; larger tracks are simplified to fit.
+const defaultTrackMaxPoints = 5000
+
+// parseTrackQuery reads the since, until, limit, decimate, simplify and
+// max_points parameters of a track request. A request without since, until
+// or limit only reads the current session, so polling the live track stays
+// cheap however much history the track directory holds.
+func parseTrackQuery(v url.Values) (telemetry.TrackQuery, error) {
+	q := telemetry.TrackQuery{MaxPoints: defaultTrackMaxPoints}
+	var err error
+	if q.Since, err = parseTime(v.Get("since")); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseTime(v.Get("until")); err != nil {
+		return q, err
+	}
+	if q.Limit, err = parseCount("limit", v.Get("limit")); err != nil {
+		return q, err
+	}
+	if q.Decimate, err = parseCount("decimate", v.Get("decimate")); err != nil {
+		return q, err
+	}
+	if q.Tolerance, err = parseMetres("simplify", v.Get("simplify")); err != nil {
+		return q, err
+	}
+	if v.Has("max_points") {
+		if q.MaxPoints, err = parseCount("max_points", v.Get("max_points")); err != nil {
+			return q, err
+		}
+	}
+	q.Current = q.Since.IsZero() && q.Until.IsZero() && q.Limit == 0
+	return q, nil
+}
+
+// defaultHistoryMaxPoints bounds /api/history responses unless the request
+// sets step or max_points.
+const defaultHistoryMaxPoints = 1000
+
+// parseHistoryQuery reads the fields, since, step (seconds) and max_points
+// parameters of a history request.
+func parseHistoryQuery(v url.Values) (telemetry.HistoryQuery, error) {
+	q := telemetry.HistoryQuery{MaxPoints: defaultHistoryMaxPoints}
+	for _, name := range strings.Split(v.Get("fields"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			q.Fields = append(q.Fields, name)
+		}
+	}
+	var err error
+	if q.Since, err = parseTime(v.Get("since")); err != nil {
+		return q, err
+	}
+	step, err := parseCount("step", v.Get("step"))
+	if err != nil {
+		return q, err
+	}
+	q.Step = time.Duration(step) * time.Second
+	if v.Has("max_points") {
+		if q.MaxPoints, err = parseCount("max_points", v.Get("max_points")); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
@@ -1,14 +1,22 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/export"
+	"fpv-ground-station/internal/logbook"
 	"fpv-ground-station/internal/telemetry"
 )
 
@@ -17,6 +25,10 @@ type Config struct {
 	Store    *telemetry.Store
 	Stats    *telemetry.Stats
 	TrackLog *telemetry.TrackLog
+	Sessions *telemetry.Sessions
+	Alarms   *alarm.Engine // optional; raised alarms are included in WS messages
+	Logbook  *logbook.DB   // optional; serves /api/logbook
+	MaxRate  float64       // telemetry messages per second per client; 0 for DefaultMaxRate
 	Addr     string
 	WebFS    fs.FS // embedded or nil in dev mode
 	DevMode  bool
@@ -27,28 +39,56 @@ type Server struct {
 	store    *telemetry.Store
 	stats    *telemetry.Stats
 	trackLog *telemetry.TrackLog
+	sessions *telemetry.Sessions
+	alarms   *alarm.Engine
+	logbook  *logbook.DB
+	interval time.Duration // minimum time between telemetry messages
 	addr     string
 	webFS    fs.FS
 	devMode  bool
 
 	mu      sync.RWMutex
 	clients map[*client]struct{}
+	kick    chan struct{} // wakes the broadcast loop for a new client
 }
 
+// client is a WebSocket or SSE connection fed by the broadcast loop.
 type client struct {
-	send chan []byte
+	send chan outbound
+	sub  subscription
+
+	// Broadcast loop state.
+	next    time.Time // earliest time of the next telemetry message
+	sent    Message   // full message the last one was cut from; zero before the first
+	refresh bool      // freshness and stats are due
+}
+
+// outbound is one encoded message queued for a client: telemetry when
+// event is empty, otherwise the named event.
+type outbound struct {
+	event string
+	data  []byte
 }
 
 // New creates a new Server.
 func New(cfg Config) *Server {
+	rate := cfg.MaxRate
+	if rate <= 0 {
+		rate = DefaultMaxRate
+	}
 	return &Server{
 		store:    cfg.Store,
 		stats:    cfg.Stats,
 		trackLog: cfg.TrackLog,
+		sessions: cfg.Sessions,
+		alarms:   cfg.Alarms,
+		logbook:  cfg.Logbook,
+		interval: time.Duration(float64(time.Second) / rate),
 		addr:     cfg.Addr,
 		webFS:    cfg.WebFS,
 		devMode:  cfg.DevMode,
 		clients:  make(map[*client]struct{}),
+		kick:     make(chan struct{}, 1),
 	}
 }
 
@@ -57,7 +97,21 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/api/events", s.handleEvents)
 	mux.HandleFunc("/api/track", s.handleTrack)
+	mux.HandleFunc("/api/track.gpx", s.handleTrackExport(export.WriteGPX, "application/gpx+xml", "gpx"))
+	mux.HandleFunc("/api/track.kml", s.handleTrackExport(export.WriteKML, "application/vnd.google-earth.kml+xml", "kml"))
+	mux.HandleFunc("/api/flights", s.handleFlights)
+	mux.HandleFunc("/api/flights/{id}", s.handleFlight)
+	mux.HandleFunc("/api/summary", s.handleSummary)
+	mux.HandleFunc("/api/telemetry", s.handleTelemetry)
+	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/api/history", s.handleHistory)
+	mux.HandleFunc("/api/logbook/flights", s.handleLogbookFlights)
+	mux.HandleFunc("/api/logbook/flights/{id}", s.handleLogbookFlight)
+	mux.HandleFunc("/api/logbook/frames", s.handleLogbookFrames)
+	mux.HandleFunc("/api/logbook/alarms", s.handleLogbookAlarms)
 
 	// SPA file serving (only if webFS is available)
 	if s.webFS != nil {
@@ -118,6 +172,10 @@ func (s *Server) addClient(c *client) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.clients[c] = struct{}{}
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
 }
 
 func (s *Server) removeClient(c *client) {
@@ -127,6 +185,8 @@ func (s *Server) removeClient(c *client) {
 	close(c.send)
 }
 
+// handleTrack serves the recorded track, optionally bounded and simplified
+// by query parameters, or clears it.
 func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
 	if s.trackLog == nil {
 		http.Error(w, "track log not configured", http.StatusServiceUnavailable)
@@ -135,13 +195,30 @@ func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		points, err := s.trackLog.ReadAll()
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "coords" && format != "points" {
+			http.Error(w, "invalid format "+strconv.Quote(format), http.StatusBadRequest)
+			return
+		}
+		q, err := parseTrackQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		points, err := s.trackLog.Query(q)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(points)
+		if format == "points" {
+			if points == nil {
+				points = []telemetry.TrackPoint{}
+			}
+			json.NewEncoder(w).Encode(points)
+		} else {
+			json.NewEncoder(w).Encode(telemetry.Coords(points))
+		}
 
 	case http.MethodDelete:
 		if err := s.trackLog.Clear(); err != nil {
@@ -154,3 +231,189 @@ func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
+
+// handleTrackExport serves one flight's track as a downloadable GPX or KML
+// file, so unrelated flights are never joined into one line. The flight is
+// picked by ID with ?flight=, or by time with ?since= and ?until=; without
+// either it is the latest flight, or the current session before the first
+// arm. The flight's home position, when known, anchors the export.
+func (s *Server) handleTrackExport(write func(io.Writer, export.Track) error, contentType, ext string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.trackLog == nil {
+			http.Error(w, "track log not configured", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q, track, filename, status, err := s.trackExport(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if track.Points, err = s.trackLog.Query(q); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := write(&buf, track); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.`+ext+`"`)
+		w.Write(buf.Bytes())
+	}
+}
+
+// trackExport resolves the flight or time range an export request selects
+// into a track query, the track metadata and a file name. On error it also
+// returns the HTTP status to answer with.
+func (s *Server) trackExport(v url.Values) (telemetry.TrackQuery, export.Track, string, int, error) {
+	var q telemetry.TrackQuery
+	track := export.Track{Name: "FPV track"}
+
+	var flight telemetry.Flight
+	var ok bool
+	switch {
+	case v.Has("flight"):
+		id, err := strconv.Atoi(v.Get("flight"))
+		if err != nil {
+			return q, track, "", http.StatusBadRequest, fmt.Errorf("invalid flight %q", v.Get("flight"))
+		}
+		if s.sessions != nil {
+			flight, ok = s.sessions.Get(id)
+		}
+		if !ok {
+			return q, track, "", http.StatusNotFound, fmt.Errorf("flight %d not found", id)
+		}
+
+	case v.Has("since") || v.Has("until"):
+		var err error
+		if q.Since, err = parseTime(v.Get("since")); err != nil {
+			return q, track, "", http.StatusBadRequest, err
+		}
+		if q.Until, err = parseTime(v.Get("until")); err != nil {
+			return q, track, "", http.StatusBadRequest, err
+		}
+		if s.sessions != nil {
+			if fl, found := flightAt(s.sessions.List(), q.Since); found && fl.Home != nil {
+				track.Home = &export.Home{Lat: fl.Home.Lat, Lon: fl.Home.Lon, Alt: fl.Home.Alt}
+			}
+		}
+		return q, track, "track", 0, nil
+
+	default:
+		if s.sessions != nil {
+			flight, ok = s.sessions.Latest()
+		}
+		if !ok {
+			q.Current = true
+			return q, track, "track", 0, nil
+		}
+	}
+
+	q.Since, q.Until = flight.Start, flight.End
+	track.Name = fmt.Sprintf("Flight %d", flight.ID)
+	if flight.Home != nil {
+		track.Home = &export.Home{Lat: flight.Home.Lat, Lon: flight.Home.Lon, Alt: flight.Home.Alt}
+	}
+	return q, track, fmt.Sprintf("flight-%d", flight.ID), 0, nil
+}
+
+// flightAt returns the flight in progress at t, if any.
+func flightAt(flights []telemetry.Flight, t time.Time) (telemetry.Flight, bool) {
+	for _, f := range flights {
+		if !t.Before(f.Start) && (f.End.IsZero() || !t.After(f.End)) {
+			return f, true
+		}
+	}
+	return telemetry.Flight{}, false
+}
+
+func (s *Server) handleFlights(w http.ResponseWriter, r *http.Request) {
+	if s.sessions == nil {
+		http.Error(w, "flight sessions not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.sessions.List())
+}
+
+func (s *Server) handleFlight(w http.ResponseWriter, r *http.Request) {
+	if s.sessions == nil {
+		http.Error(w, "flight sessions not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid flight id", http.StatusBadRequest)
+		return
+	}
+	flight, ok := s.sessions.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flight)
+}
+
+// handleSummary serves the summary of the active flight, or of the most
+// recent one after landing.
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if s.sessions == nil {
+		http.Error(w, "flight sessions not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flight, ok := s.sessions.Latest()
+	if !ok {
+		http.Error(w, "no flights recorded", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flight)
+}
+
+// handleHistory serves recorded telemetry history as columnar series for
+// charting.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h, err := s.store.History(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h)
+}
@@ -1,15 +1,26 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"testing/fstest"
 	"time"
 
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/crsf"
+	"fpv-ground-station/internal/export"
+	"fpv-ground-station/internal/logbook"
 	"fpv-ground-station/internal/ltm"
 	"fpv-ground-station/internal/telemetry"
 
@@ -206,3 +217,822 @@ func TestWebSocket_MultipleClients(t *testing.T) {
 		t.Fatal("both clients should receive status data")
 	}
 }
+
+func TestEvents_SSE(t *testing.T) {
+	srv, store, _ := testServer(t)
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: time.Now(), Attitude: &ltm.AttitudeData{Heading: 270}})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/events", srv.handleEvents)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.broadcastLoop(ctx)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/events?topics=attitude,session&rate=10", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	// next returns the name and data of the next event.
+	lines := bufio.NewScanner(resp.Body)
+	next := func() (event, data string) {
+		t.Helper()
+		for lines.Scan() {
+			switch line := lines.Text(); {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && data != "":
+				return event, data
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return
+	}
+
+	event, data := next()
+	var msg Message
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if event != "" || msg.Attitude == nil || msg.Attitude.Heading != 270 || msg.Stats != nil || msg.Freshness != nil {
+		t.Errorf("telemetry event %q = %s, want attitude only", event, data)
+	}
+
+	srv.PublishAlarm(alarm.Event{Rule: "failsafe", State: alarm.Raised}) // not subscribed
+	srv.PublishSession(telemetry.Flight{ID: 1, Active: true, Track: [][2]float64{{1, 2}}})
+	for event == "" {
+		event, data = next()
+	}
+	var em EventMessage
+	json.Unmarshal([]byte(data), &em)
+	if event != "session" || em.Session == nil || em.Session.ID != 1 || em.Session.Track != nil {
+		t.Errorf("event %q = %s, want session 1 without track", event, data)
+	}
+
+	for _, query := range []string{"?topics=bogus", "?rate=0", "?rate=fast"} {
+		rec := httptest.NewRecorder()
+		srv.handleEvents(rec, httptest.NewRequest("GET", "/api/events"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/events%s status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestPush_Coalescing(t *testing.T) {
+	srv, store, _ := testServer(t)
+	fast := &client{send: make(chan outbound, 16)}
+	slow := &client{send: make(chan outbound, 16), sub: subscription{interval: 200 * time.Millisecond}}
+	quiet := &client{send: make(chan outbound, 16), sub: subscription{topics: topicSet{"alarm": true}}}
+	for _, c := range []*client{fast, slow, quiet} {
+		srv.addClient(c)
+	}
+
+	// Attitude at 100 Hz for 400 ms against the default 30 Hz maximum.
+	t0 := time.Now()
+	var wake time.Time
+	for i := range 40 {
+		now := t0.Add(time.Duration(i) * 10 * time.Millisecond)
+		store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: now, Attitude: &ltm.AttitudeData{Heading: int16(i)}})
+		wake = srv.push(now, false)
+	}
+	if len(fast.send) != 10 || len(slow.send) != 2 || len(quiet.send) != 0 {
+		t.Errorf("queued fast %d, slow %d, quiet %d; want 10, 2, 0", len(fast.send), len(slow.send), len(quiet.send))
+	}
+	if wake.IsZero() {
+		t.Error("no wake-up for clients holding coalesced changes")
+	}
+
+	srv.removeClient(fast)
+	srv.removeClient(slow)
+	srv.removeClient(quiet)
+	if !srv.push(t0.Add(time.Second), true).IsZero() {
+		t.Error("wake-up scheduled without clients")
+	}
+}
+
+func TestPush_Deltas(t *testing.T) {
+	srv, store, _ := testServer(t)
+	c := &client{send: make(chan outbound, 16), sub: subscription{delta: true}}
+	srv.addClient(c)
+	next := func() (m Message, ok bool) {
+		select {
+		case o := <-c.send:
+			if err := json.Unmarshal(o.data, &m); err != nil {
+				t.Fatal(err)
+			}
+			return m, true
+		default:
+			return m, false
+		}
+	}
+
+	t0 := time.Now()
+	store.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0, Status: &ltm.StatusData{Vbat: 16}})
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: t0, Attitude: &ltm.AttitudeData{Heading: 90}})
+	srv.push(t0, false)
+	if m, _ := next(); m.Delta || m.Status == nil || m.Attitude == nil || m.Stats == nil || m.Freshness == nil {
+		t.Errorf("first message = %+v, want full", m)
+	}
+
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: t0.Add(time.Second), Attitude: &ltm.AttitudeData{Heading: 91}})
+	srv.push(t0.Add(time.Second), false)
+	if m, _ := next(); !m.Delta || m.Attitude == nil || m.Attitude.Heading != 91 || m.Status != nil || m.Stats != nil {
+		t.Errorf("delta = %+v, want attitude only", m)
+	}
+
+	srv.push(t0.Add(2*time.Second), false)
+	if m, ok := next(); ok {
+		t.Errorf("message without changes: %+v", m)
+	}
+
+	srv.push(t0.Add(3*time.Second), true)
+	if m, _ := next(); m.Stats == nil || m.Freshness == nil || m.Attitude != nil {
+		t.Errorf("refresh = %+v, want stats and freshness only", m)
+	}
+
+	c.sent.Alarms = []AlarmPayload{{Rule: "failsafe"}}
+	if m, _ := c.message(srv.buildMessage()); !slices.Equal(m.Cleared, []string{"alarms"}) {
+		t.Errorf("cleared = %v, want alarms", m.Cleared)
+	}
+}
+
+func TestWebSocket_Subscription(t *testing.T) {
+	srv, store, _ := testServer(t)
+	now := time.Now()
+	store.Update(ltm.Frame{Function: ltm.FuncGPS, Time: now, GPS: &ltm.GPSData{Lat: 51.5, Fix: 3}})
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: now, Attitude: &ltm.AttitudeData{Heading: 90}})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", srv.handleWebSocket)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go srv.broadcastLoop(ctx)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?topics=gps", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	// reply holds whichever kind of message was read.
+	type reply struct {
+		msg Message
+		ev  EventMessage
+	}
+	read := func() (r reply) {
+		t.Helper()
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.Unmarshal(data, &r.msg)
+		json.Unmarshal(data, &r.ev)
+		return r
+	}
+	send := func(req string) {
+		t.Helper()
+		if err := conn.Write(ctx, websocket.MessageText, []byte(req)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r := read(); r.msg.GPS == nil || r.msg.Attitude != nil || r.msg.Stats != nil {
+		t.Errorf("first message = %+v, want gps only", r.msg)
+	}
+
+	send(`{"type": "subscribe", "topics": ["attitude"]}`)
+	if r := read(); r.ev.Event != "subscription" || !slices.Equal(r.ev.Subscription.Topics, []string{"gps", "attitude"}) {
+		t.Errorf("subscribe reply = %+v", r.ev)
+	}
+	if r := read(); r.msg.Delta || r.msg.GPS == nil || r.msg.Attitude == nil || r.msg.Attitude.Heading != 90 {
+		t.Errorf("after subscribe = %+v, want full gps and attitude", r.msg)
+	}
+
+	send(`{"type": "rate", "rate": 2}`)
+	if r := read(); r.ev.Subscription == nil || r.ev.Subscription.Rate != 2 {
+		t.Errorf("rate reply = %+v", r.ev)
+	}
+	send(`{"type": "rate", "rate": 1000}`)
+	if r := read(); r.ev.Subscription == nil || r.ev.Subscription.Rate != DefaultMaxRate {
+		t.Errorf("rate reply = %+v, want capped at %d", r.ev, DefaultMaxRate)
+	}
+
+	send(`{"type": "unsubscribe", "topics": ["gps"]}`)
+	if r := read(); r.ev.Subscription == nil || !slices.Equal(r.ev.Subscription.Topics, []string{"attitude"}) {
+		t.Errorf("unsubscribe reply = %+v", r.ev)
+	}
+	if r := read(); r.msg.GPS != nil || r.msg.Attitude == nil {
+		t.Errorf("after unsubscribe = %+v, want attitude only", r.msg)
+	}
+
+	send(`{"type": "snapshot"}`)
+	if r := read(); r.ev.Event != "" || r.msg.Delta || r.msg.Attitude == nil {
+		t.Errorf("snapshot = %+v", r.msg)
+	}
+
+	for _, req := range []string{`{"type": "subscribe", "topics": ["bogus"]}`, `{"type": "reboot"}`, `{"type": "unsubscribe"}`, `{"type": "rate", "rate": -1}`, `not json`} {
+		send(req)
+		if r := read(); r.ev.Event != "error" || r.ev.Error == "" {
+			t.Errorf("%s: reply = %+v, want error", req, r.ev)
+		}
+	}
+}
+
+func TestBuildMessage_LinkState(t *testing.T) {
+	srv, _, stats := testServer(t)
+
+	if msg := srv.buildMessage(); msg.Stats.Link != nil {
+		t.Fatal("link should be omitted before any state is reported")
+	}
+
+	stats.SetLink("reconnecting", 2, errors.New("no such device"))
+
+	msg := srv.buildMessage()
+	if msg.Stats.Link == nil {
+		t.Fatal("link should be present")
+	}
+	if msg.Stats.Link.State != "reconnecting" {
+		t.Errorf("state = %q, want reconnecting", msg.Stats.Link.State)
+	}
+	if msg.Stats.Link.Retries != 2 {
+		t.Errorf("retries = %d, want 2", msg.Stats.Link.Retries)
+	}
+	if msg.Stats.Link.Since == 0 {
+		t.Error("since should be set")
+	}
+}
+
+func TestBuildMessage_CRSFLink(t *testing.T) {
+	srv, store, _ := testServer(t)
+
+	if msg := srv.buildMessage(); msg.CRSFLink != nil {
+		t.Fatal("crsf_link should be omitted without CRSF input")
+	}
+
+	store.UpdateCRSFLink(&crsf.LinkStatistics{UplinkRSSI1: -70, UplinkLQ: 99, UplinkSNR: 8, UplinkTxPower: 250}, time.Now())
+
+	data, err := json.Marshal(srv.buildMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	if _, ok := raw["crsf_link"]; !ok {
+		t.Fatalf("message has no crsf_link section: %s", data)
+	}
+	if _, ok := raw["crsf_link_ts"]; !ok {
+		t.Error("message has no crsf_link_ts")
+	}
+
+	var msg Message
+	json.Unmarshal(data, &msg)
+	if msg.CRSFLink.UplinkLQ != 99 || msg.CRSFLink.UplinkTxPower != 250 {
+		t.Errorf("crsf_link = %+v", *msg.CRSFLink)
+	}
+}
+
+func TestFlightsAPI(t *testing.T) {
+	sessions := telemetry.NewSessions()
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), Sessions: sessions})
+
+	t0 := time.Now()
+	sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0, Status: &ltm.StatusData{Armed: true}})
+	sessions.Update(ltm.Frame{Function: ltm.FuncGPS, Time: t0, GPS: &ltm.GPSData{Lat: 51.5, Lon: -0.1, Fix: 3}})
+	sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0.Add(time.Minute), Status: &ltm.StatusData{}})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/flights", srv.handleFlights)
+	mux.HandleFunc("/api/flights/{id}", srv.handleFlight)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/flights", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/flights status = %d", rec.Code)
+	}
+	var flights []telemetry.Flight
+	if err := json.Unmarshal(rec.Body.Bytes(), &flights); err != nil {
+		t.Fatal(err)
+	}
+	if len(flights) != 1 || flights[0].ID != 1 || flights[0].Summary.DurationSec != 60 {
+		t.Fatalf("flights = %+v", flights)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/flights/1", nil))
+	var flight telemetry.Flight
+	json.Unmarshal(rec.Body.Bytes(), &flight)
+	if len(flight.Track) != 1 {
+		t.Errorf("flight track = %v, want one point", flight.Track)
+	}
+
+	for path, want := range map[string]int{
+		"/api/flights/2":   http.StatusNotFound,
+		"/api/flights/abc": http.StatusBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestSummaryAPI(t *testing.T) {
+	sessions := telemetry.NewSessions()
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), Sessions: sessions})
+
+	rec := httptest.NewRecorder()
+	srv.handleSummary(rec, httptest.NewRequest("GET", "/api/summary", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status before any flight = %d, want 404", rec.Code)
+	}
+
+	t0 := time.Now()
+	sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0, Status: &ltm.StatusData{Armed: true, Vbat: 16.8}})
+	sessions.Update(ltm.Frame{Function: ltm.FuncGPS, Time: t0, GPS: &ltm.GPSData{Lat: 51.5, Lon: -0.1, Altitude: 42, Fix: 3}})
+	sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0.Add(time.Minute), Status: &ltm.StatusData{Vbat: 15.2}})
+
+	rec = httptest.NewRecorder()
+	srv.handleSummary(rec, httptest.NewRequest("GET", "/api/summary", nil))
+	var flight telemetry.Flight
+	if err := json.Unmarshal(rec.Body.Bytes(), &flight); err != nil {
+		t.Fatal(err)
+	}
+	if flight.Summary.MaxAltitude != 42 || flight.Summary.MinVoltage != 15.2 || flight.Summary.DurationSec != 60 {
+		t.Errorf("summary = %+v", flight.Summary)
+	}
+}
+
+func TestBuildMessage_Derived(t *testing.T) {
+	srv, store, _ := testServer(t)
+
+	if msg := srv.buildMessage(); msg.Derived != nil {
+		t.Fatal("derived should be omitted before any GPS frame")
+	}
+
+	now := time.Now()
+	store.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: now, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Fix: 1}})
+	store.Update(ltm.Frame{Function: ltm.FuncGPS, Time: now, GPS: &ltm.GPSData{Lat: 51.501, Lon: -0.1, Altitude: 25, Fix: 3}})
+
+	msg := srv.buildMessage()
+	if msg.Derived == nil || !msg.Derived.HomeValid {
+		t.Fatalf("derived = %+v", msg.Derived)
+	}
+	if msg.Derived.DistanceToHome < 100 || msg.Derived.AltitudeAboveHome != 25 {
+		t.Errorf("derived = %+v", *msg.Derived)
+	}
+}
+
+func TestAlarms(t *testing.T) {
+	store := &telemetry.Store{}
+	engine, err := alarm.NewEngine([]alarm.Rule{{Name: "failsafe", Field: "failsafe", Op: "==", Threshold: 1, Severity: alarm.Critical}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(Config{Store: store, Stats: telemetry.NewStats(), Alarms: engine})
+
+	c := &client{send: make(chan outbound, 1)}
+	srv.addClient(c)
+
+	now := time.Now()
+	store.Update(ltm.Frame{Function: ltm.FuncStatus, Time: now, Status: &ltm.StatusData{Failsafe: true}})
+	for _, ev := range engine.Evaluate(store.Snapshot(), now) {
+		srv.PublishAlarm(ev)
+	}
+
+	var em EventMessage
+	if err := json.Unmarshal((<-c.send).data, &em); err != nil {
+		t.Fatal(err)
+	}
+	if em.Event != "alarm" || em.Alarm == nil || em.Alarm.Rule != "failsafe" || em.Alarm.State != alarm.Raised {
+		t.Errorf("event = %+v", em)
+	}
+
+	msg := srv.buildMessage()
+	if len(msg.Alarms) != 1 || msg.Alarms[0].Severity != "critical" || msg.Alarms[0].Time != now.UnixMilli() {
+		t.Errorf("alarms = %+v, want the raised failsafe alarm", msg.Alarms)
+	}
+}
+
+func TestBuildMessage_Freshness(t *testing.T) {
+	srv, store, _ := testServer(t)
+
+	if f := srv.buildMessage().Freshness; f == nil || f.Overall != telemetry.StreamNone {
+		t.Fatalf("freshness = %+v, want overall none before any frame", f)
+	}
+
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: time.Now().Add(-time.Minute), Attitude: &ltm.AttitudeData{}})
+	f := srv.buildMessage().Freshness
+	if f.Overall != telemetry.StreamLost || f.Streams["attitude"].State != telemetry.StreamLost {
+		t.Errorf("freshness = %+v, want attitude lost", f)
+	}
+}
+
+func TestStatsFromTelemetry_LinkQuality(t *testing.T) {
+	p := statsFromTelemetry(telemetry.StatsSnapshot{LinkQuality: telemetry.LinkQuality{
+		Quality:   85,
+		WindowSec: 10,
+		XReceived: 9,
+		XLost:     1,
+		Streams:   map[string]telemetry.StreamRate{"attitude": {ObservedHz: 8.5, ExpectedHz: 10}},
+	}})
+	if p.LinkQuality != 85 || p.Loss == nil {
+		t.Fatalf("stats = %+v, want link quality 85 with loss details", p)
+	}
+	if p.Loss.XLost != 1 || p.Loss.Rates["attitude"].ObservedHz != 8.5 {
+		t.Errorf("loss = %+v", *p.Loss)
+	}
+
+	if p := statsFromTelemetry(telemetry.StatsSnapshot{}); p.Loss != nil {
+		t.Error("loss should be omitted before the first full second")
+	}
+}
+
+func TestStatsFromTelemetry_Windows(t *testing.T) {
+	burstStart := time.Now()
+	p := statsFromTelemetry(telemetry.StatsSnapshot{
+		Windows: []telemetry.RateWindow{
+			{Window: 1, Seconds: 0},
+			{Window: 10, Seconds: 4, FPS: 25, BytesPerSec: 600, Frames: map[string]float64{"attitude": 10}},
+		},
+		ErrorBursts:    2,
+		LastErrorBurst: telemetry.ErrorBurst{Start: burstStart, End: burstStart.Add(time.Second), Errors: 7},
+	})
+
+	if len(p.Windows) != 1 {
+		t.Fatalf("windows = %+v, want empty windows dropped", p.Windows)
+	}
+	if w := p.Windows[0]; w.Window != 10 || w.Seconds != 4 || w.BytesPerSec != 600 || w.Frames["attitude"] != 10 {
+		t.Errorf("window = %+v", w)
+	}
+	if b := p.ErrorBursts; b == nil || b.Count != 2 || b.LastErrors != 7 || b.LastStart != burstStart.UnixMilli() {
+		t.Errorf("error bursts = %+v", b)
+	}
+}
+
+func TestTrackExportAPI(t *testing.T) {
+	trackLog, err := telemetry.NewTrackLog(filepath.Join(t.TempDir(), "track.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trackLog.Close()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	trackLog.Append(telemetry.TrackPoint{Time: t0, Lat: 51.5, Lon: -0.1, Alt: 10, Fix: 3, Sats: 12})
+	trackLog.Append(telemetry.TrackPoint{Time: t0.Add(time.Second), Lat: 51.501, Lon: -0.1, Alt: 20, Fix: 3, Sats: 12})
+
+	sessions := telemetry.NewSessions()
+	sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0, Status: &ltm.StatusData{Armed: true}})
+	sessions.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: t0, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Alt: 100, Fix: 1}})
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), TrackLog: trackLog, Sessions: sessions})
+
+	tests := []struct {
+		path, contentType, want string
+		write                   func(io.Writer, export.Track) error
+	}{
+		// GPX elevations are MSL: home altitude plus the relative altitude.
+		{"/api/track.gpx", "application/gpx+xml", "<ele>120</ele>", export.WriteGPX},
+		{"/api/track.kml", "application/vnd.google-earth.kml+xml", "<coordinates>-0.1000000,51.5000000,10.0 -0.1000000,51.5010000,20.0</coordinates>", export.WriteKML},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.handleTrackExport(tt.write, tt.contentType, "x")(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d", tt.path, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("GET %s Content-Type = %q, want %q", tt.path, got, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("GET %s body missing %q:\n%s", tt.path, tt.want, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	srv.handleTrackExport(export.WriteGPX, "application/gpx+xml", "gpx")(rec, httptest.NewRequest("POST", "/api/track.gpx", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
+
+func TestTrackExportAPI_OneFlight(t *testing.T) {
+	trackLog, err := telemetry.OpenTrackDir(t.TempDir(), telemetry.TrackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trackLog.Close()
+	sessions := telemetry.NewSessions()
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), TrackLog: trackLog, Sessions: sessions})
+
+	// Two flights from different fields, each recorded into its own session.
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, lat := range []float64{51.5, 40.0} {
+		start := t0.Add(time.Duration(i) * time.Hour)
+		trackLog.Rotate()
+		sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: start, Status: &ltm.StatusData{Armed: true}})
+		sessions.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: start, Origin: &ltm.OriginData{Lat: lat, Lon: 10, Alt: float64(100 * (i + 1)), Fix: 1}})
+		for j := range 3 {
+			trackLog.Append(telemetry.TrackPoint{Time: start.Add(time.Duration(j) * time.Second), Lat: lat + float64(j)*0.001, Lon: 10, Fix: 3})
+		}
+		sessions.Update(ltm.Frame{Function: ltm.FuncStatus, Time: start.Add(time.Minute), Status: &ltm.StatusData{}})
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handleTrackExport(export.WriteGPX, "application/gpx+xml", "gpx")(rec, httptest.NewRequest("GET", "/api/track.gpx"+query, nil))
+		return rec
+	}
+
+	tests := []struct {
+		query, lat, home, other, filename string
+	}{
+		{"", `lat="40.002"`, `<ele>200</ele>`, `lat="51.5"`, "flight-2.gpx"},
+		{"?flight=1", `lat="51.502"`, `<ele>100</ele>`, `lat="40"`, "flight-1.gpx"},
+		{"?since=2026-05-01T12:00:00Z&until=2026-05-01T12:01:00Z", `lat="51.501"`, `<ele>100</ele>`, `lat="40"`, "track.gpx"},
+	}
+	for _, tt := range tests {
+		rec := get(tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d: %s", tt.query, rec.Code, rec.Body)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, tt.lat) || !strings.Contains(body, tt.home) || strings.Contains(body, tt.other) {
+			t.Errorf("GET %s: want %s with home %s and without %s:\n%s", tt.query, tt.lat, tt.home, tt.other, body)
+		}
+		if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, tt.filename) {
+			t.Errorf("GET %s Content-Disposition = %q, want %s", tt.query, got, tt.filename)
+		}
+	}
+
+	for query, want := range map[string]int{"?flight=3": http.StatusNotFound, "?flight=x": http.StatusBadRequest, "?since=x": http.StatusBadRequest} {
+		if rec := get(query); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", query, rec.Code, want)
+		}
+	}
+}
+
+func TestTrackAPI_Query(t *testing.T) {
+	trackLog, err := telemetry.NewTrackLog(filepath.Join(t.TempDir(), "track.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trackLog.Close()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		trackLog.Append(telemetry.TrackPoint{Time: t0.Add(time.Duration(i) * time.Second), Lat: 51.5 + float64(i), Lon: -0.1})
+	}
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), TrackLog: trackLog})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handleTrack(rec, httptest.NewRequest("GET", "/api/track"+query, nil))
+		return rec
+	}
+
+	var coords [][2]float64
+	json.Unmarshal(get("").Body.Bytes(), &coords)
+	if len(coords) != 10 {
+		t.Errorf("all coords = %d, want 10", len(coords))
+	}
+
+	since := strconv.FormatInt(t0.Add(2*time.Second).UnixMilli(), 10)
+	json.Unmarshal(get("?since="+since+"&until=2026-05-01T12:00:07Z&decimate=2&limit=2").Body.Bytes(), &coords)
+	if len(coords) != 2 || coords[0][0] != 53.5 || coords[1][0] != 55.5 {
+		t.Errorf("queried coords = %v, want lat 53.5 and 55.5", coords)
+	}
+
+	var points []telemetry.TrackPoint
+	json.Unmarshal(get("?format=points&limit=1").Body.Bytes(), &points)
+	if len(points) != 1 || !points[0].Time.Equal(t0) {
+		t.Errorf("points = %+v, want the first point with its time", points)
+	}
+
+	// The points lie on a meridian, so simplification keeps only the ends.
+	for _, query := range []string{"?simplify=1", "?max_points=2"} {
+		json.Unmarshal(get(query).Body.Bytes(), &coords)
+		if len(coords) != 2 || coords[0][0] != 51.5 || coords[1][0] != 60.5 {
+			t.Errorf("GET /api/track%s = %v, want the two endpoints", query, coords)
+		}
+	}
+
+	for _, query := range []string{"?since=yesterday", "?limit=-1", "?format=csv", "?simplify=-1", "?max_points=x"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/track%s status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestTrackAPI_DefaultReadsCurrentSession(t *testing.T) {
+	dir := t.TempDir()
+	older := "# fpv-ground-station track v2\n2020-01-01T00:00:00.000Z,10.0000000,10.0000000,0.0,0.0,0,12,3,16.00\n"
+	os.WriteFile(filepath.Join(dir, "track-20200101-000000.000.csv"), []byte(older), 0o644)
+
+	trackLog, err := telemetry.OpenTrackDir(dir, telemetry.TrackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trackLog.Close()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	trackLog.Append(telemetry.TrackPoint{Time: t0, Lat: 51.5, Lon: -0.1, Fix: 3})
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), TrackLog: trackLog})
+
+	get := func(query string) [][2]float64 {
+		rec := httptest.NewRecorder()
+		srv.handleTrack(rec, httptest.NewRequest("GET", "/api/track"+query, nil))
+		var coords [][2]float64
+		json.Unmarshal(rec.Body.Bytes(), &coords)
+		return coords
+	}
+
+	if coords := get(""); len(coords) != 1 || coords[0][0] != 51.5 {
+		t.Errorf("GET /api/track = %v, want only the current session's point", coords)
+	}
+	if coords := get("?since=0"); len(coords) != 2 || coords[0][0] != 10 {
+		t.Errorf("GET /api/track?since=0 = %v, want both sessions, oldest first", coords)
+	}
+}
+
+func TestHistoryAPI(t *testing.T) {
+	srv, store, _ := testServer(t)
+	t0 := time.Unix(1_700_000_000, 0)
+	for i := range 10 {
+		at := t0.Add(time.Duration(i) * time.Second)
+		store.Update(ltm.Frame{Function: ltm.FuncStatus, Time: at, Status: &ltm.StatusData{Vbat: 16, RSSI: 90}})
+		store.Update(ltm.Frame{Function: ltm.FuncGPS, Time: at, GPS: &ltm.GPSData{Altitude: float64(i), Fix: 3}})
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handleHistory(rec, httptest.NewRequest("GET", "/api/history"+query, nil))
+		return rec
+	}
+
+	rec := get("?fields=alt,vbat&since=" + strconv.FormatInt(t0.Add(5*time.Second).UnixMilli(), 10))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var h telemetry.HistorySeries
+	if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Time) != 5 || len(h.Fields) != 2 || *h.Fields["alt"][0] != 5 || *h.Fields["vbat"][4] != 16 {
+		t.Errorf("history = %+v", h)
+	}
+
+	json.Unmarshal(get("?fields=rssi&step=5").Body.Bytes(), &h)
+	if h.StepMS != 5000 || len(h.Time) > 3 {
+		t.Errorf("step 5: step_ms = %d, %d points", h.StepMS, len(h.Time))
+	}
+
+	for _, query := range []string{"?fields=bogus", "?since=soon", "?step=-1"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /api/history%s status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	now := time.Now()
+	connected := telemetry.StatsSnapshot{Link: telemetry.LinkState{State: "connected"}}
+	tests := []struct {
+		name string
+		snap telemetry.Snapshot
+		want string
+	}{
+		{"none", telemetry.Snapshot{}, healthWaiting},
+		{"origin sent once", telemetry.Snapshot{AttitudeTime: now, StatusTime: now, OriginTime: now.Add(-time.Hour)}, healthOK},
+		{"gps dead", telemetry.Snapshot{AttitudeTime: now, StatusTime: now, GPSTime: now.Add(-time.Minute)}, healthOK},
+		{"status lost", telemetry.Snapshot{AttitudeTime: now, StatusTime: now.Add(-time.Minute)}, healthDegraded},
+		{"gps only", telemetry.Snapshot{GPSTime: now}, healthOK},
+		{"all lost", telemetry.Snapshot{AttitudeTime: now.Add(-time.Minute), GPSTime: now.Add(-time.Minute)}, healthDown},
+	}
+	for _, tt := range tests {
+		if h := health(tt.snap, connected, now); h.Status != tt.want {
+			t.Errorf("%s: status = %s, want %s", tt.name, h.Status, tt.want)
+		}
+	}
+}
+
+func TestSnapshotAPI(t *testing.T) {
+	srv, store, stats := testServer(t)
+	get := func(h http.HandlerFunc, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	var health HealthPayload
+	json.Unmarshal(get(srv.handleHealth, "/api/health").Body.Bytes(), &health)
+	if health.Status != healthWaiting || health.LastFrame != 0 {
+		t.Errorf("health before any frame = %+v", health)
+	}
+
+	now := time.Now()
+	store.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: now, Attitude: &ltm.AttitudeData{Heading: 90}})
+	stats.Count(ltm.FuncAttitude)
+	stats.Count(ltm.FuncAttitude)
+	stats.Count(ltm.FuncGPS)
+
+	var msg Message
+	rec := get(srv.handleTelemetry, "/api/telemetry")
+	if err := json.Unmarshal(rec.Body.Bytes(), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Attitude == nil || msg.Attitude.Heading != 90 || msg.Stats == nil || msg.Stats.Frames != nil {
+		t.Errorf("telemetry = %s", rec.Body)
+	}
+
+	var p StatsPayload
+	json.Unmarshal(get(srv.handleStats, "/api/stats").Body.Bytes(), &p)
+	if p.Total != 3 || p.Frames["attitude"] != 2 || p.Frames["gps"] != 1 {
+		t.Errorf("stats = %+v", p)
+	}
+
+	rec = get(srv.handleHealth, "/api/health")
+	json.Unmarshal(rec.Body.Bytes(), &health)
+	if rec.Code != http.StatusOK || health.Status != healthOK || health.LastFrame != now.UnixMilli() {
+		t.Errorf("health = %d %+v", rec.Code, health)
+	}
+
+	stats.SetLink("reconnecting", 1, errors.New("unplugged"))
+	rec = get(srv.handleHealth, "/api/health")
+	json.Unmarshal(rec.Body.Bytes(), &health)
+	if rec.Code != http.StatusServiceUnavailable || health.Status != healthDown || health.Link != "reconnecting" {
+		t.Errorf("health with link down = %d %+v", rec.Code, health)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleStats(rec, httptest.NewRequest("POST", "/api/stats", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /api/stats status = %d, want 405", rec.Code)
+	}
+}
+
+func TestLogbookAPI(t *testing.T) {
+	db, err := logbook.Open(t.TempDir(), logbook.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	db.RecordFlight(telemetry.Flight{ID: 1, Start: t0, End: t0.Add(10 * time.Second)})
+	for i := range 20 {
+		db.RecordFrame(ltm.Frame{Function: ltm.FuncGPS, Time: t0.Add(time.Duration(i) * time.Second), GPS: &ltm.GPSData{Altitude: float64(i)}})
+	}
+	db.RecordAlarm(alarm.Event{Rule: "rssi_low", State: alarm.Raised, Severity: alarm.Warning, Time: t0.Add(5 * time.Second)})
+
+	srv := New(Config{Store: &telemetry.Store{}, Stats: telemetry.NewStats(), Logbook: db})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/logbook/flights", srv.handleLogbookFlights)
+	mux.HandleFunc("/api/logbook/flights/{id}", srv.handleLogbookFlight)
+	mux.HandleFunc("/api/logbook/frames", srv.handleLogbookFrames)
+	mux.HandleFunc("/api/logbook/alarms", srv.handleLogbookAlarms)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	var flights []telemetry.Flight
+	json.Unmarshal(get("/api/logbook/flights").Body.Bytes(), &flights)
+	if len(flights) != 1 || flights[0].ID != 1 {
+		t.Errorf("flights = %+v", flights)
+	}
+
+	var frames []ltm.Frame
+	json.Unmarshal(get("/api/logbook/frames?flight=1&limit=5").Body.Bytes(), &frames)
+	if len(frames) != 5 || frames[4].GPS.Altitude != 4 {
+		t.Errorf("frames = %+v", frames)
+	}
+	json.Unmarshal(get("/api/logbook/frames?since=2026-05-01T12:00:15Z").Body.Bytes(), &frames)
+	if len(frames) != 5 {
+		t.Errorf("frames since 15 s = %d, want 5", len(frames))
+	}
+
+	var alarms []AlarmPayload
+	json.Unmarshal(get("/api/logbook/alarms?flight=1").Body.Bytes(), &alarms)
+	if len(alarms) != 1 || alarms[0].Rule != "rssi_low" || alarms[0].Time != t0.Add(5*time.Second).UnixMilli() {
+		t.Errorf("alarms = %+v", alarms)
+	}
+
+	for path, want := range map[string]int{
+		"/api/logbook/flights/2":       http.StatusNotFound,
+		"/api/logbook/frames?flight=2": http.StatusNotFound,
+		"/api/logbook/alarms?limit=x":  http.StatusBadRequest,
+		"/api/logbook/flights/abc":     http.StatusBadRequest,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseKeepAlive is how often an idle SSE stream gets a comment line, so
+// proxies don't time it out when the client only listens for events.
+const sseKeepAlive = 15 * time.Second
+
+// handleEvents streams telemetry and events as Server-Sent Events, for
+// clients that can't use the WebSocket. Telemetry messages are unnamed
+// events; alarms and sessions are named after EventMessage.Event. The
+// topics and rate query parameters select what is sent and how often.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sub, err := parseSubscription(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	c := &client{send: make(chan outbound, 16), sub: sub}
+	s.addClient(c)
+	defer s.removeClient(c)
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	ctx := r.Context()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case o := <-c.send:
+			if o.event != "" {
+				_, err = fmt.Fprintf(w, "event: %s\n", o.event)
+			}
+			if err == nil {
+				_, err = fmt.Fprintf(w, "data: %s\n\n", o.data)
+			}
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"fpv-ground-station/internal/telemetry"
+)
+
+// Health statuses reported by /api/health.
+const (
+	healthOK       = "ok"       // telemetry arriving at the expected rate
+	healthWaiting  = "waiting"  // link up, no telemetry received yet
+	healthDegraded = "degraded" // telemetry arriving, but late or with gaps
+	healthDown     = "down"     // input link lost or no telemetry arriving
+)
+
+// HealthPayload is a compact liveness report for monitoring tools.
+type HealthPayload struct {
+	Status    string                `json:"status"`
+	UptimeSec float64               `json:"uptime_sec"`
+	Link      string                `json:"link,omitempty"`     // input link state
+	Protocol  string                `json:"protocol,omitempty"` // detected or configured protocol
+	Telemetry telemetry.StreamState `json:"telemetry"`          // overall stream freshness
+	LastFrame int64                 `json:"last_frame_ts,omitempty"`
+	Clients   int                   `json:"clients"` // connected WebSocket and SSE clients
+}
+
+// handleTelemetry serves the current telemetry state, the full message a
+// new WebSocket client receives first.
+func (s *Server) handleTelemetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.buildMessage())
+}
+
+// handleStats serves connection stats, including the per-frame-type totals
+// left out of WebSocket messages.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snap := s.stats.Snapshot()
+	p := statsFromTelemetry(snap)
+	p.Frames = snap.Frames
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// handleHealth reports whether telemetry is flowing. It answers 503 when the
+// status is down so plain HTTP checks can alert on it.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	clients := len(s.clients)
+	s.mu.RUnlock()
+	h := health(s.store.Snapshot(), s.stats.Snapshot(), time.Now())
+	h.Clients = clients
+
+	w.Header().Set("Content-Type", "application/json")
+	if h.Status == healthDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
+
+// health classifies the station's state at time now. Whether telemetry is
+// still arriving at all is judged by the freshest stream, so a stream that
+// stops on its own, such as GPS, only degrades the status.
+func health(snap telemetry.Snapshot, stats telemetry.StatsSnapshot, now time.Time) HealthPayload {
+	f := snap.Freshness(now)
+	h := HealthPayload{
+		UptimeSec: stats.UptimeSec,
+		Link:      stats.Link.State,
+		Protocol:  stats.Protocol,
+		Telemetry: f.Overall,
+	}
+	best := f.Freshest()
+	for _, t := range []time.Time{snap.GPSTime, snap.AttitudeTime, snap.StatusTime, snap.OriginTime, snap.NavTime, snap.ExtraTime, snap.CRSFLinkTime} {
+		if ms := toMillis(t); ms > h.LastFrame {
+			h.LastFrame = ms
+		}
+	}
+
+	switch {
+	case h.Link != "" && h.Link != "connected":
+		h.Status = healthDown
+	case best == telemetry.StreamNone:
+		h.Status = healthWaiting
+	case best == telemetry.StreamLost:
+		h.Status = healthDown
+	case best == telemetry.StreamStale || h.Telemetry != telemetry.StreamFresh:
+		h.Status = healthDegraded
+	default:
+		h.Status = healthOK
+	}
+	return h
+}
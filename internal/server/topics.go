@@ -0,0 +1,191 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// messageTopics are the sections of a telemetry Message a client can
+// subscribe to, named after their JSON keys. Each section's timestamp
+// comes with it.
+var messageTopics = []string{
+	"gps", "attitude", "status", "origin", "nav", "extra", "crsf_link",
+	"derived", "freshness", "alarms", "stats",
+}
+
+// eventTopics are the discrete events a client can subscribe to, named
+// after EventMessage.Event.
+var eventTopics = []string{"alarm", "session"}
+
+// topicSet selects message sections and events. A nil set selects all.
+type topicSet map[string]bool
+
+// parseTopics parses a comma-separated topic list; empty selects all.
+func parseTopics(s string) (topicSet, error) {
+	return newTopicSet(strings.Split(s, ","))
+}
+
+// newTopicSet checks and collects topic names. Blank names are skipped, and
+// a list without any selects all.
+func newTopicSet(names []string) (topicSet, error) {
+	var t topicSet
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(messageTopics, name) && !slices.Contains(eventTopics, name) {
+			return nil, fmt.Errorf("unknown topic %q (want one of %s)", name, strings.Join(allTopics(), ", "))
+		}
+		if t == nil {
+			t = make(topicSet)
+		}
+		t[name] = true
+	}
+	return t, nil
+}
+
+func allTopics() []string {
+	return append(slices.Clone(messageTopics), eventTopics...)
+}
+
+// with returns t plus the topics in u. Narrowing the default of all topics
+// is the common case, so with on a nil set returns just u.
+func (t topicSet) with(u topicSet) topicSet {
+	if u == nil {
+		return nil
+	}
+	out := make(topicSet, len(t)+len(u))
+	for name := range t {
+		out[name] = true
+	}
+	for name := range u {
+		out[name] = true
+	}
+	return out
+}
+
+// without returns t minus the topics in u.
+func (t topicSet) without(u topicSet) topicSet {
+	out := make(topicSet)
+	for _, name := range allTopics() {
+		if t.has(name) && !u.has(name) {
+			out[name] = true
+		}
+	}
+	return out
+}
+
+// names lists the selected topics in a fixed order.
+func (t topicSet) names() []string {
+	names := []string{}
+	for _, name := range allTopics() {
+		if t.has(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (t topicSet) has(name string) bool {
+	return t == nil || t[name]
+}
+
+// telemetry reports whether the set selects any message section.
+func (t topicSet) telemetry() bool {
+	if t == nil {
+		return true
+	}
+	for _, name := range messageTopics {
+		if t[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// key identifies the set's message sections, so clients selecting the same
+// sections can share one encoded message. It is "" for all sections.
+func (t topicSet) key() string {
+	if t == nil {
+		return ""
+	}
+	var names []string
+	for _, name := range messageTopics {
+		if t[name] {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// subscription is what a client has asked to receive.
+type subscription struct {
+	topics   topicSet
+	interval time.Duration // minimum time between telemetry messages; 0 for the server's
+	delta    bool          // after the first message, send only changed sections
+}
+
+// parseSubscription reads the topics and rate (Hz) parameters of a
+// streaming request. Rates above the server's maximum are capped to it by
+// the broadcast loop.
+func parseSubscription(v url.Values) (subscription, error) {
+	var sub subscription
+	var err error
+	if sub.topics, err = parseTopics(v.Get("topics")); err != nil {
+		return sub, err
+	}
+	if s := v.Get("rate"); s != "" {
+		hz, err := strconv.ParseFloat(s, 64)
+		if err != nil || hz <= 0 {
+			return sub, fmt.Errorf("invalid rate %q: want a positive number of messages per second", s)
+		}
+		sub.interval = time.Duration(float64(time.Second) / hz)
+	}
+	return sub, nil
+}
+
+// filter returns the sections of m selected by t.
+func (m Message) filter(t topicSet) Message {
+	if t == nil {
+		return m
+	}
+	out := Message{Timestamp: m.Timestamp, Delta: m.Delta, Cleared: m.Cleared}
+	if t["gps"] {
+		out.GPS, out.GPSTime = m.GPS, m.GPSTime
+	}
+	if t["attitude"] {
+		out.Attitude, out.AttitudeTime = m.Attitude, m.AttitudeTime
+	}
+	if t["status"] {
+		out.Status, out.StatusTime = m.Status, m.StatusTime
+	}
+	if t["origin"] {
+		out.Origin, out.OriginTime = m.Origin, m.OriginTime
+	}
+	if t["nav"] {
+		out.Nav, out.NavTime = m.Nav, m.NavTime
+	}
+	if t["extra"] {
+		out.Extra, out.ExtraTime = m.Extra, m.ExtraTime
+	}
+	if t["crsf_link"] {
+		out.CRSFLink, out.CRSFLinkTime = m.CRSFLink, m.CRSFLinkTime
+	}
+	if t["derived"] {
+		out.Derived = m.Derived
+	}
+	if t["freshness"] {
+		out.Freshness = m.Freshness
+	}
+	if t["alarms"] {
+		out.Alarms = m.Alarms
+	}
+	if t["stats"] {
+		out.Stats = m.Stats
+	}
+	return out
+}
@@ -1,22 +1,33 @@
 package server
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"time"
 
+	"fpv-ground-station/internal/alarm"
+	"fpv-ground-station/internal/crsf"
 	"fpv-ground-station/internal/ltm"
 	"fpv-ground-station/internal/telemetry"
 
 	"nhooyr.io/websocket"
 )
 
-// Message is the JSON envelope sent to each WebSocket client.
+// Message is the JSON envelope sent to each WebSocket client. After the
+// first message on a connection, WebSocket messages are deltas holding only
+// the sections that changed; a client merges them into the state it has.
 type Message struct {
 	Timestamp int64 `json:"ts"` // Unix millis
 
+	// Delta marks a message with only the changed sections. Cleared lists
+	// sections that were emptied and should be removed ("alarms").
+	Delta   bool     `json:"delta,omitempty"`
+	Cleared []string `json:"cleared,omitempty"`
+
 	GPS          *ltm.GPSData      `json:"gps,omitempty"`
 	GPSTime      int64             `json:"gps_ts,omitempty"`
 	Attitude     *ltm.AttitudeData `json:"attitude,omitempty"`
@@ -30,19 +41,143 @@ type Message struct {
 	Extra        *ltm.ExtraData    `json:"extra,omitempty"`
 	ExtraTime    int64             `json:"extra_ts,omitempty"`
 
+	CRSFLink     *crsf.LinkStatistics `json:"crsf_link,omitempty"`
+	CRSFLinkTime int64                `json:"crsf_link_ts,omitempty"`
+
+	Derived   *telemetry.Derived   `json:"derived,omitempty"`
+	Freshness *telemetry.Freshness `json:"freshness,omitempty"`
+
+	Alarms []AlarmPayload `json:"alarms,omitempty"` // currently raised
+
 	Stats *StatsPayload `json:"stats,omitempty"`
 }
 
+// EventMessage is sent to WebSocket clients between periodic Messages when
+// a discrete event occurs. Clients tell the two apart by the event field.
+type EventMessage struct {
+	Timestamp    int64                `json:"ts"`    // Unix millis
+	Event        string               `json:"event"` // "alarm", "session", "subscription" or "error"
+	Alarm        *AlarmPayload        `json:"alarm,omitempty"`
+	Session      *telemetry.Flight    `json:"session,omitempty"` // started or ended, without track
+	Subscription *SubscriptionPayload `json:"subscription,omitempty"`
+	Error        string               `json:"error,omitempty"` // a ClientMessage was rejected
+}
+
+// ClientMessage is a request from a WebSocket client:
+//
+//	{"type": "subscribe", "topics": ["gps", "alarm"]}    add topics; from the default of all, select only these
+//	{"type": "unsubscribe", "topics": ["stats"]}         remove topics
+//	{"type": "rate", "rate": 2}                          telemetry messages per second; 0 for the server's maximum
+//	{"type": "snapshot"}                                 send the full state now
+//
+// Subscription changes are answered with a "subscription" event and
+// followed by a full message of the new selection; bad requests with an
+// "error" event.
+type ClientMessage struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+	Rate   float64  `json:"rate,omitempty"`
+}
+
+// SubscriptionPayload describes a client's current subscription.
+type SubscriptionPayload struct {
+	Topics []string `json:"topics"`
+	Rate   float64  `json:"rate"` // telemetry messages per second, at most
+}
+
+// AlarmPayload describes one alarm raise or clear. In Message.Alarms, Time
+// is when the alarm was raised and Value is the latest reading.
+type AlarmPayload struct {
+	Rule      string  `json:"rule"`
+	State     string  `json:"state"` // "raised" or "cleared"
+	Severity  string  `json:"severity"`
+	Message   string  `json:"message"`
+	Field     string  `json:"field"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Time      int64   `json:"ts"` // Unix millis
+}
+
 // StatsPayload contains connection/throughput metrics.
 type StatsPayload struct {
 	UptimeSec    float64 `json:"uptime_sec"`
 	Total        int     `json:"total"`
-	FPS          float64 `json:"fps"`
+	Bytes        int     `json:"bytes"`
+	FPS          float64 `json:"fps"`     // over the last second
+	AvgFPS       float64 `json:"avg_fps"` // since start
 	CRCErrors    int     `json:"crc_errors"`
 	DecodeErrors int     `json:"decode_errors"`
+	Protocol     string  `json:"protocol,omitempty"` // detected or configured protocol
+	LinkQuality  int     `json:"link_quality"`       // 0-100 over the loss window
+
+	// Frames counts frames since start per type, keyed by lower-case frame
+	// name. Only /api/stats fills it in, to keep WS messages small.
+	Frames map[string]int `json:"frames,omitempty"`
+
+	Windows     []WindowPayload `json:"windows,omitempty"`
+	ErrorBursts *BurstPayload   `json:"error_bursts,omitempty"`
+
+	Link *LinkPayload `json:"link,omitempty"`
+	Loss *LossPayload `json:"loss,omitempty"`
+}
+
+// WindowPayload holds rates over the last Seconds seconds, which is shorter
+// than the nominal Window until that much time has passed since start.
+type WindowPayload struct {
+	Window       int                `json:"window"`
+	Seconds      int                `json:"seconds"`
+	FPS          float64            `json:"fps"`
+	Frames       map[string]float64 `json:"frames"` // per-second, keyed by lower-case frame name
+	BytesPerSec  float64            `json:"bytes_per_sec"`
+	CRCErrors    int                `json:"crc_errors"`
+	DecodeErrors int                `json:"decode_errors"`
+}
+
+// BurstPayload reports runs of CRC/decode errors in quick succession.
+type BurstPayload struct {
+	Count      int   `json:"count"`
+	Active     bool  `json:"active"`
+	LastStart  int64 `json:"last_start_ts"` // Unix millis
+	LastEnd    int64 `json:"last_end_ts"`
+	LastErrors int   `json:"last_errors"`
+}
+
+// LossPayload details the link quality estimate over the last WindowSec seconds.
+type LossPayload struct {
+	WindowSec    int                    `json:"window_sec"`
+	XReceived    int                    `json:"x_received"`
+	XLost        int                    `json:"x_lost"`
+	CRCErrors    int                    `json:"crc_errors"`
+	CRCErrorRate float64                `json:"crc_error_rate"` // fraction of frames
+	Rates        map[string]RatePayload `json:"rates"`          // keyed by lower-case frame name
+}
+
+// RatePayload compares a frame type's observed rate with its expected rate.
+type RatePayload struct {
+	ObservedHz float64 `json:"observed_hz"`
+	ExpectedHz float64 `json:"expected_hz"`
+}
+
+// LinkPayload reports the input link state ("connected", "reconnecting", "lost").
+type LinkPayload struct {
+	State         string `json:"state"`
+	Since         int64  `json:"since_ts"` // Unix millis
+	LastConnected int64  `json:"last_connected_ts,omitempty"`
+	Retries       int    `json:"retries"`
+	LastError     string `json:"last_error,omitempty"`
 }
 
+// handleWebSocket streams telemetry and events to a WebSocket client. The
+// topics and rate query parameters set the initial subscription, which the
+// client can change with ClientMessages.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, err := parseSubscription(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sub.delta = true
+
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 		InsecureSkipVerify: true, // allow any origin (Vite dev server)
 	})
@@ -51,7 +186,7 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := &client{send: make(chan []byte, 16)}
+	c := &client{send: make(chan outbound, 16), sub: sub}
 	s.addClient(c)
 
 	ctx := r.Context()
@@ -67,7 +202,7 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				if !ok {
 					return
 				}
-				err := conn.Write(ctx, websocket.MessageText, msg)
+				err := conn.Write(ctx, websocket.MessageText, msg.data)
 				if err != nil {
 					return
 				}
@@ -77,57 +212,126 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Reader goroutine — just drain incoming messages to detect close
+	// Reader: apply client requests until the connection closes
 	for {
-		_, _, err := conn.Read(ctx)
+		_, data, err := conn.Read(ctx)
 		if err != nil {
 			break
 		}
+		s.handleClientMessage(c, data)
 	}
 }
 
-func (s *Server) broadcastLoop(ctx context.Context) {
-	ticker := time.NewTicker(50 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			msg := s.buildMessage()
-			data, err := json.Marshal(msg)
-			if err != nil {
-				log.Printf("ws marshal: %v", err)
-				continue
-			}
+// handleClientMessage applies a ClientMessage from c and answers it.
+func (s *Server) handleClientMessage(c *client, data []byte) {
+	var req ClientMessage
+	if err := json.Unmarshal(data, &req); err != nil {
+		s.reply(c, EventMessage{Event: "error", Error: "invalid message: " + err.Error()})
+		return
+	}
+	topics, err := newTopicSet(req.Topics)
+	if err != nil {
+		s.reply(c, EventMessage{Event: "error", Error: err.Error()})
+		return
+	}
 
-			s.mu.RLock()
-			for c := range s.clients {
-				select {
-				case c.send <- data:
-				default:
-					// slow client, drop frame
-				}
-			}
-			s.mu.RUnlock()
+	s.mu.Lock()
+	full := true // the next message is complete, so it covers new sections
+	switch req.Type {
+	case "subscribe":
+		c.sub.topics = c.sub.topics.with(topics)
+	case "unsubscribe":
+		if topics == nil {
+			err = errors.New("unsubscribe needs topics")
+			break
 		}
+		c.sub.topics = c.sub.topics.without(topics)
+	case "rate":
+		full = false
+		switch {
+		case req.Rate < 0:
+			err = fmt.Errorf("invalid rate %v", req.Rate)
+		case req.Rate == 0:
+			c.sub.interval = 0
+		default:
+			c.sub.interval = time.Duration(float64(time.Second) / req.Rate)
+		}
+	case "snapshot":
+	default:
+		err = fmt.Errorf("unknown message type %q (want subscribe, unsubscribe, rate or snapshot)", req.Type)
+	}
+	if err == nil {
+		if full {
+			c.sent = Message{}
+		}
+		c.next = time.Time{}
+	}
+	sub := SubscriptionPayload{
+		Topics: c.sub.topics.names(),
+		Rate:   math.Round(float64(time.Second)/float64(max(c.sub.interval, s.interval))*100) / 100,
+	}
+	s.mu.Unlock()
+
+	switch {
+	case err != nil:
+		s.reply(c, EventMessage{Event: "error", Error: err.Error()})
+		return
+	case req.Type != "snapshot":
+		s.reply(c, EventMessage{Event: "subscription", Subscription: &sub})
+	}
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// reply sends an event to c alone, unless it has disconnected.
+func (s *Server) reply(c *client, em EventMessage) {
+	em.Timestamp = time.Now().UnixMilli()
+	data, err := json.Marshal(em)
+	if err != nil {
+		log.Printf("ws marshal: %v", err)
+		return
 	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.clients[c]; ok {
+		c.queue(outbound{event: em.Event, data: data})
+	}
+}
+
+// PublishAlarm sends an alarm raise or clear event to every client.
+func (s *Server) PublishAlarm(ev alarm.Event) {
+	p := alarmPayload(ev)
+	s.publish(EventMessage{
+		Timestamp: time.Now().UnixMilli(),
+		Event:     "alarm",
+		Alarm:     &p,
+	})
+}
+
+// PublishSession sends a flight start or end event to every client. The
+// flight is sent without its track.
+func (s *Server) PublishSession(f telemetry.Flight) {
+	f.Track = nil
+	s.publish(EventMessage{
+		Timestamp: time.Now().UnixMilli(),
+		Event:     "session",
+		Session:   &f,
+	})
 }
 
 func (s *Server) buildMessage() Message {
 	snap := s.store.Snapshot()
 	statsSnap := s.stats.Snapshot()
 
+	now := time.Now()
+	stats := statsFromTelemetry(statsSnap)
+	freshness := snap.Freshness(now)
 	msg := Message{
-		Timestamp: time.Now().UnixMilli(),
-		Stats: &StatsPayload{
-			UptimeSec:    statsSnap.UptimeSec,
-			Total:        statsSnap.Total,
-			FPS:          statsSnap.FPS,
-			CRCErrors:    statsSnap.CRCErrors,
-			DecodeErrors: statsSnap.DecodeErrors,
-		},
+		Timestamp: now.UnixMilli(),
+		Freshness: &freshness,
+		Stats:     &stats,
 	}
 
 	if snap.GPS != nil {
@@ -154,10 +358,34 @@ func (s *Server) buildMessage() Message {
 		msg.Extra = snap.Extra
 		msg.ExtraTime = toMillis(snap.ExtraTime)
 	}
+	if snap.CRSFLink != nil {
+		msg.CRSFLink = snap.CRSFLink
+		msg.CRSFLinkTime = toMillis(snap.CRSFLinkTime)
+	}
+	msg.Derived = snap.Derived
+
+	if s.alarms != nil {
+		for _, ev := range s.alarms.Active() {
+			msg.Alarms = append(msg.Alarms, alarmPayload(ev))
+		}
+	}
 
 	return msg
 }
 
+func alarmPayload(ev alarm.Event) AlarmPayload {
+	return AlarmPayload{
+		Rule:      ev.Rule,
+		State:     ev.State,
+		Severity:  string(ev.Severity),
+		Message:   ev.Message,
+		Field:     ev.Field,
+		Value:     ev.Value,
+		Threshold: ev.Threshold,
+		Time:      toMillis(ev.Time),
+	}
+}
+
 func toMillis(t time.Time) int64 {
 	if t.IsZero() {
 		return 0
@@ -167,11 +395,61 @@ func toMillis(t time.Time) int64 {
 
 // statsFromTelemetry converts a telemetry StatsSnapshot to a StatsPayload.
 func statsFromTelemetry(snap telemetry.StatsSnapshot) StatsPayload {
-	return StatsPayload{
+	p := StatsPayload{
 		UptimeSec:    snap.UptimeSec,
 		Total:        snap.Total,
+		Bytes:        snap.Bytes,
 		FPS:          snap.FPS,
+		AvgFPS:       snap.AvgFPS,
 		CRCErrors:    snap.CRCErrors,
 		DecodeErrors: snap.DecodeErrors,
+		Protocol:     snap.Protocol,
+		LinkQuality:  snap.LinkQuality.Quality,
+	}
+	for _, w := range snap.Windows {
+		if w.Seconds == 0 {
+			continue
+		}
+		p.Windows = append(p.Windows, WindowPayload{
+			Window:       w.Window,
+			Seconds:      w.Seconds,
+			FPS:          w.FPS,
+			Frames:       w.Frames,
+			BytesPerSec:  w.BytesPerSec,
+			CRCErrors:    w.CRCErrors,
+			DecodeErrors: w.DecodeErrors,
+		})
+	}
+	if snap.ErrorBursts > 0 {
+		p.ErrorBursts = &BurstPayload{
+			Count:      snap.ErrorBursts,
+			Active:     snap.InErrorBurst,
+			LastStart:  toMillis(snap.LastErrorBurst.Start),
+			LastEnd:    toMillis(snap.LastErrorBurst.End),
+			LastErrors: snap.LastErrorBurst.Errors,
+		}
+	}
+	if lq := snap.LinkQuality; lq.WindowSec > 0 {
+		p.Loss = &LossPayload{
+			WindowSec:    lq.WindowSec,
+			XReceived:    lq.XReceived,
+			XLost:        lq.XLost,
+			CRCErrors:    lq.CRCErrors,
+			CRCErrorRate: lq.CRCErrorRate,
+			Rates:        make(map[string]RatePayload, len(lq.Streams)),
+		}
+		for name, r := range lq.Streams {
+			p.Loss.Rates[name] = RatePayload{ObservedHz: r.ObservedHz, ExpectedHz: r.ExpectedHz}
+		}
+	}
+	if snap.Link.State != "" {
+		p.Link = &LinkPayload{
+			State:         snap.Link.State,
+			Since:         toMillis(snap.Link.Since),
+			LastConnected: toMillis(snap.Link.LastConnected),
+			Retries:       snap.Link.Retries,
+			LastError:     snap.Link.LastError,
+		}
 	}
+	return p
 }
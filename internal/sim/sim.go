@@ -0,0 +1,394 @@
+// Package sim generates a scripted synthetic flight as a stream of LTM
+// frames, for bench-testing the ground station and OSDs without a vehicle.
+//
+// The script is deterministic: idle on the ground while GPS acquires, arm
+// and take off, fly out to an orbit, circle it twice, suffer a short link
+// failsafe that triggers RTH, fly home, land and disarm. Battery voltage sags
+// with current draw and decays with consumed capacity throughout.
+package sim
+
+import (
+	"context"
+	"io"
+	"math"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// Tick is the simulation step. Attitude is sent every tick (10 Hz).
+const Tick = 100 * time.Millisecond
+
+// Default home position, used when Config leaves it zero.
+const (
+	DefaultHomeLat = 51.5
+	DefaultHomeLon = -0.1278
+)
+
+// Flight script parameters.
+const (
+	cruiseAlt     = 60.0  // m above home
+	climbRate     = 3.0   // m/s
+	descentRate   = 2.0   // m/s
+	cruiseSpeed   = 12.0  // m/s
+	orbitSpeed    = 10.0  // m/s
+	orbitRadius   = 80.0  // m
+	orbitDistance = 400.0 // m from home to orbit centre
+	orbitBearing  = 60.0  // degrees from home to orbit centre
+	orbitLaps     = 2
+
+	packCapacity = 1500.0 // mAh
+	packFull     = 16.8   // V, 4S
+	packEmpty    = 14.0   // V at full capacity drawn
+	packIR       = 0.02   // ohm, voltage sag per amp
+
+	disarmReasonLanding = 8
+)
+
+// INAV flight modes used by the script (see ltm.FlightModeName).
+const (
+	modeAngle     = 2
+	modeWaypoints = 10
+	modeCircle    = 12
+	modeRTH       = 13
+	modeLand      = 15
+)
+
+// Config configures a Simulator.
+type Config struct {
+	HomeLat float64
+	HomeLon float64
+	HomeAlt float64 // m MSL
+	Loop    bool    // restart the script after landing
+}
+
+// Simulator steps through the flight script one Tick at a time.
+type Simulator struct {
+	cfg    Config
+	phases []phase
+	total  time.Duration
+
+	tick     int
+	mah      float64
+	xCounter uint8
+}
+
+type phase struct {
+	name  string
+	start time.Duration
+	dur   time.Duration
+	state func(t float64) vehicle // t = seconds into the phase
+}
+
+// vehicle is the simulated state at one instant, relative to home.
+type vehicle struct {
+	north, east, alt float64 // m
+	speed            float64 // m/s over ground
+	course           float64 // degrees
+	roll, pitch      float64 // degrees
+	current          float64 // A
+
+	armed, failsafe bool
+	mode            uint8
+	gpsMode         uint8
+	navMode         uint8
+	navAction       uint8
+	linkOK          bool
+	disarmReason    uint8
+}
+
+// New builds a Simulator for cfg.
+func New(cfg Config) *Simulator {
+	if cfg.HomeLat == 0 && cfg.HomeLon == 0 {
+		cfg.HomeLat, cfg.HomeLon = DefaultHomeLat, DefaultHomeLon
+	}
+	s := &Simulator{cfg: cfg}
+	s.buildScript()
+	return s
+}
+
+// Duration returns the length of one pass through the script.
+func (s *Simulator) Duration() time.Duration {
+	return s.total
+}
+
+func (s *Simulator) buildScript() {
+	outbound := orbitDistance - orbitRadius
+	lap := 2 * math.Pi * orbitRadius / orbitSpeed
+	// Orbit starts on the near side of the circle, facing home.
+	orbitStart := orbitBearing + 180
+
+	add := func(name string, sec float64, state func(t float64) vehicle) {
+		d := time.Duration(sec * float64(time.Second))
+		s.phases = append(s.phases, phase{name: name, start: s.total, dur: d, state: state})
+		s.total += d
+	}
+
+	add("idle", 5, func(t float64) vehicle {
+		return vehicle{current: 0.5, mode: modeAngle, linkOK: true}
+	})
+
+	add("takeoff", cruiseAlt/climbRate, func(t float64) vehicle {
+		return vehicle{
+			alt:     climbRate * t,
+			current: 18,
+			armed:   true,
+			mode:    modeAngle,
+			linkOK:  true,
+		}
+	})
+
+	add("outbound", outbound/cruiseSpeed, func(t float64) vehicle {
+		n, e := polar(orbitBearing, cruiseSpeed*t)
+		return vehicle{
+			north: n, east: e, alt: cruiseAlt,
+			speed: cruiseSpeed, course: orbitBearing, pitch: -8,
+			current: 14, armed: true,
+			mode: modeWaypoints, gpsMode: 3, navMode: 5, navAction: 1,
+			linkOK: true,
+		}
+	})
+
+	add("orbit", lap*orbitLaps, func(t float64) vehicle {
+		cn, ce := polar(orbitBearing, orbitDistance)
+		angle := orbitStart + 360*t/lap
+		n, e := polar(angle, orbitRadius)
+		return vehicle{
+			north: cn + n, east: ce + e, alt: cruiseAlt,
+			speed: orbitSpeed, course: math.Mod(angle+90, 360),
+			roll: 15, pitch: -6,
+			current: 13, armed: true,
+			mode: modeCircle, gpsMode: 1, navMode: 3,
+			linkOK: true,
+		}
+	})
+
+	add("failsafe", 6, func(t float64) vehicle {
+		n, e := polar(orbitBearing, outbound)
+		return vehicle{
+			north: n, east: e, alt: cruiseAlt,
+			course:  orbitBearing + 180,
+			current: 10, armed: true, failsafe: true,
+			mode: modeRTH, gpsMode: 2, navMode: 1, navAction: 4,
+		}
+	})
+
+	add("rth", outbound/cruiseSpeed, func(t float64) vehicle {
+		n, e := polar(orbitBearing, outbound-cruiseSpeed*t)
+		return vehicle{
+			north: n, east: e, alt: cruiseAlt,
+			speed: cruiseSpeed, course: orbitBearing + 180, pitch: -8,
+			current: 14, armed: true,
+			mode: modeRTH, gpsMode: 2, navMode: 2, navAction: 4,
+			linkOK: true,
+		}
+	})
+
+	add("land", cruiseAlt/descentRate, func(t float64) vehicle {
+		return vehicle{
+			alt:     cruiseAlt - descentRate*t,
+			course:  orbitBearing + 180,
+			current: 7, armed: true,
+			mode: modeLand, gpsMode: 2, navMode: 9, navAction: 8,
+			linkOK: true,
+		}
+	})
+
+	add("landed", 5, func(t float64) vehicle {
+		return vehicle{
+			course:       orbitBearing + 180,
+			current:      0.5,
+			mode:         modeAngle,
+			navMode:      10,
+			linkOK:       true,
+			disarmReason: disarmReasonLanding,
+		}
+	})
+}
+
+// Elapsed returns the simulated time of the next tick.
+func (s *Simulator) Elapsed() time.Duration {
+	return time.Duration(s.tick) * Tick
+}
+
+// Next advances one Tick and returns the frames due in it. It returns false
+// once the script has finished and Config.Loop is not set.
+func (s *Simulator) Next() ([]ltm.Frame, bool) {
+	t := s.Elapsed()
+	if t >= s.total {
+		if !s.cfg.Loop {
+			return nil, false
+		}
+		s.tick, s.mah, t = 0, 0, 0
+	}
+
+	v := s.stateAt(t)
+	s.mah += v.current * Tick.Hours() * 1000
+
+	frames := []ltm.Frame{{Function: ltm.FuncAttitude, Attitude: s.attitude(v)}}
+	if s.tick%2 == 0 {
+		frames = append(frames,
+			ltm.Frame{Function: ltm.FuncGPS, GPS: s.gps(v, t)},
+			ltm.Frame{Function: ltm.FuncStatus, Status: s.status(v)},
+		)
+	}
+	if s.tick%3 == 0 {
+		frames = append(frames, ltm.Frame{Function: ltm.FuncNav, Nav: &ltm.NavData{
+			GPSMode:   v.gpsMode,
+			NavMode:   v.navMode,
+			NavAction: v.navAction,
+		}})
+	}
+	if s.tick%10 == 0 {
+		frames = append(frames,
+			ltm.Frame{Function: ltm.FuncOrigin, Origin: s.origin(t)},
+			ltm.Frame{Function: ltm.FuncExtra, Extra: s.extra(v)},
+		)
+	}
+
+	s.tick++
+	return frames, true
+}
+
+func (s *Simulator) stateAt(t time.Duration) vehicle {
+	for _, p := range s.phases {
+		if t < p.start+p.dur {
+			return p.state((t - p.start).Seconds())
+		}
+	}
+	last := s.phases[len(s.phases)-1]
+	return last.state(last.dur.Seconds())
+}
+
+func (s *Simulator) attitude(v vehicle) *ltm.AttitudeData {
+	// A little deterministic wobble so the horizon is visibly alive.
+	wobble := math.Sin(float64(s.tick) / 7)
+	if !v.armed {
+		wobble = 0
+	}
+	return &ltm.AttitudeData{
+		Pitch:   int16(math.Round(v.pitch + wobble)),
+		Roll:    int16(math.Round(v.roll + 2*wobble)),
+		Heading: int16(math.Round(math.Mod(v.course+360, 360))),
+	}
+}
+
+func (s *Simulator) gps(v vehicle, t time.Duration) *ltm.GPSData {
+	lat, lon := s.offset(v.north, v.east)
+	sats := uint8(14)
+	if acq := t.Seconds() * 3; acq < float64(sats) {
+		sats = uint8(acq)
+	}
+	fix := uint8(0)
+	if sats >= 6 {
+		fix = 3
+	}
+	return &ltm.GPSData{
+		Lat:         lat,
+		Lon:         lon,
+		GroundSpeed: uint8(math.Round(v.speed)),
+		Altitude:    v.alt, // LTM GPS altitude is relative to home
+		Fix:         fix,
+		Sats:        sats,
+	}
+}
+
+func (s *Simulator) status(v vehicle) *ltm.StatusData {
+	rest := packFull - (packFull-packEmpty)*math.Min(s.mah/packCapacity, 1)
+
+	rssi := uint8(0)
+	if v.linkOK {
+		dist := math.Hypot(v.north, v.east)
+		rssi = uint8(math.Max(40, 254-dist/4))
+	}
+
+	return &ltm.StatusData{
+		Vbat:       math.Round((rest-v.current*packIR)*100) / 100,
+		MAhDrawn:   uint16(s.mah),
+		RSSI:       rssi,
+		Airspeed:   uint8(math.Round(v.speed)),
+		Armed:      v.armed,
+		Failsafe:   v.failsafe,
+		FlightMode: v.mode,
+	}
+}
+
+func (s *Simulator) origin(t time.Duration) *ltm.OriginData {
+	// Home is only valid once the vehicle has armed.
+	if t < s.phases[1].start {
+		return &ltm.OriginData{}
+	}
+	return &ltm.OriginData{
+		Lat:   s.cfg.HomeLat,
+		Lon:   s.cfg.HomeLon,
+		Alt:   s.cfg.HomeAlt,
+		OSDOn: true,
+		Fix:   3,
+	}
+}
+
+func (s *Simulator) extra(v vehicle) *ltm.ExtraData {
+	s.xCounter++
+	return &ltm.ExtraData{
+		HDOP:         0.9,
+		XCounter:     s.xCounter,
+		DisarmReason: v.disarmReason,
+	}
+}
+
+// offset converts a north/east displacement in metres from home to
+// latitude/longitude using a local flat-earth approximation.
+func (s *Simulator) offset(north, east float64) (lat, lon float64) {
+	const mPerDeg = 111320.0
+	lat = s.cfg.HomeLat + north/mPerDeg
+	lon = s.cfg.HomeLon + east/(mPerDeg*math.Cos(s.cfg.HomeLat*math.Pi/180))
+	return lat, lon
+}
+
+// polar returns the north/east components of dist metres along bearing.
+func polar(bearing, dist float64) (north, east float64) {
+	rad := bearing * math.Pi / 180
+	return dist * math.Cos(rad), dist * math.Sin(rad)
+}
+
+// Run writes the encoded flight to w, one Write per tick, pacing ticks at
+// Tick/speed of wall time. Speed <= 0 writes as fast as possible. It returns
+// nil when the script ends, or the context or write error.
+func (s *Simulator) Run(ctx context.Context, w io.Writer, speed float64) error {
+	var ticker *time.Ticker
+	if speed > 0 {
+		ticker = time.NewTicker(time.Duration(float64(Tick) / speed))
+		defer ticker.Stop()
+	}
+
+	for {
+		frames, ok := s.Next()
+		if !ok {
+			return nil
+		}
+
+		var buf []byte
+		for _, f := range frames {
+			data, err := ltm.Encode(f)
+			if err != nil {
+				return err
+			}
+			buf = append(buf, data...)
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+
+		if ticker == nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func collect(t *testing.T, s *Simulator) []ltm.Frame {
+	t.Helper()
+	var frames []ltm.Frame
+	for {
+		fs, ok := s.Next()
+		if !ok {
+			return frames
+		}
+		frames = append(frames, fs...)
+	}
+}
+
+func TestSimulator_FlightScript(t *testing.T) {
+	s := New(Config{})
+	frames := collect(t, s)
+
+	var (
+		armedSeen, failsafeSeen bool
+		maxAlt                  float64
+		firstVbat, minVbat      = 0.0, math.MaxFloat64
+		last                    *ltm.StatusData
+		lastGPS                 *ltm.GPSData
+		attitude                int
+	)
+	for _, f := range frames {
+		switch {
+		case f.Attitude != nil:
+			attitude++
+		case f.Status != nil:
+			if firstVbat == 0 {
+				firstVbat = f.Status.Vbat
+			}
+			minVbat = math.Min(minVbat, f.Status.Vbat)
+			armedSeen = armedSeen || f.Status.Armed
+			failsafeSeen = failsafeSeen || f.Status.Failsafe
+			last = f.Status
+		case f.GPS != nil:
+			maxAlt = math.Max(maxAlt, f.GPS.Altitude)
+			lastGPS = f.GPS
+		}
+	}
+
+	wantTicks := int(s.Duration() / Tick)
+	if attitude < wantTicks-1 || attitude > wantTicks+1 {
+		t.Errorf("attitude frames = %d, want ~%d (10 Hz)", attitude, wantTicks)
+	}
+	if !armedSeen {
+		t.Error("vehicle never armed")
+	}
+	if !failsafeSeen {
+		t.Error("script should include a failsafe event")
+	}
+	if last.Armed {
+		t.Error("vehicle should be disarmed after landing")
+	}
+	if math.Abs(maxAlt-cruiseAlt) > 1 {
+		t.Errorf("max altitude = %.1f, want ~%.0f", maxAlt, cruiseAlt)
+	}
+	if lastGPS.Altitude > 0.5 {
+		t.Errorf("final altitude = %.1f, want on the ground", lastGPS.Altitude)
+	}
+	if math.Abs(lastGPS.Lat-DefaultHomeLat) > 1e-5 || math.Abs(lastGPS.Lon-DefaultHomeLon) > 1e-5 {
+		t.Errorf("landed at %f,%f, want home", lastGPS.Lat, lastGPS.Lon)
+	}
+	if minVbat >= firstVbat-0.5 {
+		t.Errorf("battery did not sag: first %.2fV min %.2fV", firstVbat, minVbat)
+	}
+	if last.MAhDrawn == 0 {
+		t.Error("mAh drawn should accumulate")
+	}
+}
+
+func TestSimulator_Loop(t *testing.T) {
+	s := New(Config{Loop: true})
+	ticks := int(s.Duration()/Tick) + 10
+	for i := 0; i < ticks; i++ {
+		if _, ok := s.Next(); !ok {
+			t.Fatalf("looping simulator stopped at tick %d", i)
+		}
+	}
+}
+
+func TestSimulator_RunProducesValidLTM(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(Config{HomeLat: 40, HomeLon: 29, HomeAlt: 100})
+	if err := s.Run(context.Background(), &buf, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var n, errs int
+	p := ltm.NewParser(func(raw ltm.RawFrame) {
+		if _, err := ltm.Decode(raw); err != nil {
+			errs++
+		}
+		n++
+	}, func(error) { errs++ })
+	p.Write(buf.Bytes())
+
+	if errs != 0 {
+		t.Errorf("%d parse/decode errors", errs)
+	}
+	if n < int(s.Duration()/Tick) {
+		t.Errorf("parsed %d frames, want at least one per tick", n)
+	}
+}
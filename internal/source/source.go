@@ -0,0 +1,305 @@
+// Package source opens telemetry byte streams from URI-style addresses so
+// the parsing pipeline does not care whether bytes arrive over a local
+// serial port, the network, a file or stdin.
+//
+// Supported forms:
+//
+//	/dev/ttyUSB0, COM3          serial port (also serial:///dev/ttyUSB0)
+//	tcp://192.168.4.1:5760      TCP client, redialled when the connection drops
+//	tcp-listen://:5760          TCP server, one client at a time
+//	udp://:14550                UDP socket bound to the given address
+//	file:///path/to/dump.bin    raw bytes from a file
+//	stdin, -                    raw bytes from standard input
+package source
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"fpv-ground-station/internal/serial"
+)
+
+// Config selects and configures an input source.
+type Config struct {
+	URI  string
+	Baud int // serial only
+
+	// OnLinkState receives reconnect progress for serial and TCP client
+	// sources, which are reopened automatically when the link drops.
+	OnLinkState func(state serial.LinkState, retries int, err error)
+}
+
+// Scheme returns the URI scheme, or "serial" for a bare device path.
+func (c Config) Scheme() string {
+	if c.URI == "stdin" || c.URI == "-" {
+		return "stdin"
+	}
+	scheme, _, ok := strings.Cut(c.URI, "://")
+	if !ok {
+		return "serial"
+	}
+	return strings.ToLower(scheme)
+}
+
+// Addr returns the URI with its scheme stripped.
+func (c Config) Addr() string {
+	if _, addr, ok := strings.Cut(c.URI, "://"); ok {
+		return addr
+	}
+	return c.URI
+}
+
+// String describes the source for log lines.
+func (c Config) String() string {
+	if c.Scheme() == "serial" {
+		return fmt.Sprintf("%s @ %d baud", c.Addr(), c.Baud)
+	}
+	return c.URI
+}
+
+// Open opens the source described by cfg.
+func Open(cfg Config) (io.ReadCloser, error) {
+	addr := cfg.Addr()
+
+	switch cfg.Scheme() {
+	case "serial":
+		sup, err := serial.NewSupervisor(serial.Config{Name: addr, Baud: cfg.Baud})
+		if err != nil {
+			return nil, err
+		}
+		sup.OnState = cfg.OnLinkState
+		return sup, nil
+
+	case "tcp":
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("dial %s: %w", addr, err)
+		}
+		c := newTCPClient(addr, conn)
+		c.onState = cfg.OnLinkState
+		return c, nil
+
+	case "tcp-listen":
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen %s: %w", addr, err)
+		}
+		return &tcpListener{ln: ln}, nil
+
+	case "udp":
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen udp %s: %w", addr, err)
+		}
+		return &udpSource{conn: pc, buf: make([]byte, 65535)}, nil
+
+	case "file":
+		f, err := os.Open(addr)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+
+	case "stdin":
+		return os.Stdin, nil
+
+	default:
+		return nil, fmt.Errorf("source: unsupported scheme %q", cfg.Scheme())
+	}
+}
+
+// tcpClient reads from a TCP server and redials it with exponential backoff
+// when the connection drops, mirroring serial.Supervisor. The initial dial is
+// not retried so a wrong address still fails fast. Read blocks while
+// redialling and only fails once the client is closed.
+type tcpClient struct {
+	addr       string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	lostAfter  int
+	onState    func(state serial.LinkState, retries int, err error)
+	dial       func(addr string) (net.Conn, error)
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+	done   chan struct{}
+}
+
+func newTCPClient(addr string, conn net.Conn) *tcpClient {
+	return &tcpClient{
+		addr:       addr,
+		minBackoff: serial.DefaultMinBackoff,
+		maxBackoff: serial.DefaultMaxBackoff,
+		lostAfter:  serial.DefaultLostAfter,
+		dial:       func(addr string) (net.Conn, error) { return net.Dial("tcp", addr) },
+		conn:       conn,
+		done:       make(chan struct{}),
+	}
+}
+
+func (t *tcpClient) Read(buf []byte) (int, error) {
+	t.mu.Lock()
+	conn, closed := t.conn, t.closed
+	t.mu.Unlock()
+
+	if closed {
+		return 0, serial.ErrClosed
+	}
+	if conn == nil {
+		return 0, t.redial(nil)
+	}
+
+	n, err := conn.Read(buf)
+	if err == nil {
+		return n, nil
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return n, serial.ErrClosed
+	}
+	t.conn = nil
+	t.mu.Unlock()
+	conn.Close()
+
+	return n, t.redial(err)
+}
+
+// redial retries the dial with exponential backoff until it succeeds or the
+// client is closed. A nil return means the connection is up again.
+func (t *tcpClient) redial(cause error) error {
+	t.notify(serial.Reconnecting, 0, cause)
+
+	backoff := t.minBackoff
+	for retries := 1; ; retries++ {
+		select {
+		case <-t.done:
+			return serial.ErrClosed
+		case <-time.After(backoff):
+		}
+
+		c, err := t.dial(t.addr)
+		if err == nil {
+			t.mu.Lock()
+			if t.closed {
+				t.mu.Unlock()
+				c.Close()
+				return serial.ErrClosed
+			}
+			t.conn = c
+			t.mu.Unlock()
+			t.notify(serial.Connected, retries, nil)
+			return nil
+		}
+
+		state := serial.Reconnecting
+		if retries >= t.lostAfter {
+			state = serial.Lost
+		}
+		t.notify(state, retries, err)
+
+		backoff *= 2
+		if backoff > t.maxBackoff {
+			backoff = t.maxBackoff
+		}
+	}
+}
+
+func (t *tcpClient) notify(state serial.LinkState, retries int, err error) {
+	if t.onState != nil {
+		t.onState(state, retries, err)
+	}
+}
+
+func (t *tcpClient) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	close(t.done)
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+// tcpListener serves bytes from one accepted client at a time. When the
+// client disconnects or the connection fails it waits for the next one
+// instead of ending the stream; only a closed listener ends it.
+type tcpListener struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (t *tcpListener) Read(buf []byte) (int, error) {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn == nil {
+		c, err := t.ln.Accept()
+		if err != nil {
+			return 0, err
+		}
+		t.mu.Lock()
+		t.conn = c
+		t.mu.Unlock()
+		conn = c
+	}
+
+	n, err := conn.Read(buf)
+	if err != nil {
+		conn.Close()
+		t.mu.Lock()
+		if t.conn == conn {
+			t.conn = nil
+		}
+		t.mu.Unlock()
+	}
+	return n, nil
+}
+
+func (t *tcpListener) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn != nil {
+		t.conn.Close()
+	}
+	return t.ln.Close()
+}
+
+// udpSource turns datagrams into a byte stream. Datagrams larger than the
+// caller's buffer are handed out over several reads rather than truncated.
+type udpSource struct {
+	conn    net.PacketConn
+	buf     []byte
+	pending []byte
+}
+
+func (u *udpSource) Read(buf []byte) (int, error) {
+	if len(u.pending) == 0 {
+		n, _, err := u.conn.ReadFrom(u.buf)
+		if err != nil {
+			return 0, err
+		}
+		u.pending = u.buf[:n]
+	}
+	n := copy(buf, u.pending)
+	u.pending = u.pending[n:]
+	return n, nil
+}
+
+func (u *udpSource) Close() error {
+	return u.conn.Close()
+}
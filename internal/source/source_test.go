@@ -0,0 +1,341 @@
+package source
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/serial"
+)
+
+func TestConfig_Scheme(t *testing.T) {
+	tests := []struct {
+		uri    string
+		scheme string
+		addr   string
+	}{
+		{"/dev/ttyUSB0", "serial", "/dev/ttyUSB0"},
+		{"COM3", "serial", "COM3"},
+		{"serial:///dev/ttyACM0", "serial", "/dev/ttyACM0"},
+		{"tcp://192.168.4.1:5760", "tcp", "192.168.4.1:5760"},
+		{"TCP://host:1", "tcp", "host:1"},
+		{"tcp-listen://:5760", "tcp-listen", ":5760"},
+		{"udp://:14550", "udp", ":14550"},
+		{"file:///tmp/dump.bin", "file", "/tmp/dump.bin"},
+		{"stdin", "stdin", "stdin"},
+		{"-", "stdin", "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			c := Config{URI: tt.uri}
+			if got := c.Scheme(); got != tt.scheme {
+				t.Errorf("Scheme() = %q, want %q", got, tt.scheme)
+			}
+			if got := c.Addr(); got != tt.addr {
+				t.Errorf("Addr() = %q, want %q", got, tt.addr)
+			}
+		})
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	c := Config{URI: "/dev/ttyUSB0", Baud: 19200}
+	if got := c.String(); got != "/dev/ttyUSB0 @ 19200 baud" {
+		t.Errorf("String() = %q", got)
+	}
+	c = Config{URI: "udp://:14550", Baud: 19200}
+	if got := c.String(); got != "udp://:14550" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestOpen_UnsupportedScheme(t *testing.T) {
+	_, err := Open(Config{URI: "ftp://example.com"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("err = %v, want unsupported scheme", err)
+	}
+}
+
+func TestOpen_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.bin")
+	os.WriteFile(path, []byte("$TA"), 0644)
+
+	src, err := Open(Config{URI: "file://" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	got, _ := io.ReadAll(src)
+	if string(got) != "$TA" {
+		t.Errorf("read %q, want %q", got, "$TA")
+	}
+}
+
+func TestOpen_TCPClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		c.Write([]byte("hello"))
+		c.Close()
+	}()
+
+	src, err := Open(Config{URI: "tcp://" + ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	got := readAtLeast(t, src, len("hello"))
+	if string(got) != "hello" {
+		t.Errorf("read %q, want %q", got, "hello")
+	}
+}
+
+func TestOpen_TCPClientRedialsAfterReset(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		reset(c)
+
+		c, err = ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.Write([]byte("two"))
+		time.Sleep(100 * time.Millisecond)
+	}()
+
+	var states []serial.LinkState
+	src, err := Open(Config{
+		URI: "tcp://" + ln.Addr().String(),
+		OnLinkState: func(state serial.LinkState, retries int, err error) {
+			states = append(states, state)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	src.(*tcpClient).minBackoff = time.Millisecond
+
+	got := readAtLeast(t, src, len("two"))
+	if string(got) != "two" {
+		t.Errorf("read %q, want %q", got, "two")
+	}
+	if len(states) < 2 || states[0] != serial.Reconnecting || states[len(states)-1] != serial.Connected {
+		t.Errorf("link states = %v, want reconnecting then connected", states)
+	}
+}
+
+func TestOpen_TCPClientCloseStopsRedial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		ln.Close()
+		time.Sleep(20 * time.Millisecond)
+		reset(c)
+	}()
+
+	src, err := Open(Config{URI: "tcp://" + addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := src.Read(make([]byte, 16))
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	src.Close()
+
+	select {
+	case err := <-done:
+		if err != serial.ErrClosed {
+			t.Errorf("err = %v, want %v", err, serial.ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read did not unblock after Close")
+	}
+}
+
+func TestOpen_TCPListenAcceptsNextClient(t *testing.T) {
+	src, err := Open(Config{URI: "tcp-listen://127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	addr := src.(*tcpListener).ln.Addr().String()
+
+	send := func(msg string) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		c.Write([]byte(msg))
+		c.Close()
+	}
+
+	go func() {
+		send("one")
+		time.Sleep(20 * time.Millisecond)
+		send("two")
+	}()
+
+	var got []byte
+	buf := make([]byte, 16)
+	for len(got) < len("onetwo") {
+		n, err := src.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "onetwo" {
+		t.Errorf("read %q, want %q", got, "onetwo")
+	}
+}
+
+func TestOpen_TCPListenSurvivesClientReset(t *testing.T) {
+	src, err := Open(Config{URI: "tcp-listen://127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	addr := src.(*tcpListener).ln.Addr().String()
+
+	go func() {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		reset(c)
+
+		time.Sleep(20 * time.Millisecond)
+		c, err = net.Dial("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		c.Write([]byte("two"))
+		c.Close()
+	}()
+
+	got := readAtLeast(t, src, len("two"))
+	if string(got) != "two" {
+		t.Errorf("read %q, want %q", got, "two")
+	}
+}
+
+func TestOpen_UDPSplitsLargeDatagrams(t *testing.T) {
+	src, err := Open(Config{URI: "udp://127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	addr := src.(*udpSource).conn.LocalAddr().String()
+	c, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	msg := strings.Repeat("x", 600)
+	c.Write([]byte(msg))
+
+	var got []byte
+	buf := make([]byte, 256)
+	for len(got) < len(msg) {
+		n, err := src.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != msg {
+		t.Errorf("read %d bytes, want %d", len(got), len(msg))
+	}
+}
+
+func TestOpen_CloseUnblocksRead(t *testing.T) {
+	src, err := Open(Config{URI: "tcp-listen://127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := src.Read(make([]byte, 16))
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	src.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected error after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read did not unblock after Close")
+	}
+}
+
+// reset aborts c so the peer sees a connection reset rather than EOF.
+func reset(c net.Conn) {
+	c.(*net.TCPConn).SetLinger(0)
+	c.Close()
+}
+
+// readAtLeast reads from r until n bytes have arrived, failing on any error.
+func readAtLeast(t *testing.T, r io.Reader, n int) []byte {
+	t.Helper()
+	var got []byte
+	buf := make([]byte, 16)
+	for len(got) < n {
+		m, err := r.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, buf[:m]...)
+	}
+	return got
+}
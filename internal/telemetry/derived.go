@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"math"
+	"time"
+
+	"fpv-ground-station/internal/geo"
+	"fpv-ground-station/internal/ltm"
+)
+
+// Climb rate smoothing: samples further apart than climbMaxGap restart the
+// estimate, and each new sample is blended in with weight climbAlpha.
+const (
+	climbMaxGap = 5 * time.Second
+	climbAlpha  = 0.3
+)
+
+// Derived holds navigation values computed from GPS and home position.
+type Derived struct {
+	HomeValid         bool    `json:"home_valid"`
+	DistanceToHome    float64 `json:"distance_to_home"`    // m, great-circle
+	BearingToHome     float64 `json:"bearing_to_home"`     // degrees, aircraft → home
+	BearingFromHome   float64 `json:"bearing_from_home"`   // degrees, home → aircraft (antenna pointing)
+	AltitudeAboveHome float64 `json:"altitude_above_home"` // m
+	ClimbRate         float64 `json:"climb_rate"`          // m/s, smoothed
+}
+
+// deriver tracks the state needed to compute Derived values.
+type deriver struct {
+	prevAlt  float64
+	prevTime time.Time
+	climb    float64
+}
+
+// update computes Derived from the latest GPS and origin frames. gps must
+// be non-nil; origin may be nil before the home position is known.
+func (d *deriver) update(gps *ltm.GPSData, gpsTime time.Time, origin *ltm.OriginData) *Derived {
+	// Origin frames recompute with the same GPS sample; only a new sample
+	// moves the climb rate.
+	if !gpsTime.Equal(d.prevTime) {
+		if dt := gpsTime.Sub(d.prevTime); !d.prevTime.IsZero() && dt > 0 && dt <= climbMaxGap {
+			rate := (gps.Altitude - d.prevAlt) / dt.Seconds()
+			d.climb += climbAlpha * (rate - d.climb)
+		} else {
+			d.climb = 0
+		}
+		d.prevAlt, d.prevTime = gps.Altitude, gpsTime
+	}
+
+	// LTM G-frame altitude is already relative to home.
+	out := &Derived{
+		AltitudeAboveHome: gps.Altitude,
+		ClimbRate:         math.Round(d.climb*100) / 100,
+	}
+
+	if origin != nil && origin.Fix > 0 && (origin.Lat != 0 || origin.Lon != 0) && gps.Fix >= 2 {
+		out.HomeValid = true
+		out.DistanceToHome = round1(geo.Distance(gps.Lat, gps.Lon, origin.Lat, origin.Lon))
+		out.BearingToHome = roundBearing(geo.Bearing(gps.Lat, gps.Lon, origin.Lat, origin.Lon))
+		out.BearingFromHome = roundBearing(geo.Bearing(origin.Lat, origin.Lon, gps.Lat, gps.Lon))
+	}
+	return out
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// roundBearing rounds a bearing to 0.1° and keeps it in [0, 360): 359.95°
+// and up round to 360, which wraps to 0.
+func roundBearing(v float64) float64 {
+	return math.Mod(round1(v), 360)
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func TestStore_DerivedNavigation(t *testing.T) {
+	s := &Store{}
+	t0 := time.Now()
+
+	s.Update(gpsFrame(t0, 51.5+latStep100m, -0.1, 30, 10))
+	d := s.Snapshot().Derived
+	if d == nil {
+		t.Fatal("derived should be set after a GPS frame")
+	}
+	if d.HomeValid {
+		t.Error("home should not be valid before an origin frame")
+	}
+	if d.AltitudeAboveHome != 30 {
+		t.Errorf("altitude above home = %v, want 30", d.AltitudeAboveHome)
+	}
+
+	s.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: t0, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Fix: 1}})
+	d = s.Snapshot().Derived
+	if !d.HomeValid {
+		t.Fatal("home should be valid after an origin frame")
+	}
+	if math.Abs(d.DistanceToHome-100) > 1 {
+		t.Errorf("distance = %.1f, want ~100", d.DistanceToHome)
+	}
+	if math.Abs(d.BearingToHome-180) > 0.01 || math.Abs(d.BearingFromHome) > 0.01 {
+		t.Errorf("bearings = %.2f / %.2f, want 180 / 0", d.BearingToHome, d.BearingFromHome)
+	}
+}
+
+func TestStore_DerivedBearingWrapsAtNorth(t *testing.T) {
+	s := &Store{}
+	t0 := time.Now()
+	s.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: t0, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Fix: 1}})
+
+	// Just west of due north: the bearing from home is ~359.97°.
+	s.Update(gpsFrame(t0, 51.5+latStep100m, -0.1-0.0000008, 0, 0))
+	d := s.Snapshot().Derived
+	if d.BearingFromHome != 0 {
+		t.Errorf("bearing from home = %v, want 0 rather than 360", d.BearingFromHome)
+	}
+	if d.BearingToHome < 0 || d.BearingToHome >= 360 {
+		t.Errorf("bearing to home = %v, want within [0, 360)", d.BearingToHome)
+	}
+}
+
+func TestStore_DerivedClimbRate(t *testing.T) {
+	s := &Store{}
+	t0 := time.Now()
+
+	// Climb at a steady 2 m/s; the smoothed estimate converges on it.
+	for i := range 30 {
+		s.Update(gpsFrame(t0.Add(time.Duration(i)*200*time.Millisecond), 51.5, -0.1, float64(i)*0.4, 0))
+	}
+	if got := s.Snapshot().Derived.ClimbRate; math.Abs(got-2) > 0.05 {
+		t.Errorf("climb rate = %.2f, want ~2", got)
+	}
+
+	// An origin frame does not count as a new altitude sample.
+	s.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: t0, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Fix: 1}})
+	if got := s.Snapshot().Derived.ClimbRate; math.Abs(got-2) > 0.05 {
+		t.Errorf("climb rate after origin = %.2f, want ~2", got)
+	}
+
+	// A long gap restarts the estimate.
+	s.Update(gpsFrame(t0.Add(time.Minute), 51.5, -0.1, 100, 0))
+	if got := s.Snapshot().Derived.ClimbRate; got != 0 {
+		t.Errorf("climb rate after gap = %.2f, want 0", got)
+	}
+}
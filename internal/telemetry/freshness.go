@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// StreamState describes how recently a frame type was received.
+type StreamState string
+
+const (
+	StreamNone  StreamState = "none"  // never received
+	StreamFresh StreamState = "fresh" // arriving at about the expected rate
+	StreamStale StreamState = "stale" // several frames missed
+	StreamLost  StreamState = "lost"  // silent long enough to assume the link is down
+)
+
+// ExpectedRate is the nominal rate of each tracked frame type in Hz, as
+// sent by INAV's LTM telemetry.
+var ExpectedRate = map[byte]float64{
+	ltm.FuncAttitude: 10,
+	ltm.FuncGPS:      5,
+	ltm.FuncStatus:   5,
+	ltm.FuncOrigin:   1,
+	ltm.FuncExtra:    1,
+}
+
+// protocolRates are the expected rates of the converted frame types for
+// protocols slower than LTM. They are the low end of common setups:
+// ArduPilot and INAV stream MAVLink ATTITUDE at 2-4 Hz and SYS_STATUS,
+// GPS_RAW_INT and HEARTBEAT at 1-2 Hz, and CRSF sensor frames share the
+// radio's telemetry slots, which at low telemetry ratios leaves each about
+// 1 Hz.
+var protocolRates = map[string]map[byte]float64{
+	"mavlink": {
+		ltm.FuncAttitude: 2,
+		ltm.FuncGPS:      1,
+		ltm.FuncStatus:   1,
+		ltm.FuncOrigin:   1,
+		ltm.FuncExtra:    1,
+	},
+	"crsf": {
+		ltm.FuncAttitude: 1,
+		ltm.FuncGPS:      1,
+		ltm.FuncStatus:   1,
+		ltm.FuncOrigin:   1,
+		ltm.FuncExtra:    1,
+	},
+}
+
+// ExpectedRates returns the expected frame rates for a telemetry protocol,
+// falling back to LTM's for LTM and an unknown or undetected protocol.
+func ExpectedRates(protocol string) map[byte]float64 {
+	if rates, ok := protocolRates[protocol]; ok {
+		return rates
+	}
+	return ExpectedRate
+}
+
+// linkStreams are the frame types every protocol sends continuously, so
+// their freshness is the link's. Origin may be sent only once (MAVLink
+// HOME_POSITION) and GPS and extra stop with the GPS, so they would report a
+// live link as lost.
+var linkStreams = []byte{ltm.FuncAttitude, ltm.FuncStatus}
+
+// A stream goes stale after staleIntervals expected frame periods without
+// a frame, and is lost after lostIntervals: 0.5 s / 1.5 s for attitude,
+// 5 s / 15 s for origin.
+const (
+	staleIntervals = 5
+	lostIntervals  = 15
+)
+
+// StreamFreshness is the freshness of one frame type.
+type StreamFreshness struct {
+	State      StreamState `json:"state"`
+	AgeMS      int64       `json:"age_ms,omitempty"` // since the last frame; 0 if never received
+	ExpectedHz float64     `json:"expected_hz"`
+}
+
+// Freshness reports per-stream and overall telemetry freshness. Overall is
+// the worst state among the link streams that have been received. Without
+// any, it is the state of the freshest stream received, or StreamNone.
+type Freshness struct {
+	Overall StreamState                `json:"overall"`
+	Streams map[string]StreamFreshness `json:"streams"` // keyed by lower-case frame name
+}
+
+// rank orders states from none to lost.
+func (s StreamState) rank() int {
+	switch s {
+	case StreamFresh:
+		return 1
+	case StreamStale:
+		return 2
+	case StreamLost:
+		return 3
+	}
+	return 0
+}
+
+// streamState classifies a stream last received at last with the given
+// expected rate.
+func streamState(last, now time.Time, hz float64) StreamState {
+	if last.IsZero() {
+		return StreamNone
+	}
+	period := time.Duration(float64(time.Second) / hz)
+	switch age := now.Sub(last); {
+	case age > lostIntervals*period:
+		return StreamLost
+	case age > staleIntervals*period:
+		return StreamStale
+	}
+	return StreamFresh
+}
+
+// Freshness computes stream freshness at time now against the expected
+// rates of the snapshot's protocol.
+func (s Snapshot) Freshness(now time.Time) Freshness {
+	times := map[byte]time.Time{
+		ltm.FuncAttitude: s.AttitudeTime,
+		ltm.FuncGPS:      s.GPSTime,
+		ltm.FuncStatus:   s.StatusTime,
+		ltm.FuncOrigin:   s.OriginTime,
+		ltm.FuncExtra:    s.ExtraTime,
+	}
+
+	rates := ExpectedRates(s.Protocol)
+	f := Freshness{Overall: StreamNone, Streams: make(map[string]StreamFreshness, len(rates))}
+	for fn, hz := range rates {
+		last := times[fn]
+		sf := StreamFreshness{State: streamState(last, now, hz), ExpectedHz: hz}
+		if !last.IsZero() {
+			sf.AgeMS = now.Sub(last).Milliseconds()
+		}
+		f.Streams[frameKey(fn)] = sf
+	}
+
+	for _, fn := range linkStreams {
+		if st := f.Streams[frameKey(fn)].State; st.rank() > f.Overall.rank() {
+			f.Overall = st
+		}
+	}
+	if f.Overall == StreamNone {
+		f.Overall = f.Freshest()
+	}
+	return f
+}
+
+// Freshest returns the best state among the streams received, or
+// StreamNone if none have been.
+func (f Freshness) Freshest() StreamState {
+	best := StreamNone
+	for _, sf := range f.Streams {
+		if sf.State != StreamNone && (best == StreamNone || sf.State.rank() < best.rank()) {
+			best = sf.State
+		}
+	}
+	return best
+}
@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshot_Freshness(t *testing.T) {
+	now := time.Now()
+	snap := Snapshot{
+		AttitudeTime: now.Add(-200 * time.Millisecond), // 2 periods at 10 Hz
+		GPSTime:      now.Add(-2 * time.Second),        // 10 periods at 5 Hz
+		StatusTime:   now.Add(-4 * time.Second),        // 20 periods at 5 Hz
+		OriginTime:   now.Add(-3 * time.Second),        // 3 periods at 1 Hz
+	}
+
+	f := snap.Freshness(now)
+	want := map[string]StreamState{
+		"attitude": StreamFresh,
+		"gps":      StreamStale,
+		"status":   StreamLost,
+		"origin":   StreamFresh,
+		"extra":    StreamNone,
+	}
+	for name, state := range want {
+		if got := f.Streams[name].State; got != state {
+			t.Errorf("%s = %s, want %s", name, got, state)
+		}
+	}
+	if f.Overall != StreamLost {
+		t.Errorf("overall = %s, want lost", f.Overall)
+	}
+	if f.Streams["gps"].AgeMS != 2000 || f.Streams["gps"].ExpectedHz != 5 {
+		t.Errorf("gps = %+v", f.Streams["gps"])
+	}
+}
+
+func TestSnapshot_FreshnessOverall(t *testing.T) {
+	now := time.Now()
+
+	if f := (Snapshot{}).Freshness(now); f.Overall != StreamNone {
+		t.Errorf("overall = %s, want none before any frame", f.Overall)
+	}
+
+	// Streams that were never received do not make the link stale.
+	f := Snapshot{AttitudeTime: now, StatusTime: now}.Freshness(now)
+	if f.Overall != StreamFresh {
+		t.Errorf("overall = %s, want fresh", f.Overall)
+	}
+
+	// An origin sent once and a dead GPS do not either.
+	f = Snapshot{AttitudeTime: now, StatusTime: now, GPSTime: now.Add(-time.Minute), OriginTime: now.Add(-time.Hour)}.Freshness(now)
+	if f.Overall != StreamFresh || f.Streams["origin"].State != StreamLost {
+		t.Errorf("freshness = %+v, want overall fresh with origin lost", f)
+	}
+
+	// Without attitude or status frames the freshest stream counts.
+	f = Snapshot{GPSTime: now, OriginTime: now.Add(-time.Hour)}.Freshness(now)
+	if f.Overall != StreamFresh {
+		t.Errorf("overall = %s, want fresh from GPS alone", f.Overall)
+	}
+
+	// A frozen link ages every stream out.
+	f = Snapshot{AttitudeTime: now, StatusTime: now}.Freshness(now.Add(time.Minute))
+	if f.Overall != StreamLost || f.Streams["attitude"].State != StreamLost {
+		t.Errorf("freshness = %+v, want lost", f)
+	}
+}
+
+func TestSnapshot_FreshnessProtocolRates(t *testing.T) {
+	now := time.Now()
+
+	// MAVLink SYS_STATUS and HEARTBEAT at 1 Hz and ATTITUDE at 2 Hz: a
+	// gap of a second and a half is normal, not seven missed LTM periods.
+	snap := Snapshot{
+		Protocol:     "mavlink",
+		AttitudeTime: now.Add(-500 * time.Millisecond),
+		StatusTime:   now.Add(-1500 * time.Millisecond),
+	}
+	f := snap.Freshness(now)
+	if f.Overall != StreamFresh {
+		t.Errorf("mavlink overall = %s, want fresh", f.Overall)
+	}
+	if hz := f.Streams["status"].ExpectedHz; hz != 1 {
+		t.Errorf("mavlink status expected %v Hz, want 1", hz)
+	}
+
+	snap.Protocol = "ltm"
+	if f := snap.Freshness(now); f.Overall != StreamStale {
+		t.Errorf("ltm overall = %s, want stale", f.Overall)
+	}
+
+	// A MAVLink link still goes stale and lost once frames stop.
+	snap.Protocol = "mavlink"
+	if f := snap.Freshness(now.Add(5 * time.Second)); f.Overall != StreamStale {
+		t.Errorf("mavlink overall after 6.5s = %s, want stale", f.Overall)
+	}
+	if f := snap.Freshness(now.Add(15 * time.Second)); f.Overall != StreamLost {
+		t.Errorf("mavlink overall after 16.5s = %s, want lost", f.Overall)
+	}
+}
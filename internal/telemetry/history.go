@@ -0,0 +1,336 @@
+package telemetry
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"fpv-ground-station/internal/crsf"
+	"fpv-ground-station/internal/ltm"
+)
+
+// HistoryResolution is the bucket size of the history buffer: samples
+// arriving within the same second are averaged into one point.
+const HistoryResolution = time.Second
+
+// DefaultHistoryWindow is how far back the history buffer reaches unless
+// Store.SetHistoryWindow says otherwise.
+const DefaultHistoryWindow = time.Hour
+
+// historyField describes how one history field is aggregated. Angles that
+// wrap around keep the latest value instead of averaging.
+type historyField struct {
+	last bool
+}
+
+// historyFields lists the recorded fields.
+var historyFields = map[string]historyField{
+	"alt":         {}, // m, GPS altitude relative to home
+	"speed":       {}, // m/s, ground speed
+	"sats":        {},
+	"climb_rate":  {}, // m/s
+	"distance":    {}, // m to home
+	"pitch":       {}, // degrees
+	"roll":        {}, // degrees
+	"heading":     {last: true},
+	"vbat":        {}, // V
+	"mah_drawn":   {},
+	"rssi":        {},
+	"airspeed":    {}, // m/s
+	"hdop":        {},
+	"uplink_lq":   {}, // %, CRSF only
+	"uplink_rssi": {}, // dBm, CRSF only
+}
+
+// HistoryFields returns the names of the recorded fields, sorted.
+func HistoryFields() []string {
+	names := make([]string, 0, len(historyFields))
+	for name := range historyFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// historyBucket accumulates the samples of one second.
+type historyBucket struct {
+	sec  int64 // Unix seconds
+	sum  float64
+	last float64
+	n    int
+}
+
+func (b historyBucket) value(f historyField) float64 {
+	if f.last {
+		return b.last
+	}
+	return b.sum / float64(b.n)
+}
+
+// historySeries is a ring of completed one-second buckets for one field,
+// plus the bucket in progress. The ring grows as buckets arrive, up to size,
+// so a long window costs memory only once it has filled.
+type historySeries struct {
+	field   historyField
+	size    int // buckets in the window
+	buckets []historyBucket
+	head    int // index of the oldest bucket
+	cur     historyBucket
+}
+
+func (s *historySeries) add(t time.Time, v float64) {
+	sec := t.Unix()
+	if s.cur.n > 0 && sec != s.cur.sec {
+		s.push(s.cur)
+		s.cur = historyBucket{}
+	}
+	s.cur.sec = sec
+	s.cur.sum += v
+	s.cur.last = v
+	s.cur.n++
+}
+
+func (s *historySeries) push(b historyBucket) {
+	if len(s.buckets) < s.size {
+		// head stays 0 until the ring is full.
+		if len(s.buckets) == cap(s.buckets) {
+			s.buckets = slices.Grow(s.buckets, min(max(len(s.buckets), 64), s.size-len(s.buckets)))
+		}
+		s.buckets = append(s.buckets, b)
+		return
+	}
+	s.buckets[s.head] = b
+	s.head = (s.head + 1) % len(s.buckets)
+}
+
+// each calls fn for every bucket from since on, oldest first, including
+// the one in progress.
+func (s *historySeries) each(since int64, fn func(historyBucket)) {
+	for i := range len(s.buckets) {
+		if b := s.buckets[(s.head+i)%len(s.buckets)]; b.sec >= since {
+			fn(b)
+		}
+	}
+	if s.cur.n > 0 && s.cur.sec >= since {
+		fn(s.cur)
+	}
+}
+
+// history holds a series per field. A zero or negative window records the
+// default one; a shorter one than HistoryResolution keeps one bucket.
+//
+// The rings only bound how many buckets are kept. Which of them are still
+// inside the window is decided by time, against the newest sample recorded
+// in any field, so buckets left from before a gap in the data age out
+// even though nothing has replaced them.
+type history struct {
+	window time.Duration
+	series map[string]*historySeries
+	latest int64 // Unix seconds of the newest sample
+}
+
+func (h *history) setWindow(d time.Duration) {
+	h.window = d
+	h.series = nil
+	h.latest = 0
+}
+
+// size is the number of one-second buckets in the window.
+func (h *history) size() int {
+	window := h.window
+	if window <= 0 {
+		window = DefaultHistoryWindow
+	}
+	return max(1, int(window/HistoryResolution))
+}
+
+func (h *history) record(name string, t time.Time, v float64) {
+	if t.IsZero() {
+		return
+	}
+	s := h.series[name]
+	if s == nil {
+		if h.series == nil {
+			h.series = make(map[string]*historySeries)
+		}
+		s = &historySeries{
+			field: historyFields[name],
+			size:  h.size(),
+		}
+		h.series[name] = s
+	}
+	s.add(t, v)
+	h.latest = max(h.latest, t.Unix())
+}
+
+// recordFrame adds the fields carried by f. derived is the store's derived
+// state after f was applied.
+func (h *history) recordFrame(f ltm.Frame, derived *Derived) {
+	t := f.Time
+	switch {
+	case f.GPS != nil:
+		h.record("alt", t, f.GPS.Altitude)
+		h.record("speed", t, float64(f.GPS.GroundSpeed))
+		h.record("sats", t, float64(f.GPS.Sats))
+		if derived != nil {
+			h.record("climb_rate", t, derived.ClimbRate)
+			if derived.HomeValid {
+				h.record("distance", t, derived.DistanceToHome)
+			}
+		}
+	case f.Attitude != nil:
+		h.record("pitch", t, float64(f.Attitude.Pitch))
+		h.record("roll", t, float64(f.Attitude.Roll))
+		h.record("heading", t, float64(f.Attitude.Heading))
+	case f.Status != nil:
+		// Zero volts means no voltage sensor, and RSSI 0 no RSSI source.
+		if f.Status.Vbat > 0 {
+			h.record("vbat", t, f.Status.Vbat)
+		}
+		h.record("mah_drawn", t, float64(f.Status.MAhDrawn))
+		if f.Status.RSSI > 0 {
+			h.record("rssi", t, float64(f.Status.RSSI))
+		}
+		h.record("airspeed", t, float64(f.Status.Airspeed))
+	case f.Extra != nil:
+		h.record("hdop", t, f.Extra.HDOP)
+	}
+}
+
+func (h *history) recordCRSFLink(l *crsf.LinkStatistics, t time.Time) {
+	h.record("uplink_lq", t, float64(l.UplinkLQ))
+	h.record("uplink_rssi", t, float64(l.UplinkRSSI1))
+}
+
+// HistoryQuery selects history for charting.
+type HistoryQuery struct {
+	Fields []string  // empty for every field with data
+	Since  time.Time // zero for the whole window
+	// Step is the output bucket size, rounded up to whole seconds. Zero
+	// picks the smallest step that keeps the result within MaxPoints.
+	Step      time.Duration
+	MaxPoints int // 0 for no limit
+}
+
+// HistorySeries is columnar history: Time holds the start of each bucket
+// and Fields one value per bucket for each field, nil where the field had
+// no samples.
+type HistorySeries struct {
+	StepMS int64                 `json:"step_ms"`
+	Time   []int64               `json:"t"` // Unix ms
+	Fields map[string][]*float64 `json:"fields"`
+}
+
+func (h *history) query(q HistoryQuery) (HistorySeries, error) {
+	fields := q.Fields
+	if len(fields) == 0 {
+		for name := range h.series {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+	}
+	for _, name := range fields {
+		if _, ok := historyFields[name]; !ok {
+			return HistorySeries{}, fmt.Errorf("unknown history field %q (want one of %v)", name, HistoryFields())
+		}
+	}
+
+	// The window ends at the newest sample; since never reaches further
+	// back, so buckets from before a gap longer than the window are left out.
+	since := h.latest - int64(h.size())
+	if !q.Since.IsZero() {
+		since = max(since, q.Since.Unix())
+	}
+	step := int64((q.Step + HistoryResolution - 1) / HistoryResolution)
+	if step <= 0 {
+		step = h.autoStep(fields, since, q.MaxPoints)
+	}
+
+	// Aggregate every field into output buckets keyed by start second.
+	out := make(map[string]map[int64]historyBucket, len(fields))
+	keys := make(map[int64]struct{})
+	for _, name := range fields {
+		s := h.series[name]
+		if s == nil {
+			continue
+		}
+		agg := make(map[int64]historyBucket)
+		s.each(since, func(b historyBucket) {
+			key := b.sec - mod(b.sec, step)
+			a := agg[key]
+			a.sec = key
+			a.sum += b.value(s.field) * float64(b.n)
+			a.last = b.last
+			a.n += b.n
+			agg[key] = a
+			keys[key] = struct{}{}
+		})
+		out[name] = agg
+	}
+
+	res := HistorySeries{
+		StepMS: step * HistoryResolution.Milliseconds(),
+		Time:   make([]int64, 0, len(keys)),
+		Fields: make(map[string][]*float64, len(fields)),
+	}
+	secs := make([]int64, 0, len(keys))
+	for k := range keys {
+		secs = append(secs, k)
+	}
+	slices.Sort(secs)
+	for _, sec := range secs {
+		res.Time = append(res.Time, sec*1000)
+	}
+	for _, name := range fields {
+		col := make([]*float64, len(secs))
+		for i, sec := range secs {
+			if b, ok := out[name][sec]; ok {
+				v := round2(b.value(historyFields[name]))
+				col[i] = &v
+			}
+		}
+		res.Fields[name] = col
+	}
+	return res, nil
+}
+
+// autoStep returns the smallest step in seconds that keeps the requested
+// span within maxPoints buckets.
+func (h *history) autoStep(fields []string, since int64, maxPoints int) int64 {
+	if maxPoints <= 0 {
+		return 1
+	}
+	first, last := int64(-1), int64(-1)
+	for _, name := range fields {
+		s := h.series[name]
+		if s == nil {
+			continue
+		}
+		s.each(since, func(b historyBucket) {
+			if first < 0 || b.sec < first {
+				first = b.sec
+			}
+			last = max(last, b.sec)
+		})
+	}
+	if first < 0 {
+		return 1
+	}
+	// Buckets are aligned to multiples of the step, which can add one.
+	step := max(1, (last-first+int64(maxPoints))/int64(maxPoints))
+	for (last-mod(last, step))/step-(first-mod(first, step))/step+1 > int64(maxPoints) {
+		step++
+	}
+	return step
+}
+
+// mod is the non-negative remainder of a/b.
+func mod(a, b int64) int64 {
+	return ((a % b) + b) % b
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
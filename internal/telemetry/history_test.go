@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/crsf"
+	"fpv-ground-station/internal/ltm"
+)
+
+func TestStore_History(t *testing.T) {
+	s := &Store{}
+	t0 := time.Unix(1_700_000_000, 0)
+
+	// Two status frames per second for 10 s; no GPS until the last 5 s.
+	for i := range 20 {
+		at := t0.Add(time.Duration(i) * 500 * time.Millisecond)
+		s.Update(ltm.Frame{Function: ltm.FuncStatus, Time: at, Status: &ltm.StatusData{Vbat: 16 - float64(i)*0.1, RSSI: 100}})
+		if i >= 10 {
+			s.Update(ltm.Frame{Function: ltm.FuncGPS, Time: at, GPS: &ltm.GPSData{Lat: 51.5, Lon: -0.1, Altitude: float64(i), Fix: 3}})
+		}
+	}
+
+	h, err := s.History(HistoryQuery{Fields: []string{"vbat", "alt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.StepMS != 1000 || len(h.Time) != 10 || h.Time[0] != t0.UnixMilli() {
+		t.Fatalf("step = %d, times = %v", h.StepMS, h.Time)
+	}
+	// The first second averages 16.0 and 15.9.
+	if v := h.Fields["vbat"][0]; v == nil || *v != 15.95 {
+		t.Errorf("vbat[0] = %v, want 15.95", v)
+	}
+	// The in-progress second is included.
+	if v := h.Fields["vbat"][9]; v == nil || *v != 14.15 {
+		t.Errorf("vbat[9] = %v, want 14.15", v)
+	}
+	if h.Fields["alt"][4] != nil || h.Fields["alt"][5] == nil || *h.Fields["alt"][5] != 10.5 {
+		t.Errorf("alt = %v, want gaps before GPS then 10.5", h.Fields["alt"])
+	}
+
+	h, _ = s.History(HistoryQuery{Fields: []string{"vbat"}, Since: t0.Add(8 * time.Second)})
+	if len(h.Time) != 2 {
+		t.Errorf("since: %d points, want 2", len(h.Time))
+	}
+
+	h, _ = s.History(HistoryQuery{Fields: []string{"rssi"}, MaxPoints: 3})
+	if len(h.Time) > 3 || h.StepMS < 4000 {
+		t.Errorf("max points: step %d ms, %d points", h.StepMS, len(h.Time))
+	}
+	h, _ = s.History(HistoryQuery{Fields: []string{"rssi"}, Step: 5 * time.Second})
+	if h.StepMS != 5000 || len(h.Time) > 3 || *h.Fields["rssi"][0] != 100 {
+		t.Errorf("step: %+v", h)
+	}
+
+	if _, err := s.History(HistoryQuery{Fields: []string{"altitude"}}); err == nil || !strings.Contains(err.Error(), "alt") {
+		t.Errorf("unknown field error = %v", err)
+	}
+
+	h, _ = s.History(HistoryQuery{})
+	if _, ok := h.Fields["sats"]; !ok || len(h.Fields) != 8 {
+		t.Errorf("all fields = %d, want the 8 with data", len(h.Fields))
+	}
+}
+
+func TestStore_HistoryWindow(t *testing.T) {
+	s := &Store{}
+	s.SetHistoryWindow(5 * time.Second)
+	t0 := time.Unix(1_700_000_000, 0)
+	for i := range 20 {
+		s.UpdateCRSFLink(&crsf.LinkStatistics{UplinkLQ: uint8(i)}, t0.Add(time.Duration(i)*time.Second))
+	}
+
+	// Five completed seconds plus the one in progress.
+	h, _ := s.History(HistoryQuery{Fields: []string{"uplink_lq"}})
+	if len(h.Time) != 6 || *h.Fields["uplink_lq"][0] != 14 || *h.Fields["uplink_lq"][5] != 19 {
+		t.Errorf("history = %v %v", h.Time, h.Fields["uplink_lq"])
+	}
+}
+
+func TestStore_HistoryGap(t *testing.T) {
+	s := &Store{}
+	s.SetHistoryWindow(10 * time.Second)
+	t0 := time.Unix(1_700_000_000, 0)
+	for i := range 5 {
+		s.UpdateCRSFLink(&crsf.LinkStatistics{UplinkLQ: 50}, t0.Add(time.Duration(i)*time.Second))
+		s.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0.Add(time.Duration(i) * time.Second), Status: &ltm.StatusData{Vbat: 16}})
+	}
+	// After a minute's silence only the link statistics resume; the ring
+	// still holds the old buckets, but they are outside the window.
+	t1 := t0.Add(time.Minute)
+	for i := range 3 {
+		s.UpdateCRSFLink(&crsf.LinkStatistics{UplinkLQ: 90}, t1.Add(time.Duration(i)*time.Second))
+	}
+
+	for _, since := range []time.Time{{}, t0} {
+		h, _ := s.History(HistoryQuery{Fields: []string{"uplink_lq", "vbat"}, Since: since})
+		if len(h.Time) != 3 || h.Time[0] != t1.UnixMilli() {
+			t.Errorf("since %v: times = %v, want the 3 seconds after the gap", since, h.Time)
+		}
+		for _, v := range h.Fields["vbat"] {
+			if v != nil {
+				t.Errorf("since %v: vbat = %v, want none from before the gap", since, *v)
+			}
+		}
+	}
+}
+
+func TestStore_HistoryGrowsLazily(t *testing.T) {
+	s := &Store{}
+	s.SetHistoryWindow(720 * time.Hour)
+	t0 := time.Unix(1_700_000_000, 0)
+	for i := range 100 {
+		s.UpdateCRSFLink(&crsf.LinkStatistics{UplinkLQ: uint8(i)}, t0.Add(time.Duration(i)*time.Second))
+	}
+
+	if n := cap(s.history.series["uplink_lq"].buckets); n > 200 {
+		t.Errorf("ring capacity = %d after 100 s, want it to grow with the data", n)
+	}
+	h, _ := s.History(HistoryQuery{Fields: []string{"uplink_lq"}})
+	if len(h.Time) != 100 || *h.Fields["uplink_lq"][0] != 0 || *h.Fields["uplink_lq"][99] != 99 {
+		t.Errorf("history = %d points", len(h.Time))
+	}
+}
+
+func TestStore_HistorySkipsMissingSensors(t *testing.T) {
+	s := &Store{}
+	t0 := time.Unix(1_700_000_000, 0)
+	s.Update(ltm.Frame{Function: ltm.FuncStatus, Time: t0, Status: &ltm.StatusData{MAhDrawn: 10}})
+
+	h, _ := s.History(HistoryQuery{})
+	for _, name := range []string{"vbat", "rssi"} {
+		if _, ok := h.Fields[name]; ok {
+			t.Errorf("%s recorded without a sensor: %v", name, h.Fields[name])
+		}
+	}
+}
+
+func TestStore_HistoryHeading(t *testing.T) {
+	s := &Store{}
+	t0 := time.Unix(1_700_000_000, 0)
+	for i, hdg := range []int16{350, 359, 5} {
+		s.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: t0.Add(time.Duration(i) * 100 * time.Millisecond), Attitude: &ltm.AttitudeData{Heading: hdg}})
+	}
+	// Averaging across north would give 238; the latest heading is kept.
+	h, _ := s.History(HistoryQuery{Fields: []string{"heading"}})
+	if v := h.Fields["heading"][0]; v == nil || *v != 5 {
+		t.Errorf("heading = %v, want 5", v)
+	}
+}
@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"math"
+	"time"
+)
+
+// linkWindow is how many seconds link quality is measured over.
+const linkWindow = 10
+
+// StreamRate compares a frame type's observed rate with its expected rate.
+type StreamRate struct {
+	ObservedHz float64
+	ExpectedHz float64
+}
+
+// LinkQuality summarizes link health over the last WindowSec seconds.
+//
+// Quality starts from the fraction of expected frames that arrived, at the
+// detected protocol's expected rates, summed over the frame types received
+// so far (types a protocol never sends are left out). If X-frame counter
+// gaps show a larger loss, that is used instead. The result is scaled down
+// by the share of frames failing CRC.
+type LinkQuality struct {
+	Quality      int // 0-100
+	WindowSec    int
+	Streams      map[string]StreamRate // keyed by lower-case frame name
+	XReceived    int
+	XLost        int
+	CRCErrors    int
+	CRCErrorRate float64 // fraction of frames in the window that failed CRC
+}
+
+// RecordXCounter records the sequence counter of a received X-frame. INAV
+// increments it for every X-frame sent, so a jump of more than one counts
+// the frames lost in between. A repeated value carries no information
+// (converted protocols always send 0) and is ignored. After a silence longer
+// than the measurement window, such as a reboot or link outage, the counter
+// starts over rather than counting the jump as loss.
+func (s *Stats) RecordXCounter(c uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordXCounter(c, time.Now())
+}
+
+func (s *Stats) recordXCounter(c uint8, now time.Time) {
+	if s.haveX && now.Sub(s.xTime) > linkWindow*time.Second {
+		s.haveX = false
+	}
+	if s.haveX && c == s.lastX {
+		return
+	}
+	w := s.window.at(now)
+	w.XReceived++
+	if s.haveX {
+		w.XLost += int(c - s.lastX - 1)
+	}
+	s.lastX, s.xTime, s.haveX = c, now, true
+}
+
+// linkQuality computes LinkQuality at time now. The caller holds s.mu.
+func (s *Stats) linkQuality(now time.Time) LinkQuality {
+	n := span(s.StartTime, now, linkWindow)
+	lq := LinkQuality{WindowSec: n, Streams: make(map[string]StreamRate)}
+	if n < 1 {
+		return lq
+	}
+	c := s.window.sum(now, n)
+
+	var observed, expected float64
+	for fn, hz := range ExpectedRates(s.Protocol) {
+		if s.Frames[fn] == 0 {
+			continue
+		}
+		rate := float64(c.Frames[fn]) / float64(n)
+		lq.Streams[frameKey(fn)] = StreamRate{ObservedHz: rate, ExpectedHz: hz}
+		observed += min(rate, hz)
+		expected += hz
+	}
+
+	lq.XReceived, lq.XLost = c.XReceived, c.XLost
+	lq.CRCErrors = c.CRCErrors
+	if total := c.total() + c.CRCErrors; total > 0 {
+		lq.CRCErrorRate = float64(c.CRCErrors) / float64(total)
+	}
+
+	if expected == 0 {
+		return lq
+	}
+	delivered := observed / expected
+	if sent := c.XReceived + c.XLost; sent > 0 {
+		delivered = min(delivered, float64(c.XReceived)/float64(sent))
+	}
+	lq.Quality = int(math.Round(100 * delivered * (1 - lq.CRCErrorRate)))
+	return lq
+}
@@ -0,0 +1,119 @@
+package telemetry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// feed counts frames at their expected rates for the given whole seconds
+// after start, dropping every dropNth attitude frame (0 drops none).
+func feed(s *Stats, start time.Time, seconds, dropNth int) {
+	for sec := 0; sec < seconds; sec++ {
+		base := start.Add(time.Duration(sec) * time.Second)
+		for i := 0; i < 10; i++ {
+			if dropNth == 0 || i%dropNth != 0 {
+				s.count(ltm.FuncAttitude, base.Add(time.Duration(i)*100*time.Millisecond))
+			}
+		}
+		for i := 0; i < 5; i++ {
+			s.count(ltm.FuncStatus, base.Add(time.Duration(i)*200*time.Millisecond))
+		}
+	}
+}
+
+func TestStats_LinkQualityFullRate(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+	feed(s, start, 20, 0)
+
+	lq := s.linkQuality(start.Add(20 * time.Second))
+	if lq.WindowSec != linkWindow {
+		t.Errorf("window = %d, want %d", lq.WindowSec, linkWindow)
+	}
+	if lq.Quality != 100 {
+		t.Errorf("quality = %d, want 100", lq.Quality)
+	}
+	if r := lq.Streams["attitude"]; r.ObservedHz != 10 || r.ExpectedHz != 10 {
+		t.Errorf("attitude rate = %+v", r)
+	}
+	if _, ok := lq.Streams["gps"]; ok {
+		t.Error("streams never received should not be listed")
+	}
+}
+
+func TestStats_LinkQualityMAVLinkRates(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+	s.Protocol = "mavlink"
+	for sec := 0; sec < 20; sec++ {
+		base := start.Add(time.Duration(sec) * time.Second)
+		s.count(ltm.FuncAttitude, base)
+		s.count(ltm.FuncAttitude, base.Add(500*time.Millisecond))
+		s.count(ltm.FuncStatus, base)
+	}
+
+	if lq := s.linkQuality(start.Add(20 * time.Second)); lq.Quality != 100 {
+		t.Errorf("quality = %d, want 100 at MAVLink rates", lq.Quality)
+	}
+}
+
+func TestStats_LinkQualityLossAndErrors(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+	feed(s, start, 10, 2) // half the attitude frames missing
+
+	now := start.Add(10 * time.Second)
+	lq := s.linkQuality(now)
+	// (5 + 5) / (10 + 5) of expected frames arrived.
+	if lq.Quality != 67 {
+		t.Errorf("quality = %d, want 67", lq.Quality)
+	}
+
+	// 100 frames in the window; 25 CRC failures make 20% of 125.
+	s.window.at(start.Add(5 * time.Second)).CRCErrors = 25
+	lq = s.linkQuality(now)
+	if math.Abs(lq.CRCErrorRate-0.2) > 1e-9 || lq.Quality != 53 {
+		t.Errorf("crc rate = %v, quality = %d, want 0.2, 53", lq.CRCErrorRate, lq.Quality)
+	}
+}
+
+func TestStats_LinkQualityStartup(t *testing.T) {
+	s := NewStats()
+	if lq := s.linkQuality(s.StartTime); lq.WindowSec != 0 || lq.Quality != 0 {
+		t.Errorf("link quality = %+v, want empty before a full second", lq)
+	}
+}
+
+func TestStats_XCounterGaps(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+
+	for i, c := range []uint8{250, 251, 251, 254, 1} { // 251 repeated; 252, 253, 255, 0 lost
+		s.recordXCounter(c, start.Add(time.Duration(i)*time.Second))
+	}
+	lq := s.linkQuality(start.Add(5 * time.Second))
+	if lq.XReceived != 4 || lq.XLost != 4 {
+		t.Errorf("x received/lost = %d/%d, want 4/4", lq.XReceived, lq.XLost)
+	}
+}
+
+func TestStats_XCounterAfterOutage(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+
+	s.recordXCounter(10, start)
+	s.recordXCounter(200, start.Add(20*time.Second)) // FC rebooted during the outage
+	s.recordXCounter(201, start.Add(21*time.Second))
+	lq := s.linkQuality(start.Add(22 * time.Second))
+	if lq.XReceived != 2 || lq.XLost != 0 {
+		t.Errorf("x received/lost = %d/%d, want 2/0", lq.XReceived, lq.XLost)
+	}
+}
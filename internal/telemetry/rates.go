@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// RateWindowSeconds are the spans StatsSnapshot.Windows reports, shortest
+// first. The shortest also drives StatsSnapshot.FPS.
+var RateWindowSeconds = []int{1, 10, 60}
+
+// Error bursts: errors less than burstGap apart belong to the same burst,
+// and a run of at least burstMinErrors errors counts as one.
+const (
+	burstGap       = time.Second
+	burstMinErrors = 3
+)
+
+// RateWindow holds rates over the last Seconds whole seconds. Seconds is
+// shorter than Window until that much time has passed since start.
+type RateWindow struct {
+	Window       int // nominal span, from RateWindowSeconds
+	Seconds      int
+	FPS          float64
+	Frames       map[string]float64 // frames per second, keyed by lower-case frame name
+	BytesPerSec  float64
+	CRCErrors    int
+	DecodeErrors int
+}
+
+// ErrorBurst is a run of CRC or decode errors in quick succession, typically
+// a stretch of line noise or a baud rate glitch.
+type ErrorBurst struct {
+	Start  time.Time
+	End    time.Time // time of the latest error
+	Errors int
+}
+
+// burstTracker groups errors into bursts.
+type burstTracker struct {
+	current ErrorBurst // run in progress, possibly below burstMinErrors
+	last    ErrorBurst // most recent run that counted as a burst
+	count   int
+}
+
+func (b *burstTracker) record(now time.Time) {
+	if b.current.Errors == 0 || now.Sub(b.current.End) >= burstGap {
+		b.current = ErrorBurst{Start: now}
+	}
+	b.current.End = now
+	b.current.Errors++
+
+	switch {
+	case b.current.Errors == burstMinErrors:
+		b.count++
+		b.last = b.current
+	case b.current.Errors > burstMinErrors:
+		b.last = b.current
+	}
+}
+
+// active reports whether the latest burst is still in progress at now.
+func (b *burstTracker) active(now time.Time) bool {
+	return b.last.Errors > 0 && b.last.Start.Equal(b.current.Start) && now.Sub(b.current.End) < burstGap
+}
+
+// RecordBytes adds n received bytes to the throughput counters.
+func (s *Stats) RecordBytes(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Bytes += n
+	s.window.at(time.Now()).Bytes += n
+}
+
+// rateWindow computes rates over the last n seconds at time now. The
+// caller holds s.mu.
+func (s *Stats) rateWindow(now time.Time, n int) RateWindow {
+	w := RateWindow{Window: n, Seconds: span(s.StartTime, now, n), Frames: make(map[string]float64)}
+	if w.Seconds < 1 {
+		return w
+	}
+	c := s.window.sum(now, w.Seconds)
+	sec := float64(w.Seconds)
+
+	w.FPS = float64(c.total()) / sec
+	for fn, count := range c.Frames {
+		w.Frames[frameKey(fn)] = float64(count) / sec
+	}
+	w.BytesPerSec = float64(c.Bytes) / sec
+	w.CRCErrors = c.CRCErrors
+	w.DecodeErrors = c.DecodeErrors
+	return w
+}
+
+// frameKey names a frame function in rate maps, e.g. "attitude".
+func frameKey(fn byte) string {
+	if name, ok := ltm.FrameName[fn]; ok {
+		return strings.ToLower(name)
+	}
+	return string(fn)
+}
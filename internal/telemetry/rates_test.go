@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func TestStats_RateWindows(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+
+	// 60 s of attitude at 10 Hz, then a 5 s dropout.
+	for sec := 0; sec < 60; sec++ {
+		at := start.Add(time.Duration(sec) * time.Second)
+		for i := 0; i < 10; i++ {
+			s.count(ltm.FuncAttitude, at.Add(time.Duration(i)*100*time.Millisecond))
+		}
+		s.window.at(at).Bytes += 80
+	}
+
+	snap := s.snapshot(start.Add(65 * time.Second))
+	if len(snap.Windows) != len(RateWindowSeconds) {
+		t.Fatalf("windows = %d, want %d", len(snap.Windows), len(RateWindowSeconds))
+	}
+	w1, w10, w60 := snap.Windows[0], snap.Windows[1], snap.Windows[2]
+	if w1.FPS != 0 || snap.FPS != 0 {
+		t.Errorf("1s fps = %v, snapshot fps = %v, want 0 during dropout", w1.FPS, snap.FPS)
+	}
+	if w10.FPS != 5 || w10.Frames["attitude"] != 5 {
+		t.Errorf("10s window = %+v, want 5 fps", w10)
+	}
+	if w60.Window != 60 || w60.Seconds != 60 || w60.BytesPerSec != 55*80.0/60 {
+		t.Errorf("60s window = %+v", w60)
+	}
+	if snap.AvgFPS < 9 {
+		t.Errorf("avg fps = %v, want lifetime average near 10", snap.AvgFPS)
+	}
+}
+
+func TestStats_RateWindowsStartup(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	s := NewStats()
+	s.StartTime = start
+	for i := 0; i < 30; i++ {
+		s.count(ltm.FuncGPS, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+
+	// Three seconds in, the 60 s window covers only those three.
+	w := s.snapshot(start.Add(3 * time.Second)).Windows[2]
+	if w.Window != 60 || w.Seconds != 3 || w.FPS != 10 {
+		t.Errorf("window = %+v, want 10 fps over 3 s", w)
+	}
+}
+
+func TestBurstTracker(t *testing.T) {
+	var b burstTracker
+	t0 := time.Unix(1_000_000, 0)
+
+	// Two isolated errors are not a burst.
+	b.record(t0)
+	b.record(t0.Add(2 * time.Second))
+	if b.count != 0 {
+		t.Fatalf("count = %d, want 0 for isolated errors", b.count)
+	}
+
+	for i := 0; i < 5; i++ {
+		b.record(t0.Add(10*time.Second + time.Duration(i)*200*time.Millisecond))
+	}
+	if b.count != 1 || b.last.Errors != 5 {
+		t.Errorf("burst = %d / %+v, want one burst of 5", b.count, b.last)
+	}
+	if !b.active(t0.Add(11 * time.Second)) {
+		t.Error("burst should be active within the gap of its last error")
+	}
+	if b.active(t0.Add(13 * time.Second)) {
+		t.Error("burst should end after the gap")
+	}
+
+	// A later error alone does not end the recorded burst's details.
+	b.record(t0.Add(20 * time.Second))
+	if b.count != 1 || b.last.Errors != 5 || b.active(t0.Add(20*time.Second)) {
+		t.Errorf("burst = %d / %+v after a lone error", b.count, b.last)
+	}
+}
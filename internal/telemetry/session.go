@@ -0,0 +1,185 @@
+package telemetry
+
+import (
+	"sync"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// disarmReasonGrace is how long after disarm an X-frame may still update
+// the closed flight's disarm reason. X-frames arrive at 1 Hz, so the frame
+// carrying the reason usually follows the disarming S-frame.
+const disarmReasonGrace = 3 * time.Second
+
+// Flight is one armed period, from arm to disarm.
+type Flight struct {
+	ID               int             `json:"id"`
+	Start            time.Time       `json:"start"`
+	End              time.Time       `json:"end"` // zero while active
+	Active           bool            `json:"active"`
+	DisarmReason     uint8           `json:"disarm_reason"`
+	DisarmReasonName string          `json:"disarm_reason_name"`
+	Home             *ltm.OriginData `json:"home,omitempty"`
+	Summary          FlightSummary   `json:"summary"`
+	Track            [][2]float64    `json:"track,omitempty"` // lat,lon
+}
+
+// Sessions splits the telemetry stream into flights on arm/disarm
+// transitions, safe for concurrent access.
+type Sessions struct {
+	mu sync.Mutex
+
+	flights []*Flight
+	current *Flight
+	armed   bool
+	sum     Summarizer // for the current flight
+
+	// OnChange is called with a copy of a flight when it starts and ends.
+	OnChange func(Flight)
+}
+
+// NewSessions creates an empty session manager.
+func NewSessions() *Sessions {
+	return &Sessions{}
+}
+
+// Update folds a decoded frame into the current flight, opening or closing
+// flights as the armed state changes.
+func (s *Sessions) Update(f ltm.Frame) {
+	s.mu.Lock()
+	changed := s.update(f)
+	var snap Flight
+	if changed != nil {
+		snap = s.copyFlight(changed, false)
+	}
+	s.mu.Unlock()
+
+	if changed != nil && s.OnChange != nil {
+		s.OnChange(snap)
+	}
+}
+
+// update applies f and returns the flight that started or ended, if any.
+func (s *Sessions) update(f ltm.Frame) *Flight {
+	if f.Status != nil && f.Status.Armed != s.armed {
+		s.armed = f.Status.Armed
+		if s.armed {
+			s.current = &Flight{
+				ID:     len(s.flights) + 1,
+				Start:  f.Time,
+				Active: true,
+			}
+			s.flights = append(s.flights, s.current)
+			s.sum = Summarizer{}
+			s.sum.Update(f)
+			return s.current
+		}
+
+		fl := s.current
+		s.current = nil
+		s.sum.Update(f)
+		fl.End = f.Time
+		fl.Active = false
+		fl.Summary = s.sum.Summary()
+		fl.DisarmReasonName = ltm.DisarmReasonName[fl.DisarmReason]
+		return fl
+	}
+
+	if s.current != nil {
+		s.sum.Update(f)
+	}
+
+	switch {
+	case f.GPS != nil:
+		g := f.GPS
+		if s.current != nil && g.Fix >= 2 && (g.Lat != 0 || g.Lon != 0) {
+			s.current.Track = append(s.current.Track, [2]float64{g.Lat, g.Lon})
+		}
+
+	case f.Origin != nil:
+		if s.current != nil && f.Origin.Lat != 0 {
+			home := *f.Origin
+			s.current.Home = &home
+		}
+
+	case f.Extra != nil:
+		fl := s.current
+		if fl == nil && len(s.flights) > 0 {
+			// The reason for the last disarm may trail the S-frame.
+			last := s.flights[len(s.flights)-1]
+			if f.Time.Sub(last.End) <= disarmReasonGrace {
+				fl = last
+			}
+		}
+		if fl != nil {
+			fl.DisarmReason = f.Extra.DisarmReason
+			if !fl.Active {
+				fl.DisarmReasonName = ltm.DisarmReasonName[fl.DisarmReason]
+			}
+		}
+	}
+	return nil
+}
+
+// copyFlight returns a copy of fl that is safe to use without the lock.
+func (s *Sessions) copyFlight(fl *Flight, withTrack bool) Flight {
+	c := *fl
+	if fl.Active {
+		c.Summary = s.sum.Summary()
+	}
+	if fl.Home != nil {
+		home := *fl.Home
+		c.Home = &home
+	}
+	c.Track = nil
+	if withTrack {
+		c.Track = append([][2]float64(nil), fl.Track...)
+	}
+	return c
+}
+
+// List returns all flights, oldest first, without their tracks.
+func (s *Sessions) List() []Flight {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Flight, len(s.flights))
+	for i, fl := range s.flights {
+		out[i] = s.copyFlight(fl, false)
+	}
+	return out
+}
+
+// Get returns the flight with the given ID, including its track.
+func (s *Sessions) Get(id int) (Flight, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > len(s.flights) {
+		return Flight{}, false
+	}
+	return s.copyFlight(s.flights[id-1], true), true
+}
+
+// Latest returns the active flight, or the most recent one if none is active.
+func (s *Sessions) Latest() (Flight, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.flights) == 0 {
+		return Flight{}, false
+	}
+	return s.copyFlight(s.flights[len(s.flights)-1], false), true
+}
+
+// Current returns the active flight, if any.
+func (s *Sessions) Current() (Flight, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return Flight{}, false
+	}
+	return s.copyFlight(s.current, false), true
+}
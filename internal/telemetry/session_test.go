@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func statusFrame(t time.Time, armed bool, mah uint16) ltm.Frame {
+	return ltm.Frame{Function: ltm.FuncStatus, Time: t, Status: &ltm.StatusData{Armed: armed, MAhDrawn: mah}}
+}
+
+func gpsFrame(t time.Time, lat, lon, alt float64, speed uint8) ltm.Frame {
+	return ltm.Frame{Function: ltm.FuncGPS, Time: t, GPS: &ltm.GPSData{Lat: lat, Lon: lon, Altitude: alt, GroundSpeed: speed, Fix: 3}}
+}
+
+func TestSessions_ArmDisarm(t *testing.T) {
+	s := NewSessions()
+	var events []Flight
+	s.OnChange = func(f Flight) { events = append(events, f) }
+
+	t0 := time.Now()
+	s.Update(gpsFrame(t0, 51.5, -0.1, 0, 0)) // before arming: ignored
+	s.Update(statusFrame(t0, false, 0))
+	s.Update(statusFrame(t0.Add(time.Second), true, 10))
+	s.Update(ltm.Frame{Function: ltm.FuncOrigin, Time: t0, Origin: &ltm.OriginData{Lat: 51.5, Lon: -0.1, Fix: 1}})
+	s.Update(gpsFrame(t0.Add(2*time.Second), 51.501, -0.1, 40, 12))
+	s.Update(gpsFrame(t0.Add(3*time.Second), 51.502, -0.1, 25, 18))
+	s.Update(statusFrame(t0.Add(61*time.Second), false, 310))
+	s.Update(ltm.Frame{Function: ltm.FuncExtra, Time: t0.Add(62 * time.Second), Extra: &ltm.ExtraData{DisarmReason: 4}})
+
+	if len(events) != 2 || !events[0].Active || events[1].Active {
+		t.Fatalf("events = %+v, want start then end", events)
+	}
+
+	flights := s.List()
+	if len(flights) != 1 {
+		t.Fatalf("got %d flights, want 1", len(flights))
+	}
+	f := flights[0]
+	if f.ID != 1 || f.Active {
+		t.Errorf("flight = %+v", f)
+	}
+	if f.Summary.DurationSec != 60 {
+		t.Errorf("duration = %v, want 60", f.Summary.DurationSec)
+	}
+	if f.Summary.MaxAltitude != 40 || f.Summary.MaxGroundSpeed != 18 || f.Summary.MAhUsed != 300 {
+		t.Errorf("summary = %+v", f.Summary)
+	}
+	if f.Home == nil || f.Home.Lat != 51.5 {
+		t.Errorf("home = %+v", f.Home)
+	}
+	if f.DisarmReason != 4 || f.DisarmReasonName != "Switch" {
+		t.Errorf("disarm reason = %d %q, want 4 Switch", f.DisarmReason, f.DisarmReasonName)
+	}
+	if f.Track != nil {
+		t.Error("List should not include tracks")
+	}
+
+	full, ok := s.Get(1)
+	if !ok || len(full.Track) != 2 || full.Summary.TrackPoints != 2 {
+		t.Errorf("Get(1) track = %v", full.Track)
+	}
+}
+
+func TestSessions_MultipleFlights(t *testing.T) {
+	s := NewSessions()
+	t0 := time.Now()
+	for i := range 3 {
+		base := t0.Add(time.Duration(i) * time.Minute)
+		s.Update(statusFrame(base, true, 0))
+		s.Update(statusFrame(base.Add(30*time.Second), false, 0))
+	}
+	s.Update(statusFrame(t0.Add(time.Hour), true, 0))
+
+	flights := s.List()
+	if len(flights) != 4 {
+		t.Fatalf("got %d flights, want 4", len(flights))
+	}
+	for i, f := range flights {
+		if f.ID != i+1 {
+			t.Errorf("flights[%d].ID = %d", i, f.ID)
+		}
+	}
+	cur, ok := s.Current()
+	if !ok || cur.ID != 4 || !cur.Active {
+		t.Errorf("current = %+v, %v", cur, ok)
+	}
+	if _, ok := s.Get(5); ok {
+		t.Error("Get(5) should not exist")
+	}
+}
+
+func TestSessions_LateDisarmReasonIgnoredAfterGrace(t *testing.T) {
+	s := NewSessions()
+	t0 := time.Now()
+	s.Update(statusFrame(t0, true, 0))
+	s.Update(statusFrame(t0.Add(time.Second), false, 0))
+	s.Update(ltm.Frame{Function: ltm.FuncExtra, Time: t0.Add(time.Minute), Extra: &ltm.ExtraData{DisarmReason: 6}})
+
+	if f, _ := s.Get(1); f.DisarmReason != 0 {
+		t.Errorf("disarm reason = %d, want 0", f.DisarmReason)
+	}
+}
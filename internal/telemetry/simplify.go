@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"math"
+
+	"fpv-ground-station/internal/geo"
+)
+
+// simplifyPrecision is the tolerance resolution in metres that SimplifyTo
+// searches down to.
+const simplifyPrecision = 0.01
+
+// Simplify reduces points with the Douglas–Peucker algorithm: the first and
+// last points are kept, as is every point further than tolerance metres
+// from the simplified line. Distances are measured in 3D, so climbs and
+// descents survive even where the ground track is straight.
+func Simplify(points []TrackPoint, tolerance float64) []TrackPoint {
+	if len(points) < 3 || tolerance <= 0 {
+		return points
+	}
+	xyz := projectTrack(points)
+	return keepPoints(points, douglasPeucker(xyz, tolerance))
+}
+
+// SimplifyTo reduces points to at most n (and no fewer than 2) with the
+// smallest Douglas–Peucker tolerance that achieves it, found by bisection.
+func SimplifyTo(points []TrackPoint, n int) []TrackPoint {
+	n = max(n, 2)
+	if len(points) <= n {
+		return points
+	}
+	xyz := projectTrack(points)
+
+	// At the largest deviation from the first-to-last line, only the
+	// endpoints remain.
+	_, hi := farthest(xyz, 0, len(xyz)-1)
+	lo := 0.0
+	best := douglasPeucker(xyz, hi)
+	for hi-lo > simplifyPrecision {
+		mid := (lo + hi) / 2
+		keep := douglasPeucker(xyz, mid)
+		if countKept(keep) <= n {
+			hi, best = mid, keep
+		} else {
+			lo = mid
+		}
+	}
+	return keepPoints(points, best)
+}
+
+// projectTrack maps points to metres on a plane tangent at the first point,
+// with altitude as the third axis. The distortion is negligible over the
+// range of a model aircraft.
+func projectTrack(points []TrackPoint) [][3]float64 {
+	lat0, lon0 := points[0].Lat, points[0].Lon
+	rad := math.Pi / 180
+	kx := geo.EarthRadius * rad * math.Cos(lat0*rad)
+	ky := geo.EarthRadius * rad
+	xyz := make([][3]float64, len(points))
+	for i, p := range points {
+		xyz[i] = [3]float64{(p.Lon - lon0) * kx, (p.Lat - lat0) * ky, p.Alt}
+	}
+	return xyz
+}
+
+// douglasPeucker marks the points to keep at the given tolerance.
+func douglasPeucker(xyz [][3]float64, tolerance float64) []bool {
+	keep := make([]bool, len(xyz))
+	keep[0], keep[len(xyz)-1] = true, true
+	stack := [][2]int{{0, len(xyz) - 1}}
+	for len(stack) > 0 {
+		a, b := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		i, d := farthest(xyz, a, b)
+		if i < 0 || d <= tolerance {
+			continue
+		}
+		keep[i] = true
+		stack = append(stack, [2]int{a, i}, [2]int{i, b})
+	}
+	return keep
+}
+
+// farthest returns the point strictly between a and b that lies furthest
+// from segment a–b, or -1 if there is none.
+func farthest(xyz [][3]float64, a, b int) (int, float64) {
+	idx, maxD := -1, 0.0
+	for i := a + 1; i < b; i++ {
+		if d := segmentDistance(xyz[i], xyz[a], xyz[b]); idx < 0 || d > maxD {
+			idx, maxD = i, d
+		}
+	}
+	return idx, maxD
+}
+
+// segmentDistance is the distance from p to the segment a–b.
+func segmentDistance(p, a, b [3]float64) float64 {
+	var ab, ap [3]float64
+	var abLen2, dot float64
+	for k := range 3 {
+		ab[k], ap[k] = b[k]-a[k], p[k]-a[k]
+		abLen2 += ab[k] * ab[k]
+		dot += ab[k] * ap[k]
+	}
+	t := 0.0
+	if abLen2 > 0 {
+		t = math.Max(0, math.Min(1, dot/abLen2))
+	}
+	var d2 float64
+	for k := range 3 {
+		d := ap[k] - t*ab[k]
+		d2 += d * d
+	}
+	return math.Sqrt(d2)
+}
+
+func countKept(keep []bool) int {
+	n := 0
+	for _, k := range keep {
+		if k {
+			n++
+		}
+	}
+	return n
+}
+
+func keepPoints(points []TrackPoint, keep []bool) []TrackPoint {
+	out := make([]TrackPoint, 0, countKept(keep))
+	for i, p := range points {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"math"
+	"testing"
+)
+
+// line returns n points heading north about 11 m apart, with a sideways
+// kink of offset degrees of longitude at the middle point.
+func line(n int, offset float64) []TrackPoint {
+	points := make([]TrackPoint, n)
+	for i := range points {
+		points[i] = TrackPoint{Lat: 51.5 + float64(i)*1e-4, Lon: -0.1}
+	}
+	points[n/2].Lon += offset
+	return points
+}
+
+func TestSimplify(t *testing.T) {
+	straight := line(101, 0)
+	if got := Simplify(straight, 1); len(got) != 2 {
+		t.Errorf("straight line: %d points, want 2", len(got))
+	}
+
+	// 1e-4 degrees of longitude at 51.5° is about 6.9 m.
+	kinked := line(101, 1e-4)
+	// The spike keeps its neighbours, which lie off the lines to its tip.
+	got := Simplify(kinked, 1)
+	if len(got) != 5 || got[2] != kinked[50] {
+		t.Errorf("kinked line: %v, want endpoints and the spike", got)
+	}
+	if got := Simplify(kinked, 10); len(got) != 2 {
+		t.Errorf("kinked line at 10 m: %d points, want 2", len(got))
+	}
+	if got := Simplify(kinked, 0); len(got) != 101 {
+		t.Errorf("zero tolerance: %d points, want all 101", len(got))
+	}
+
+	// A climb on a straight ground track is kept.
+	climb := line(101, 0)
+	climb[50].Alt = 50
+	if got := Simplify(climb, 1); len(got) != 5 || got[2] != climb[50] {
+		t.Errorf("climb: %v, want endpoints and the climb", got)
+	}
+}
+
+func TestSimplifyTo(t *testing.T) {
+	// A zigzag keeps every point at low tolerances.
+	points := make([]TrackPoint, 1000)
+	for i := range points {
+		points[i] = TrackPoint{Lat: 51.5 + float64(i)*1e-4, Lon: -0.1 + math.Sin(float64(i)/10)*1e-3}
+	}
+	for _, n := range []int{1, 2, 50, 500, 999} {
+		got := SimplifyTo(points, n)
+		if len(got) > max(n, 2) || len(got) < 2 {
+			t.Errorf("SimplifyTo(%d) = %d points", n, len(got))
+		}
+		if got[0] != points[0] || got[len(got)-1] != points[999] {
+			t.Errorf("SimplifyTo(%d) dropped an endpoint", n)
+		}
+	}
+	if got := SimplifyTo(points, 2000); len(got) != 1000 {
+		t.Errorf("SimplifyTo above the length = %d points, want 1000", len(got))
+	}
+	// The search uses the budget rather than collapsing the track.
+	if got := SimplifyTo(points, 500); len(got) < 250 {
+		t.Errorf("SimplifyTo(500) = %d points, want close to 500", len(got))
+	}
+}
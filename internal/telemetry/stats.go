@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"fpv-ground-station/internal/ltm"
@@ -18,10 +17,29 @@ type Stats struct {
 	CRCErrors    int
 	DecodeErrors int
 	Total        int
+	Bytes        int
 	StartTime    time.Time
 
-	// Attitude receive rate counter (reset every second by perf ticker)
-	AttitudeRx atomic.Int64
+	// Input link state as reported by the source
+	Link LinkState
+
+	// Protocol is the telemetry protocol being decoded, once known.
+	Protocol string
+
+	window *slidingWindow // recent per-second counts
+	bursts burstTracker
+	lastX  uint8     // last X-frame counter
+	xTime  time.Time // when lastX was received
+	haveX  bool
+}
+
+// LinkState describes the input link: "connected", "reconnecting" or "lost".
+type LinkState struct {
+	State         string
+	Since         time.Time // when State was entered
+	LastConnected time.Time
+	Retries       int // failed reopen attempts since the link dropped
+	LastError     string
 }
 
 // NewStats creates a Stats tracker starting now.
@@ -29,6 +47,7 @@ func NewStats() *Stats {
 	return &Stats{
 		Frames:    make(map[byte]int),
 		StartTime: time.Now(),
+		window:    newSlidingWindow(RateWindowSeconds[len(RateWindowSeconds)-1] + 1),
 	}
 }
 
@@ -36,22 +55,60 @@ func NewStats() *Stats {
 func (s *Stats) Count(fn byte) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.count(fn, time.Now())
+}
+
+func (s *Stats) count(fn byte, now time.Time) {
 	s.Frames[fn]++
 	s.Total++
+	s.window.at(now).Frames[fn]++
 }
 
 // RecordCRCError increments the CRC error counter.
 func (s *Stats) RecordCRCError() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	now := time.Now()
 	s.CRCErrors++
+	s.window.at(now).CRCErrors++
+	s.bursts.record(now)
 }
 
 // RecordDecodeError increments the decode error counter.
 func (s *Stats) RecordDecodeError() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	now := time.Now()
 	s.DecodeErrors++
+	s.window.at(now).DecodeErrors++
+	s.bursts.record(now)
+}
+
+// SetLink records the current input link state. Since only moves when the
+// state itself changes, so repeated retries keep the time the link dropped.
+func (s *Stats) SetLink(state string, retries int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if state != s.Link.State {
+		s.Link.State = state
+		s.Link.Since = now
+	}
+	if state == "connected" {
+		s.Link.LastConnected = now
+		s.Link.LastError = ""
+	} else if err != nil {
+		s.Link.LastError = err.Error()
+	}
+	s.Link.Retries = retries
+}
+
+// SetProtocol records the telemetry protocol being decoded.
+func (s *Stats) SetProtocol(protocol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Protocol = protocol
 }
 
 // Uptime returns the duration since tracking started.
@@ -59,7 +116,8 @@ func (s *Stats) Uptime() time.Duration {
 	return time.Since(s.StartTime)
 }
 
-// FPS returns the average frames per second.
+// FPS returns the average frames per second since tracking started. For
+// the current rate use Snapshot.
 func (s *Stats) FPS() float64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -74,28 +132,59 @@ func (s *Stats) FPS() float64 {
 type StatsSnapshot struct {
 	UptimeSec    float64
 	Total        int
-	FPS          float64
+	Frames       map[string]int // since start, keyed by lower-case frame name
+	Bytes        int
+	FPS          float64 // over the shortest rate window
+	AvgFPS       float64 // since start
 	CRCErrors    int
 	DecodeErrors int
+	Link         LinkState
+	Protocol     string
+	LinkQuality  LinkQuality
+
+	Windows []RateWindow // one per RateWindowSeconds
+
+	ErrorBursts    int        // bursts since start
+	LastErrorBurst ErrorBurst // zero if none
+	InErrorBurst   bool       // LastErrorBurst is still in progress
 }
 
 // Snapshot returns a thread-safe copy of all stat counters.
 func (s *Stats) Snapshot() StatsSnapshot {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.snapshot(time.Now())
+}
 
-	sec := time.Since(s.StartTime).Seconds()
-	fps := 0.0
+func (s *Stats) snapshot(now time.Time) StatsSnapshot {
+	sec := now.Sub(s.StartTime).Seconds()
+	avg := 0.0
 	if sec > 0 {
-		fps = float64(s.Total) / sec
+		avg = float64(s.Total) / sec
 	}
-	return StatsSnapshot{
-		UptimeSec:    sec,
-		Total:        s.Total,
-		FPS:          fps,
-		CRCErrors:    s.CRCErrors,
-		DecodeErrors: s.DecodeErrors,
+	snap := StatsSnapshot{
+		UptimeSec:      sec,
+		Total:          s.Total,
+		Frames:         make(map[string]int, len(s.Frames)),
+		Bytes:          s.Bytes,
+		AvgFPS:         avg,
+		CRCErrors:      s.CRCErrors,
+		DecodeErrors:   s.DecodeErrors,
+		Link:           s.Link,
+		Protocol:       s.Protocol,
+		LinkQuality:    s.linkQuality(now),
+		ErrorBursts:    s.bursts.count,
+		LastErrorBurst: s.bursts.last,
+		InErrorBurst:   s.bursts.active(now),
 	}
+	for fn, count := range s.Frames {
+		snap.Frames[frameKey(fn)] = count
+	}
+	for _, n := range RateWindowSeconds {
+		snap.Windows = append(snap.Windows, s.rateWindow(now, n))
+	}
+	snap.FPS = snap.Windows[0].FPS
+	return snap
 }
 
 // Summary returns a human-readable summary of all metrics.
@@ -110,12 +199,29 @@ func (s *Stats) Summary() string {
 		fps = float64(s.Total) / sec
 	}
 
-	fmt.Fprintf(&b, "--- LTM Telemetry Stats ---\n")
+	header := "Telemetry Stats"
+	if s.Protocol != "" {
+		header = strings.ToUpper(s.Protocol) + " " + header
+	}
+	fmt.Fprintf(&b, "--- %s ---\n", header)
 	fmt.Fprintf(&b, "Uptime:        %.1fs\n", sec)
 	fmt.Fprintf(&b, "Total:         %d\n", s.Total)
 	fmt.Fprintf(&b, "FPS:           %.1f\n", fps)
+	fmt.Fprintf(&b, "Bytes:         %d\n", s.Bytes)
 	fmt.Fprintf(&b, "CRC Errors:    %d\n", s.CRCErrors)
 	fmt.Fprintf(&b, "Decode Errors: %d\n", s.DecodeErrors)
+	if s.bursts.count > 0 {
+		fmt.Fprintf(&b, "Error bursts:  %d (last had %d errors)\n", s.bursts.count, s.bursts.last.Errors)
+	}
+	if s.Link.State != "" {
+		fmt.Fprintf(&b, "Link:          %s\n", s.Link.State)
+	}
+	if s.Protocol != "" {
+		fmt.Fprintf(&b, "Protocol:      %s\n", s.Protocol)
+	}
+	if lq := s.linkQuality(time.Now()); lq.WindowSec > 0 {
+		fmt.Fprintf(&b, "Link quality:  %d%% (last %ds, %d X-frames lost)\n", lq.Quality, lq.WindowSec, lq.XLost)
+	}
 
 	if len(s.Frames) > 0 {
 		fmt.Fprintf(&b, "Frames:\n")
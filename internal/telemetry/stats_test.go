@@ -1,6 +1,7 @@
 package telemetry
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -53,7 +54,7 @@ func TestStats_Summary(t *testing.T) {
 	s.RecordCRCError()
 
 	summary := s.Summary()
-	if !strings.Contains(summary, "LTM Telemetry Stats") {
+	if !strings.HasPrefix(summary, "--- Telemetry Stats ---\n") {
 		t.Error("summary missing header")
 	}
 	if !strings.Contains(summary, "Total:         2") {
@@ -83,4 +84,58 @@ func TestStats_Snapshot(t *testing.T) {
 	if snap.DecodeErrors != 1 {
 		t.Errorf("decode errors = %d, want 1", snap.DecodeErrors)
 	}
+	if snap.Frames["attitude"] != 1 || len(snap.Frames) != 1 {
+		t.Errorf("frames = %v, want attitude: 1", snap.Frames)
+	}
+}
+
+func TestStats_SetLink(t *testing.T) {
+	s := NewStats()
+	s.SetLink("connected", 0, nil)
+	connectedAt := s.Snapshot().Link.Since
+
+	s.SetLink("reconnecting", 0, errors.New("port has been closed"))
+	droppedAt := s.Snapshot().Link.Since
+	s.SetLink("reconnecting", 3, errors.New("no such device"))
+
+	link := s.Snapshot().Link
+	if link.State != "reconnecting" {
+		t.Errorf("state = %q, want reconnecting", link.State)
+	}
+	if link.Retries != 3 {
+		t.Errorf("retries = %d, want 3", link.Retries)
+	}
+	if !link.Since.Equal(droppedAt) {
+		t.Error("since moved on a retry without a state change")
+	}
+	if link.LastError != "no such device" {
+		t.Errorf("last error = %q", link.LastError)
+	}
+	if !link.LastConnected.Equal(connectedAt) {
+		t.Error("last connected should keep the original connect time")
+	}
+
+	s.SetLink("connected", 4, nil)
+	link = s.Snapshot().Link
+	if link.LastError != "" {
+		t.Errorf("last error = %q, want cleared on reconnect", link.LastError)
+	}
+}
+
+func TestStats_SetProtocol(t *testing.T) {
+	s := NewStats()
+	if strings.Contains(s.Summary(), "Protocol:") {
+		t.Error("summary should omit protocol before detection")
+	}
+
+	s.SetProtocol("crsf")
+	if got := s.Snapshot().Protocol; got != "crsf" {
+		t.Errorf("protocol = %q, want crsf", got)
+	}
+	if !strings.HasPrefix(s.Summary(), "--- CRSF Telemetry Stats ---\n") {
+		t.Error("summary header does not name the protocol")
+	}
+	if !strings.Contains(s.Summary(), "Protocol:      crsf") {
+		t.Errorf("summary missing protocol:\n%s", s.Summary())
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"fpv-ground-station/internal/crsf"
 	"fpv-ground-station/internal/ltm"
 )
 
@@ -23,6 +24,21 @@ type Store struct {
 	NavTime      time.Time
 	Extra        *ltm.ExtraData
 	ExtraTime    time.Time
+
+	// CRSFLink holds radio link statistics; only set for CRSF input.
+	CRSFLink     *crsf.LinkStatistics
+	CRSFLinkTime time.Time
+
+	// Protocol is the telemetry protocol being decoded, once known. It
+	// selects the expected frame rates for freshness.
+	Protocol string
+
+	// Derived is recomputed whenever a GPS or origin frame arrives.
+	Derived *Derived
+	derive  deriver
+
+	history history
+	changed chan struct{} // closed by the next update; nil until Changed is called
 }
 
 // Snapshot is a point-in-time copy of telemetry state, safe to use without locks.
@@ -39,6 +55,14 @@ type Snapshot struct {
 	NavTime      time.Time
 	Extra        *ltm.ExtraData
 	ExtraTime    time.Time
+
+	// CRSFLink holds radio link statistics; only set for CRSF input.
+	CRSFLink     *crsf.LinkStatistics
+	CRSFLinkTime time.Time
+
+	Protocol string
+
+	Derived *Derived
 }
 
 // Update merges a decoded LTM frame into the store.
@@ -50,6 +74,7 @@ func (s *Store) Update(f ltm.Frame) {
 	case f.GPS != nil:
 		s.GPS = f.GPS
 		s.GPSTime = f.Time
+		s.Derived = s.derive.update(s.GPS, s.GPSTime, s.Origin)
 	case f.Attitude != nil:
 		s.Attitude = f.Attitude
 		s.AttitudeTime = f.Time
@@ -59,6 +84,9 @@ func (s *Store) Update(f ltm.Frame) {
 	case f.Origin != nil:
 		s.Origin = f.Origin
 		s.OriginTime = f.Time
+		if s.GPS != nil {
+			s.Derived = s.derive.update(s.GPS, s.GPSTime, s.Origin)
+		}
 	case f.Nav != nil:
 		s.Nav = f.Nav
 		s.NavTime = f.Time
@@ -66,6 +94,61 @@ func (s *Store) Update(f ltm.Frame) {
 		s.Extra = f.Extra
 		s.ExtraTime = f.Time
 	}
+	s.history.recordFrame(f, s.Derived)
+	s.notify()
+}
+
+// UpdateCRSFLink records the latest CRSF link statistics.
+func (s *Store) UpdateCRSFLink(l *crsf.LinkStatistics, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.CRSFLink = l
+	s.CRSFLinkTime = t
+	s.history.recordCRSFLink(l, t)
+	s.notify()
+}
+
+// SetProtocol records the telemetry protocol being decoded.
+func (s *Store) SetProtocol(protocol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Protocol = protocol
+}
+
+// Changed returns a channel that is closed by the next update, so a reader
+// can wait for new telemetry instead of polling. Call it again after each
+// notification for the next one.
+func (s *Store) Changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
+
+// notify wakes the readers waiting in Changed. Callers hold s.mu.
+func (s *Store) notify() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// SetHistoryWindow sets how far back the history buffer reaches, in whole
+// seconds, and discards the history recorded so far.
+func (s *Store) SetHistoryWindow(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.setWindow(d)
+}
+
+// History returns the recorded history selected by q as columnar series.
+func (s *Store) History(q HistoryQuery) (HistorySeries, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history.query(q)
 }
 
 // Snapshot returns a point-in-time copy of the current telemetry state.
@@ -86,5 +169,9 @@ func (s *Store) Snapshot() Snapshot {
 		NavTime:      s.NavTime,
 		Extra:        s.Extra,
 		ExtraTime:    s.ExtraTime,
+		CRSFLink:     s.CRSFLink,
+		CRSFLinkTime: s.CRSFLinkTime,
+		Protocol:     s.Protocol,
+		Derived:      s.Derived,
 	}
 }
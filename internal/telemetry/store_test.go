@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"fpv-ground-station/internal/crsf"
 	"fpv-ground-station/internal/ltm"
 )
 
@@ -72,6 +73,20 @@ func TestStore_Overwrite(t *testing.T) {
 	}
 }
 
+func TestStore_UpdateCRSFLink(t *testing.T) {
+	s := &Store{}
+	now := time.Now()
+	s.UpdateCRSFLink(&crsf.LinkStatistics{UplinkLQ: 87}, now)
+
+	snap := s.Snapshot()
+	if snap.CRSFLink == nil || snap.CRSFLink.UplinkLQ != 87 {
+		t.Fatalf("crsf link = %+v, want LQ 87", snap.CRSFLink)
+	}
+	if !snap.CRSFLinkTime.Equal(now) {
+		t.Error("crsf link time not recorded")
+	}
+}
+
 func TestStore_ConcurrentAccess(t *testing.T) {
 	s := &Store{}
 	var wg sync.WaitGroup
@@ -101,3 +116,31 @@ func TestStore_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 	// No race condition — test passes if no panic
 }
+
+func TestStore_Changed(t *testing.T) {
+	s := &Store{}
+	ch := s.Changed()
+	if s.Changed() != ch {
+		t.Fatal("Changed returned a new channel without an update")
+	}
+	select {
+	case <-ch:
+		t.Fatal("notified before any update")
+	default:
+	}
+
+	s.Update(ltm.Frame{Function: ltm.FuncAttitude, Time: time.Now(), Attitude: &ltm.AttitudeData{}})
+	select {
+	case <-ch:
+	default:
+		t.Fatal("not notified of a frame")
+	}
+
+	ch = s.Changed()
+	s.UpdateCRSFLink(&crsf.LinkStatistics{}, time.Now())
+	select {
+	case <-ch:
+	default:
+		t.Fatal("not notified of link statistics")
+	}
+}
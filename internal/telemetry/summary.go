@@ -0,0 +1,131 @@
+package telemetry
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"fpv-ground-station/internal/geo"
+	"fpv-ground-station/internal/ltm"
+)
+
+// minEfficiencyDistance is the distance below which mAh/km is not reported,
+// since hovering near home would give meaningless figures.
+const minEfficiencyDistance = 100 // m
+
+// FlightSummary aggregates a flight's telemetry.
+type FlightSummary struct {
+	DurationSec    float64 `json:"duration_sec"`
+	MaxAltitude    float64 `json:"max_altitude"`     // m above home
+	MaxDistance    float64 `json:"max_distance"`     // m from home
+	TotalDistance  float64 `json:"total_distance"`   // m flown
+	MaxGroundSpeed uint8   `json:"max_ground_speed"` // m/s
+	MAhUsed        int     `json:"mah_used"`
+	MinVoltage     float64 `json:"min_voltage"` // V, 0 if unknown
+	MinRSSI        uint8   `json:"min_rssi"`    // 0 if unknown
+	MAhPerKm       float64 `json:"mah_per_km"`  // 0 if too little distance
+	TrackPoints    int     `json:"track_points"`
+}
+
+// Summarizer accumulates a FlightSummary from a stream of decoded frames.
+// The zero value is ready to use.
+type Summarizer struct {
+	sum FlightSummary
+
+	start, last time.Time
+
+	haveHome         bool
+	homeLat, homeLon float64
+	havePos          bool
+	lastLat, lastLon float64
+
+	haveStatus bool
+	firstMAh   uint16
+}
+
+// Update folds f into the summary.
+func (s *Summarizer) Update(f ltm.Frame) {
+	if s.start.IsZero() {
+		s.start = f.Time
+	}
+	s.last = f.Time
+
+	switch {
+	case f.Origin != nil:
+		// The flight controller's home takes precedence over the first fix.
+		if f.Origin.Lat != 0 || f.Origin.Lon != 0 {
+			s.haveHome = true
+			s.homeLat, s.homeLon = f.Origin.Lat, f.Origin.Lon
+		}
+
+	case f.GPS != nil:
+		g := f.GPS
+		if g.Fix < 2 || (g.Lat == 0 && g.Lon == 0) {
+			return
+		}
+		s.sum.TrackPoints++
+		s.sum.MaxAltitude = max(s.sum.MaxAltitude, g.Altitude)
+		s.sum.MaxGroundSpeed = max(s.sum.MaxGroundSpeed, g.GroundSpeed)
+
+		if !s.haveHome {
+			s.haveHome = true
+			s.homeLat, s.homeLon = g.Lat, g.Lon
+		}
+		s.sum.MaxDistance = max(s.sum.MaxDistance, geo.Distance(s.homeLat, s.homeLon, g.Lat, g.Lon))
+
+		if s.havePos {
+			s.sum.TotalDistance += geo.Distance(s.lastLat, s.lastLon, g.Lat, g.Lon)
+		}
+		s.havePos = true
+		s.lastLat, s.lastLon = g.Lat, g.Lon
+
+	case f.Status != nil:
+		st := f.Status
+		if !s.haveStatus {
+			s.haveStatus = true
+			s.firstMAh = st.MAhDrawn
+		}
+		s.sum.MAhUsed = int(st.MAhDrawn) - int(s.firstMAh)
+		// RSSI 0 means the flight controller has no RSSI source.
+		if st.RSSI > 0 && (s.sum.MinRSSI == 0 || st.RSSI < s.sum.MinRSSI) {
+			s.sum.MinRSSI = st.RSSI
+		}
+		if st.Vbat > 0 && (s.sum.MinVoltage == 0 || st.Vbat < s.sum.MinVoltage) {
+			s.sum.MinVoltage = st.Vbat
+		}
+	}
+}
+
+// Summary returns the summary so far.
+func (s *Summarizer) Summary() FlightSummary {
+	sum := s.sum
+	if !s.start.IsZero() {
+		sum.DurationSec = s.last.Sub(s.start).Seconds()
+	}
+	if sum.TotalDistance >= minEfficiencyDistance && sum.MAhUsed > 0 {
+		sum.MAhPerKm = math.Round(float64(sum.MAhUsed)/(sum.TotalDistance/1000)*10) / 10
+	}
+	return sum
+}
+
+// String formats the summary for the console.
+func (s FlightSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Flight time:   %s\n", (time.Duration(s.DurationSec * float64(time.Second))).Round(time.Second))
+	fmt.Fprintf(&b, "Max altitude:  %.1f m\n", s.MaxAltitude)
+	fmt.Fprintf(&b, "Max distance:  %.0f m\n", s.MaxDistance)
+	fmt.Fprintf(&b, "Distance:      %.2f km\n", s.TotalDistance/1000)
+	fmt.Fprintf(&b, "Max speed:     %d m/s\n", s.MaxGroundSpeed)
+	fmt.Fprintf(&b, "mAh used:      %d\n", s.MAhUsed)
+	if s.MinVoltage > 0 {
+		fmt.Fprintf(&b, "Min voltage:   %.2f V\n", s.MinVoltage)
+	}
+	if s.MinRSSI > 0 {
+		fmt.Fprintf(&b, "Min RSSI:      %d\n", s.MinRSSI)
+	}
+	if s.MAhPerKm > 0 {
+		fmt.Fprintf(&b, "Efficiency:    %.0f mAh/km\n", s.MAhPerKm)
+	}
+	return b.String()
+}
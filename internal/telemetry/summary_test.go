@@ -0,0 +1,124 @@
+package telemetry
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+// 0.0009° of latitude is ~100 m.
+const latStep100m = 0.0009
+
+func TestSummarizer(t *testing.T) {
+	var s Summarizer
+	t0 := time.Now()
+	home := [2]float64{51.5, -0.1}
+
+	frames := []ltm.Frame{
+		{Time: t0, Origin: &ltm.OriginData{Lat: home[0], Lon: home[1]}},
+		{Time: t0, Status: &ltm.StatusData{Vbat: 16.8, MAhDrawn: 100, RSSI: 200}},
+		gpsFrame(t0.Add(10*time.Second), home[0], home[1], 0, 0),
+		gpsFrame(t0.Add(20*time.Second), home[0]+latStep100m, home[1], 30, 10),
+		gpsFrame(t0.Add(30*time.Second), home[0]+2*latStep100m, home[1], 55, 16),
+		gpsFrame(t0.Add(40*time.Second), home[0]+latStep100m, home[1], 20, 12),
+		{Time: t0.Add(40 * time.Second), Status: &ltm.StatusData{Vbat: 15.1, MAhDrawn: 250, RSSI: 90}},
+		{Time: t0.Add(50 * time.Second), Status: &ltm.StatusData{Vbat: 15.4, MAhDrawn: 400, RSSI: 150}},
+	}
+	for _, f := range frames {
+		s.Update(f)
+	}
+	sum := s.Summary()
+
+	if sum.DurationSec != 50 {
+		t.Errorf("duration = %v, want 50", sum.DurationSec)
+	}
+	if sum.MaxAltitude != 55 || sum.MaxGroundSpeed != 16 {
+		t.Errorf("max alt/speed = %v/%v, want 55/16", sum.MaxAltitude, sum.MaxGroundSpeed)
+	}
+	if math.Abs(sum.MaxDistance-200) > 1 {
+		t.Errorf("max distance = %.1f, want ~200", sum.MaxDistance)
+	}
+	if math.Abs(sum.TotalDistance-300) > 1 {
+		t.Errorf("total distance = %.1f, want ~300", sum.TotalDistance)
+	}
+	if sum.MAhUsed != 300 || sum.MinVoltage != 15.1 || sum.MinRSSI != 90 {
+		t.Errorf("battery = %+v", sum)
+	}
+	if math.Abs(sum.MAhPerKm-1000) > 5 {
+		t.Errorf("efficiency = %.1f mAh/km, want ~1000", sum.MAhPerKm)
+	}
+	if sum.TrackPoints != 4 {
+		t.Errorf("track points = %d, want 4", sum.TrackPoints)
+	}
+}
+
+func TestSummarizer_HomeFallsBackToFirstFix(t *testing.T) {
+	var s Summarizer
+	t0 := time.Now()
+	s.Update(gpsFrame(t0, 51.5, -0.1, 0, 0))
+	s.Update(gpsFrame(t0, 51.5+latStep100m, -0.1, 0, 0))
+
+	if math.Abs(s.Summary().MaxDistance-100) > 1 {
+		t.Errorf("max distance = %.1f, want ~100", s.Summary().MaxDistance)
+	}
+}
+
+func TestSummarizer_IgnoresNoFix(t *testing.T) {
+	var s Summarizer
+	t0 := time.Now()
+	s.Update(gpsFrame(t0, 51.5, -0.1, 0, 0))
+	s.Update(ltm.Frame{Time: t0, GPS: &ltm.GPSData{Lat: 40, Lon: 29, Fix: 0}})
+	s.Update(gpsFrame(t0, 51.5, -0.1, 0, 0))
+
+	sum := s.Summary()
+	if sum.TotalDistance != 0 || sum.MaxDistance != 0 {
+		t.Errorf("no-fix position counted: %+v", sum)
+	}
+	if sum.MAhPerKm != 0 {
+		t.Errorf("efficiency = %v, want 0 below minimum distance", sum.MAhPerKm)
+	}
+}
+
+func TestSummarizer_NoFixDoesNotSetMaxima(t *testing.T) {
+	var s Summarizer
+	t0 := time.Now()
+	s.Update(ltm.Frame{Time: t0, GPS: &ltm.GPSData{Altitude: 4000, GroundSpeed: 200, Fix: 0}})
+	s.Update(ltm.Frame{Time: t0, GPS: &ltm.GPSData{Altitude: 3000, GroundSpeed: 150, Fix: 3}}) // no position
+	s.Update(gpsFrame(t0, 51.5, -0.1, 40, 12))
+
+	sum := s.Summary()
+	if sum.MaxAltitude != 40 || sum.MaxGroundSpeed != 12 {
+		t.Errorf("max alt/speed = %v/%v, want 40/12 from the fixed sample only", sum.MaxAltitude, sum.MaxGroundSpeed)
+	}
+}
+
+func TestSummarizer_MinRSSISkipsZero(t *testing.T) {
+	var s Summarizer
+	t0 := time.Now()
+	s.Update(ltm.Frame{Time: t0, Status: &ltm.StatusData{RSSI: 0}})
+	if got := s.Summary().MinRSSI; got != 0 {
+		t.Errorf("min RSSI = %d, want 0 without an RSSI source", got)
+	}
+	if strings.Contains(s.Summary().String(), "Min RSSI") {
+		t.Error("summary prints min RSSI without an RSSI source")
+	}
+
+	s.Update(ltm.Frame{Time: t0, Status: &ltm.StatusData{RSSI: 120}})
+	s.Update(ltm.Frame{Time: t0, Status: &ltm.StatusData{RSSI: 0}})
+	s.Update(ltm.Frame{Time: t0, Status: &ltm.StatusData{RSSI: 80}})
+	if got := s.Summary().MinRSSI; got != 80 {
+		t.Errorf("min RSSI = %d, want 80", got)
+	}
+}
+
+func TestFlightSummary_String(t *testing.T) {
+	out := FlightSummary{DurationSec: 312, MaxAltitude: 61.5, MinVoltage: 14.2, MAhPerKm: 350}.String()
+	for _, want := range []string{"Flight time:   5m12s", "Max altitude:  61.5 m", "Min voltage:   14.20 V", "Efficiency:    350 mAh/km"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
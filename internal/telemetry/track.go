@@ -3,61 +3,259 @@ package telemetry
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-// TrackLog persists GPS coordinates to a CSV file (lat,lon per line).
+// TrackVersion is the current track file record format.
+//
+// Version 1 files hold one "lat,lon" pair per line. Version 2 files start
+// with trackHeader and hold one full TrackPoint per line. Readers accept
+// both, including version 1 files that later had version 2 records
+// appended.
+const TrackVersion = 2
+
+const trackHeader = "# fpv-ground-station track v2: time,lat,lon,alt,speed,heading,sats,fix,vbat"
+
+// trackTimeFormat is RFC 3339 with millisecond precision.
+const trackTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// TrackPoint is one recorded GPS position with the state around it.
+// Points read from version 1 records only have Lat and Lon set.
+type TrackPoint struct {
+	Time    time.Time `json:"time"`
+	Lat     float64   `json:"lat"`
+	Lon     float64   `json:"lon"`
+	Alt     float64   `json:"alt"`     // m, GPS altitude relative to home
+	Speed   float64   `json:"speed"`   // m/s, ground speed
+	Heading float64   `json:"heading"` // degrees, from the attitude frame
+	Sats    uint8     `json:"sats"`
+	Fix     uint8     `json:"fix"`
+	Vbat    float64   `json:"vbat"` // V
+}
+
+// TrackPoint builds a track point from the latest GPS frame and the
+// attitude and status around it. It reports false without a GPS position.
+func (s Snapshot) TrackPoint() (TrackPoint, bool) {
+	if s.GPS == nil || s.GPS.Lat == 0 {
+		return TrackPoint{}, false
+	}
+	p := TrackPoint{
+		Time:  s.GPSTime,
+		Lat:   s.GPS.Lat,
+		Lon:   s.GPS.Lon,
+		Alt:   s.GPS.Altitude,
+		Speed: float64(s.GPS.GroundSpeed),
+		Sats:  s.GPS.Sats,
+		Fix:   s.GPS.Fix,
+	}
+	if s.Attitude != nil {
+		p.Heading = float64(s.Attitude.Heading)
+	}
+	if s.Status != nil {
+		p.Vbat = s.Status.Vbat
+	}
+	return p, true
+}
+
+func (p TrackPoint) record() string {
+	return fmt.Sprintf("%s,%.7f,%.7f,%.1f,%.1f,%.0f,%d,%d,%.2f",
+		p.Time.UTC().Format(trackTimeFormat), p.Lat, p.Lon, p.Alt, p.Speed, p.Heading, p.Sats, p.Fix, p.Vbat)
+}
+
+// parseTrackRecord parses a version 1 or version 2 record.
+func parseTrackRecord(line string) (TrackPoint, error) {
+	f := strings.Split(line, ",")
+	if len(f) != 2 && len(f) != 9 {
+		return TrackPoint{}, fmt.Errorf("track record has %d fields, want 2 or 9", len(f))
+	}
+
+	var t time.Time
+	if len(f) == 9 {
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, f[0]); err != nil {
+			return TrackPoint{}, err
+		}
+		f = f[1:]
+	}
+	v := make([]float64, len(f))
+	for i, s := range f {
+		var err error
+		if v[i], err = strconv.ParseFloat(s, 64); err != nil {
+			return TrackPoint{}, err
+		}
+	}
+
+	p := TrackPoint{Time: t, Lat: v[0], Lon: v[1]}
+	if len(v) == 8 {
+		p.Alt, p.Speed, p.Heading = v[2], v[3], v[4]
+		p.Sats, p.Fix, p.Vbat = uint8(v[5]), uint8(v[6]), v[7]
+	}
+	return p, nil
+}
+
+// TrackLog persists GPS track points to CSV files. A log opened with
+// NewTrackLog appends to a single file; one opened with OpenTrackDir writes
+// a file per session into a directory and prunes old files.
 type TrackLog struct {
 	mu   sync.Mutex
+	dir  string // empty for a single-file log
+	opts TrackOptions
 	path string
 	file *os.File
+	size int64
+
+	filter trackFilter
+
+	lastPrune time.Time
+	now       func() time.Time
 }
 
-// NewTrackLog opens or creates the track file in append mode.
+// NewTrackLog opens or creates the track file in append mode. A new file
+// gets a version header; an existing file is appended to as it is.
 func NewTrackLog(path string) (*TrackLog, error) {
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
-	if err != nil {
+	t := &TrackLog{now: time.Now}
+	if err := t.open(path, os.O_CREATE|os.O_RDWR|os.O_APPEND); err != nil {
 		return nil, err
 	}
-	return &TrackLog{path: path, file: f}, nil
+	return t, nil
 }
 
-// Append writes a single coordinate to the track file.
-func (t *TrackLog) Append(lat, lon float64) error {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	_, err := fmt.Fprintf(t.file, "%.7f,%.7f\n", lat, lon)
+// open makes path the current track file, writing the header if it is
+// empty. On error the previous file stays current.
+func (t *TrackLog) open(path string, flag int) error {
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	prevPath, prevFile, prevSize := t.path, t.file, t.size
+	t.path, t.file, t.size = path, f, info.Size()
+	if t.size == 0 {
+		if err := t.writeHeader(); err != nil {
+			f.Close()
+			t.path, t.file, t.size = prevPath, prevFile, prevSize
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TrackLog) writeHeader() error {
+	n, err := fmt.Fprintln(t.file, trackHeader)
+	t.size += int64(n)
 	return err
 }
 
-// ReadAll reads all coordinates from the track file.
-func (t *TrackLog) ReadAll() ([][2]float64, error) {
+// Append writes a single point to the track file, unless the log's
+// options filter it out. In a track directory the file is rotated once it
+// reaches its share of the size limit.
+func (t *TrackLog) Append(p TrackPoint) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if !t.filter.accept(p) {
+		return nil
+	}
+	n, err := fmt.Fprintln(t.file, p.record())
+	t.size += int64(n)
+	if err != nil || t.dir == "" {
+		return err
+	}
 
-	f, err := os.Open(t.path)
-	if err != nil {
-		return nil, err
+	if t.opts.MaxBytes > 0 && t.size >= t.opts.MaxBytes/trackFilesPerLimit {
+		return t.rotate()
 	}
-	defer f.Close()
+	if now := t.now(); now.Sub(t.lastPrune) >= trackPruneInterval {
+		return t.prune(now)
+	}
+	return nil
+}
+
+// ReadPoints reads all points from the track, skipping comment and
+// malformed lines.
+func (t *TrackLog) ReadPoints() ([]TrackPoint, error) {
+	return t.Query(TrackQuery{})
+}
+
+// ReadTrackFile reads all points from a track file without opening it for
+// writing, skipping comment and malformed lines.
+func ReadTrackFile(path string) ([]TrackPoint, error) {
+	var s trackScan
+	_, err := s.file(path)
+	return s.points, err
+}
 
-	var points [][2]float64
-	sc := bufio.NewScanner(f)
+// scanTrack calls fn for every record in r until it returns false.
+func scanTrack(r io.Reader, fn func(TrackPoint) bool) error {
+	sc := bufio.NewScanner(r)
 	for sc.Scan() {
-		var lat, lon float64
-		if _, err := fmt.Sscanf(sc.Text(), "%f,%f", &lat, &lon); err == nil {
-			points = append(points, [2]float64{lat, lon})
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		if p, err := parseTrackRecord(line); err == nil && !fn(p) {
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// ReadAll reads all coordinates from the track as lat,lon pairs.
+func (t *TrackLog) ReadAll() ([][2]float64, error) {
+	points, err := t.ReadPoints()
+	return Coords(points), err
+}
+
+// Coords returns the lat,lon pairs of points.
+func Coords(points []TrackPoint) [][2]float64 {
+	coords := make([][2]float64, len(points))
+	for i, p := range points {
+		coords[i] = [2]float64{p.Lat, p.Lon}
 	}
-	return points, sc.Err()
+	return coords
 }
 
-// Clear truncates the track file.
+// Clear deletes the recorded track. A single-file log is truncated to the
+// version header; a track directory loses all its files and starts a new
+// one.
 func (t *TrackLog) Clear() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.file.Truncate(0)
+	t.filter.reset()
+	if t.dir == "" {
+		if err := t.file.Truncate(0); err != nil {
+			return err
+		}
+		t.size = 0
+		return t.writeHeader()
+	}
+
+	files, err := t.files()
+	if err != nil {
+		return err
+	}
+	// The new file is created before anything is closed or removed, so a
+	// failure at any step leaves the log with a file to append to.
+	old := t.file
+	if err := t.create(); err != nil {
+		return err
+	}
+	old.Close()
+	var rmErr error
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) && rmErr == nil {
+			rmErr = err
+		}
+	}
+	return rmErr
 }
 
 // Close closes the track file.
@@ -0,0 +1,127 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fpv-ground-station/internal/ltm"
+)
+
+func TestTrackLog_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.csv")
+	tl, err := NewTrackLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	want := TrackPoint{
+		Time: time.Date(2026, 5, 1, 12, 30, 15, 250e6, time.UTC),
+		Lat:  51.5012345, Lon: -0.1276543, Alt: 42.5, Speed: 12,
+		Heading: 270, Sats: 14, Fix: 3, Vbat: 15.84,
+	}
+	if err := tl.Append(want); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := tl.ReadPoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("points = %+v, want 1", points)
+	}
+	got := points[0]
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("time = %v, want %v", got.Time, want.Time)
+	}
+	got.Time = want.Time
+	if got != want {
+		t.Errorf("point = %+v, want %+v", got, want)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "# fpv-ground-station track v2") {
+		t.Errorf("file should start with the version header:\n%s", data)
+	}
+}
+
+func TestTrackLog_ReadsVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.csv")
+	legacy := "51.5000000,-0.1278000\n51.5001000,-0.1277000\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tl, err := NewTrackLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+	tl.Append(TrackPoint{Time: time.Now(), Lat: 51.5002, Lon: -0.1276, Fix: 3})
+
+	points, err := tl.ReadPoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("points = %d, want 2 legacy + 1 new", len(points))
+	}
+	if points[1].Lat != 51.5001 || !points[1].Time.IsZero() || points[1].Fix != 0 {
+		t.Errorf("legacy point = %+v, want lat/lon only", points[1])
+	}
+	if points[2].Fix != 3 || points[2].Time.IsZero() {
+		t.Errorf("new point = %+v", points[2])
+	}
+
+	coords, _ := tl.ReadAll()
+	if len(coords) != 3 || coords[0] != [2]float64{51.5, -0.1278} {
+		t.Errorf("coords = %v", coords)
+	}
+}
+
+func TestTrackLog_ClearKeepsHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.csv")
+	tl, err := NewTrackLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	tl.Append(TrackPoint{Lat: 1, Lon: 2})
+	if err := tl.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	tl.Append(TrackPoint{Lat: 3, Lon: 4})
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != trackHeader || !strings.Contains(lines[1], ",3.0000000,4.0000000,") {
+		t.Errorf("file after clear =\n%s", data)
+	}
+}
+
+func TestSnapshot_TrackPoint(t *testing.T) {
+	now := time.Now()
+	if _, ok := (Snapshot{}).TrackPoint(); ok {
+		t.Error("track point without GPS")
+	}
+
+	snap := Snapshot{
+		GPS:      &ltm.GPSData{Lat: 51.5, Lon: -0.1, Altitude: 30, GroundSpeed: 9, Fix: 3, Sats: 11},
+		GPSTime:  now,
+		Attitude: &ltm.AttitudeData{Heading: 95},
+		Status:   &ltm.StatusData{Vbat: 16.1},
+	}
+	p, ok := snap.TrackPoint()
+	if !ok {
+		t.Fatal("no track point from GPS snapshot")
+	}
+	want := TrackPoint{Time: now, Lat: 51.5, Lon: -0.1, Alt: 30, Speed: 9, Heading: 95, Sats: 11, Fix: 3, Vbat: 16.1}
+	if p != want {
+		t.Errorf("point = %+v, want %+v", p, want)
+	}
+}
@@ -0,0 +1,367 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trackFilesPerLimit splits the MaxBytes budget of a track directory: the
+// current file is rotated once it reaches MaxBytes/trackFilesPerLimit, so a
+// single long session cannot grow past the limit and the oldest part of it
+// can be pruned like any other file.
+const trackFilesPerLimit = 4
+
+// trackPruneInterval is how often Append re-applies retention to a track
+// directory between rotations.
+const trackPruneInterval = time.Minute
+
+// trackFileTime names session files after their start time in UTC, which
+// keeps name order chronological.
+const trackFileTime = "20060102-150405.000"
+
+// TrackOptions bound the disk space used by a track directory and filter
+// the points written to it. Zero values disable the corresponding limit.
+type TrackOptions struct {
+	MaxBytes int64         // total size of all track files
+	MaxAge   time.Duration // age of a file's last write
+
+	// MinDistance drops points closer than this many metres (in 3D) to the
+	// last recorded one, so a parked aircraft doesn't fill the log.
+	MinDistance float64
+	// MaxSpeed drops points implying a faster move than this many m/s from
+	// the last recorded one. After a few such jumps in a row the new
+	// position is accepted.
+	MaxSpeed float64
+	// MinFix drops points with a worse GPS fix type (2 = 2D).
+	MinFix uint8
+}
+
+// legacyTrackName is the name a single-file track log from an earlier
+// version gets in a track directory. It sorts before every session file, so
+// its points are read as the oldest.
+const legacyTrackName = "track-00000000-000000.000.csv"
+
+// ImportTrackFile moves the single-file track log at path, as written by
+// earlier versions, into the track directory dir so its points are still
+// served and exported. It reports whether a file was moved; a missing or
+// empty file is left alone.
+func ImportTrackFile(path, dir string) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return false, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+	dst := filepath.Join(dir, legacyTrackName)
+	if _, err := os.Stat(dst); err == nil {
+		return false, fmt.Errorf("import %s: %s already exists", path, dst)
+	}
+	if err := os.Rename(path, dst); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// OpenTrackDir creates dir if needed and starts a new session file in it,
+// named track-<UTC start time>.csv. A track.csv left in dir by an earlier
+// version is imported first. Files older than opts.MaxAge, and the oldest
+// files while the directory exceeds opts.MaxBytes, are deleted; the current
+// file never is.
+func OpenTrackDir(dir string, opts TrackOptions) (*TrackLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if _, err := ImportTrackFile(filepath.Join(dir, "track.csv"), dir); err != nil {
+		return nil, err
+	}
+	t := &TrackLog{dir: dir, opts: opts, filter: newTrackFilter(opts), now: time.Now}
+	if err := t.create(); err != nil {
+		return nil, err
+	}
+	if err := t.prune(t.now()); err != nil {
+		t.file.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Dir returns the track directory, or "" for a single-file log.
+func (t *TrackLog) Dir() string {
+	return t.dir
+}
+
+// Rotate closes the current file of a track directory and starts a new
+// session file, unless the current one holds no points yet. It is a no-op
+// for a single-file log.
+func (t *TrackLog) Rotate() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dir == "" {
+		return nil
+	}
+	return t.rotate()
+}
+
+func (t *TrackLog) rotate() error {
+	if t.size <= int64(len(trackHeader)+1) {
+		return nil
+	}
+	old := t.file
+	if err := t.create(); err != nil {
+		return err
+	}
+	if err := old.Close(); err != nil {
+		return err
+	}
+	return t.prune(t.now())
+}
+
+// create opens a new session file, stepping the name forward a millisecond
+// at a time if one with the same start time exists.
+func (t *TrackLog) create() error {
+	ts := t.now().UTC()
+	for {
+		path := filepath.Join(t.dir, "track-"+ts.Format(trackFileTime)+".csv")
+		err := t.open(path, os.O_CREATE|os.O_EXCL|os.O_RDWR|os.O_APPEND)
+		if !os.IsExist(err) {
+			return err
+		}
+		ts = ts.Add(time.Millisecond)
+	}
+}
+
+// prune applies the retention limits to every file but the current one.
+func (t *TrackLog) prune(now time.Time) error {
+	t.lastPrune = now
+	files, err := t.files()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	keep := files[:0]
+	for _, f := range files {
+		if f.path != t.path && t.opts.MaxAge > 0 && now.Sub(f.modTime) > t.opts.MaxAge {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		total += f.size
+		keep = append(keep, f)
+	}
+	for _, f := range keep {
+		if t.opts.MaxBytes <= 0 || total <= t.opts.MaxBytes {
+			break
+		}
+		if f.path == t.path {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+func (t *TrackLog) files() ([]trackFile, error) {
+	return trackFiles(t.dir)
+}
+
+type trackFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// trackFiles lists the .csv files in dir in name order, which for session
+// files is oldest first.
+func trackFiles(dir string) ([]trackFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []trackFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".csv") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // removed since ReadDir
+		}
+		files = append(files, trackFile{
+			path:    filepath.Join(dir, e.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// TrackQuery selects points from a track. Points are filtered by time,
+// then decimated, then limited, then simplified.
+type TrackQuery struct {
+	Since    time.Time // zero for no lower bound
+	Until    time.Time // zero for no upper bound
+	Limit    int       // maximum number of points; 0 for no limit
+	Decimate int       // keep every Nth matching point; 0 or 1 keeps all
+
+	// Tolerance simplifies the result with Douglas–Peucker, dropping points
+	// within this many metres of the simplified line.
+	Tolerance float64
+	// MaxPoints simplifies a result with more points than this with the
+	// smallest tolerance that fits; 0 for no limit.
+	MaxPoints int
+
+	// Current reads only the session file being written, leaving older
+	// files in a track directory alone.
+	Current bool
+}
+
+// simplify applies the query's tolerance and point budget.
+func (q TrackQuery) simplify(points []TrackPoint) []TrackPoint {
+	points = Simplify(points, q.Tolerance)
+	if q.MaxPoints > 0 {
+		points = SimplifyTo(points, q.MaxPoints)
+	}
+	return points
+}
+
+// bounded reports whether the query has a time range. Points without a
+// timestamp (version 1 records) only match unbounded queries.
+func (q TrackQuery) bounded() bool {
+	return !q.Since.IsZero() || !q.Until.IsZero()
+}
+
+// Query reads the points selected by q, oldest first. Reading stops as soon
+// as the limit is reached or a point is past q.Until, and track directory
+// files last written before q.Since are not opened at all. Files are read
+// without holding the log's lock, so Append is not held up by a long query.
+func (t *TrackLog) Query(q TrackQuery) ([]TrackPoint, error) {
+	t.mu.Lock()
+	dir, path := t.dir, t.path
+	t.mu.Unlock()
+
+	if dir == "" || q.Current {
+		s := trackScan{q: q}
+		_, err := s.file(path)
+		if dir != "" && os.IsNotExist(err) {
+			err = nil // rotated and pruned since the lock was released
+		}
+		return q.simplify(s.points), err
+	}
+	points, err := queryTrackDir(dir, q)
+	return q.simplify(points), err
+}
+
+// QueryTrack reads the points selected by q from a track file, or from the
+// track files in a directory, without opening any of them for writing.
+func QueryTrack(path string, q TrackQuery) ([]TrackPoint, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		s := trackScan{q: q}
+		_, err := s.file(path)
+		return q.simplify(s.points), err
+	}
+	points, err := queryTrackDir(path, q)
+	return q.simplify(points), err
+}
+
+// LatestTrackFile returns the newest session file in dir holding at least
+// one point, or "" if there is none.
+func LatestTrackFile(dir string) (string, error) {
+	files, err := trackFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		s := trackScan{q: TrackQuery{Limit: 1}}
+		if _, err := s.file(files[i].path); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if len(s.points) > 0 {
+			return files[i].path, nil
+		}
+	}
+	return "", nil
+}
+
+func queryTrackDir(dir string, q TrackQuery) ([]TrackPoint, error) {
+	files, err := trackFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := trackScan{q: q}
+	for _, f := range files {
+		if !q.Since.IsZero() && f.modTime.Before(q.Since) {
+			continue
+		}
+		more, err := s.file(f.path)
+		if err != nil && !os.IsNotExist(err) {
+			return s.points, err
+		}
+		if !more {
+			break
+		}
+	}
+	return s.points, nil
+}
+
+// trackScan applies a query across one or more track files.
+type trackScan struct {
+	q       TrackQuery
+	matched int
+	points  []TrackPoint
+}
+
+// file scans one track file. It reports false once the query is satisfied
+// and later files need not be read.
+func (s *trackScan) file(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return true, err
+	}
+	defer f.Close()
+	more := true
+	err = scanTrack(f, func(p TrackPoint) bool {
+		more = s.add(p)
+		return more
+	})
+	return more, err
+}
+
+// add applies the query to p and reports whether scanning should go on.
+func (s *trackScan) add(p TrackPoint) bool {
+	q := s.q
+	if q.bounded() {
+		if p.Time.IsZero() || (!q.Since.IsZero() && p.Time.Before(q.Since)) {
+			return true
+		}
+		if !q.Until.IsZero() && p.Time.After(q.Until) {
+			return false
+		}
+	}
+	s.matched++
+	if q.Decimate > 1 && (s.matched-1)%q.Decimate != 0 {
+		return true
+	}
+	s.points = append(s.points, p)
+	return q.Limit <= 0 || len(s.points) < q.Limit
+}
@@ -0,0 +1,271 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func trackPoints(t0 time.Time, n int) []TrackPoint {
+	points := make([]TrackPoint, n)
+	for i := range points {
+		points[i] = TrackPoint{Time: t0.Add(time.Duration(i) * time.Second), Lat: 51.5 + float64(i)*1e-4, Lon: -0.1, Fix: 3}
+	}
+	return points
+}
+
+func TestTrackDir_SessionFiles(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := OpenTrackDir(dir, TrackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	// Rotating a file without points keeps it.
+	if err := tl.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := trackFiles(dir); len(files) != 1 {
+		t.Fatalf("files after empty rotate = %d, want 1", len(files))
+	}
+
+	points := trackPoints(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), 6)
+	for _, p := range points[:3] {
+		tl.Append(p)
+	}
+	if err := tl.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range points[3:] {
+		tl.Append(p)
+	}
+
+	files, _ := trackFiles(dir)
+	if len(files) != 2 {
+		t.Fatalf("files = %d, want 2", len(files))
+	}
+	for _, f := range files {
+		if name := filepath.Base(f.path); !strings.HasPrefix(name, "track-") {
+			t.Errorf("file name = %q", name)
+		}
+	}
+
+	got, err := tl.ReadPoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 6 || !got[0].Time.Equal(points[0].Time) || !got[5].Time.Equal(points[5].Time) {
+		t.Errorf("points = %+v, want all 6 in order", got)
+	}
+
+	if err := tl.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	tl.Append(points[0])
+	files, _ = trackFiles(dir)
+	got, _ = tl.ReadPoints()
+	if len(files) != 1 || len(got) != 1 {
+		t.Errorf("after clear: %d files, %d points, want 1 and 1", len(files), len(got))
+	}
+}
+
+func TestTrackLog_Query(t *testing.T) {
+	tl, err := NewTrackLog(filepath.Join(t.TempDir(), "track.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tl.Append(TrackPoint{Lat: 1, Lon: 1}) // no timestamp
+	for _, p := range trackPoints(t0, 10) {
+		tl.Append(p)
+	}
+
+	tests := []struct {
+		name  string
+		q     TrackQuery
+		first time.Duration // offset of the first point from t0
+		n     int
+	}{
+		{"all", TrackQuery{}, -1, 11},
+		{"since", TrackQuery{Since: t0.Add(7 * time.Second)}, 7 * time.Second, 3},
+		{"until", TrackQuery{Until: t0.Add(2 * time.Second)}, 0, 3},
+		{"range", TrackQuery{Since: t0.Add(2 * time.Second), Until: t0.Add(5 * time.Second)}, 2 * time.Second, 4},
+		{"limit", TrackQuery{Since: t0.Add(4 * time.Second), Limit: 2}, 4 * time.Second, 2},
+		{"decimate", TrackQuery{Since: t0, Decimate: 3}, 0, 4},
+		{"decimate and limit", TrackQuery{Since: t0, Decimate: 3, Limit: 2}, 0, 2},
+	}
+	for _, tt := range tests {
+		got, err := tl.Query(tt.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.n {
+			t.Errorf("%s: %d points, want %d", tt.name, len(got), tt.n)
+			continue
+		}
+		if tt.first >= 0 && !got[0].Time.Equal(t0.Add(tt.first)) {
+			t.Errorf("%s: first point at %v, want %v", tt.name, got[0].Time, t0.Add(tt.first))
+		}
+	}
+
+	got, _ := tl.Query(TrackQuery{Since: t0, Decimate: 3})
+	if !got[1].Time.Equal(t0.Add(3 * time.Second)) {
+		t.Errorf("decimated second point at %v, want t0+3s", got[1].Time)
+	}
+}
+
+func TestTrackDir_Retention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "track-20200101-000000.000.csv")
+	big := filepath.Join(dir, "track-20260101-000000.000.csv")
+	recent := filepath.Join(dir, "track-20260102-000000.000.csv")
+	os.WriteFile(old, []byte(trackHeader+"\n"), 0o644)
+	os.WriteFile(big, make([]byte, 700), 0o644)
+	os.WriteFile(recent, make([]byte, 300), 0o644)
+	os.Chtimes(old, time.Now(), time.Now().Add(-48*time.Hour))
+
+	tl, err := OpenTrackDir(dir, TrackOptions{MaxBytes: 1000, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	for _, path := range []string{old, big} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not pruned", filepath.Base(path))
+		}
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent file pruned: %v", err)
+	}
+
+	// The current file rotates at a quarter of the size limit and is never
+	// pruned itself.
+	for _, p := range trackPoints(time.Now(), 10) {
+		tl.Append(p)
+	}
+	files, _ := trackFiles(dir)
+	var total int64
+	for _, f := range files {
+		total += f.size
+		if f.path == recent {
+			t.Error("recent file kept past the size limit")
+		}
+	}
+	if total > 1000 {
+		t.Errorf("directory size = %d, want <= 1000", total)
+	}
+	if _, err := os.Stat(tl.path); err != nil {
+		t.Errorf("current file pruned: %v", err)
+	}
+}
+
+func TestTrackDir_QueryCurrent(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "track-20200101-000000.000.csv")
+	old := trackPoints(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 3)
+	var data string
+	for _, p := range old {
+		data += p.record() + "\n"
+	}
+	os.WriteFile(older, []byte(trackHeader+"\n"+data), 0o644)
+
+	tl, err := OpenTrackDir(dir, TrackOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+	for _, p := range trackPoints(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), 2) {
+		tl.Append(p)
+	}
+
+	got, err := tl.Query(TrackQuery{Current: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Time.Year() != 2026 {
+		t.Errorf("current points = %+v, want only the 2 new ones", got)
+	}
+	if got, _ := tl.Query(TrackQuery{}); len(got) != 5 {
+		t.Errorf("all points = %d, want 5", len(got))
+	}
+}
+
+func TestTrackDir_ImportsLegacyFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tracks")
+	legacy := filepath.Join(root, "track.csv")
+	os.WriteFile(legacy, []byte("1.0,2.0\n3.0,4.0\n"), 0o644)
+
+	moved, err := ImportTrackFile(legacy, dir)
+	if err != nil || !moved {
+		t.Fatalf("ImportTrackFile = %v, %v, want moved", moved, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("legacy file left in place")
+	}
+	if moved, _ := ImportTrackFile(legacy, dir); moved {
+		t.Error("second import moved a missing file")
+	}
+
+	// A track.csv placed in the directory itself is imported on open and
+	// read before the session files.
+	dir2 := filepath.Join(root, "tracks2")
+	os.MkdirAll(dir2, 0o755)
+	os.WriteFile(filepath.Join(dir2, "track-20260101-000000.000.csv"), []byte(trackHeader+"\n"+
+		TrackPoint{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Lat: 9, Lon: 9}.record()+"\n"), 0o644)
+	os.WriteFile(filepath.Join(dir2, "track.csv"), []byte("5.0,6.0\n"), 0o644)
+
+	for _, d := range []string{dir, dir2} {
+		tl, err := OpenTrackDir(d, TrackOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tl.ReadPoints()
+		tl.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) == 0 || (got[0].Lat != 1 && got[0].Lat != 5) {
+			t.Errorf("%s: points = %+v, want the legacy points first", filepath.Base(d), got)
+		}
+	}
+}
+
+func TestLatestTrackFileAndQueryTrack(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	write := func(name string, points []TrackPoint) string {
+		data := trackHeader + "\n"
+		for _, p := range points {
+			data += p.record() + "\n"
+		}
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(data), 0o644)
+		return path
+	}
+	write("track-20260501-120000.000.csv", trackPoints(t0, 3))
+	second := write("track-20260501-130000.000.csv", trackPoints(t0.Add(time.Hour), 2))
+	write("track-20260501-140000.000.csv", nil) // started, never got a fix
+
+	latest, err := LatestTrackFile(dir)
+	if err != nil || latest != second {
+		t.Fatalf("LatestTrackFile = %q, %v, want %q", latest, err, second)
+	}
+	if got, _ := QueryTrack(latest, TrackQuery{}); len(got) != 2 {
+		t.Errorf("latest session points = %d, want 2", len(got))
+	}
+	got, err := QueryTrack(dir, TrackQuery{Since: t0, Until: t0.Add(time.Minute)})
+	if err != nil || len(got) != 3 {
+		t.Errorf("range points = %d, %v, want the 3 of the first session", len(got), err)
+	}
+
+	if latest, _ := LatestTrackFile(t.TempDir()); latest != "" {
+		t.Errorf("LatestTrackFile of an empty dir = %q", latest)
+	}
+}
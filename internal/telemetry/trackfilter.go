@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"math"
+	"time"
+
+	"fpv-ground-station/internal/geo"
+)
+
+// trackJumpResync is how many jumps in a row are rejected before the
+// filter accepts the new position as genuine. It keeps one bad fix from
+// stalling the track for the rest of the session.
+const trackJumpResync = 5
+
+// trackFilter drops track points that add nothing or cannot be real.
+type trackFilter struct {
+	minDistance float64 // m
+	maxSpeed    float64 // m/s
+	minFix      uint8
+
+	last  TrackPoint
+	have  bool
+	jumps int
+}
+
+func newTrackFilter(opts TrackOptions) trackFilter {
+	return trackFilter{minDistance: opts.MinDistance, maxSpeed: opts.MaxSpeed, minFix: opts.MinFix}
+}
+
+// accept reports whether p should be recorded, and if so makes it the
+// reference for the next point.
+func (f *trackFilter) accept(p TrackPoint) bool {
+	if p.Fix < f.minFix {
+		return false
+	}
+	if !f.have {
+		f.last, f.have = p, true
+		return true
+	}
+
+	d := distance3D(f.last, p)
+	if f.maxSpeed > 0 && f.jumps < trackJumpResync {
+		// Measure over at least a second so closely spaced frames don't
+		// turn ordinary GPS noise into an impossible speed.
+		dt := max(p.Time.Sub(f.last.Time), time.Second).Seconds()
+		if d/dt > f.maxSpeed {
+			f.jumps++
+			return false
+		}
+	}
+	f.jumps = 0
+	if d < f.minDistance {
+		return false
+	}
+	f.last = p
+	return true
+}
+
+func (f *trackFilter) reset() {
+	f.have, f.jumps = false, 0
+}
+
+// distance3D is the distance between two points in metres, combining the
+// great-circle distance with the altitude difference.
+func distance3D(a, b TrackPoint) float64 {
+	return math.Hypot(geo.Distance(a.Lat, a.Lon, b.Lat, b.Lon), b.Alt-a.Alt)
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrackFilter(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	// 1e-5 degrees of latitude is about 1.1 m.
+	at := func(sec int, dLat float64, fix uint8) TrackPoint {
+		return TrackPoint{Time: t0.Add(time.Duration(sec) * time.Second), Lat: 51.5 + dLat, Lon: -0.1, Fix: fix}
+	}
+	f := newTrackFilter(TrackOptions{MinDistance: 2, MaxSpeed: 100, MinFix: 2})
+
+	tests := []struct {
+		name string
+		p    TrackPoint
+		want bool
+	}{
+		{"no fix", at(0, 0, 1), false},
+		{"first fix", at(0, 0, 3), true},
+		{"parked", at(1, 1e-5, 3), false},
+		{"moved", at(2, 3e-5, 3), true},
+		{"2D fix", at(3, 6e-5, 2), true},
+		{"climb only", TrackPoint{Time: t0.Add(4 * time.Second), Lat: 51.5 + 6e-5, Lon: -0.1, Alt: 5, Fix: 3}, true},
+		{"jump", at(5, 0.01, 3), false}, // 1.1 km in a second
+		{"back on track", at(6, 10e-5, 3), true},
+	}
+	for _, tt := range tests {
+		if got := f.accept(tt.p); got != tt.want {
+			t.Errorf("%s: accept = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A position that keeps jumping is eventually believed.
+	var accepted int
+	for i := range trackJumpResync + 1 {
+		if f.accept(at(7+i, 0.5, 3)) {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("accepted %d points at the new position, want 1 after %d jumps", accepted, trackJumpResync)
+	}
+}
+
+func TestTrackDir_Filter(t *testing.T) {
+	tl, err := OpenTrackDir(t.TempDir(), TrackOptions{MinDistance: 2, MinFix: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	p := TrackPoint{Time: time.Now(), Lat: 51.5, Lon: -0.1, Fix: 3}
+	for range 100 {
+		tl.Append(p)
+	}
+	tl.Append(TrackPoint{Time: time.Now(), Lat: 51.6, Lon: -0.1, Fix: 0})
+	if points, _ := tl.ReadPoints(); len(points) != 1 {
+		t.Errorf("points = %d, want 1 for a parked aircraft", len(points))
+	}
+
+	// Clearing forgets the last point, so the same position is recorded again.
+	tl.Clear()
+	tl.Append(p)
+	if points, _ := tl.ReadPoints(); len(points) != 1 {
+		t.Errorf("points after clear = %d, want 1", len(points))
+	}
+
+	// A single-file log keeps every point.
+	single, err := NewTrackLog(filepath.Join(t.TempDir(), "track.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer single.Close()
+	single.Append(p)
+	single.Append(p)
+	if points, _ := single.ReadPoints(); len(points) != 2 {
+		t.Errorf("single-file points = %d, want 2", len(points))
+	}
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import "time"
+
+// windowCounts are the events counted in one span of time.
+type windowCounts struct {
+	Frames       map[byte]int
+	Bytes        int
+	CRCErrors    int
+	DecodeErrors int
+	XReceived    int // X-frames with a usable sequence counter
+	XLost        int // X-frames missing from counter gaps
+}
+
+func (c *windowCounts) add(o windowCounts) {
+	for fn, n := range o.Frames {
+		c.Frames[fn] += n
+	}
+	c.Bytes += o.Bytes
+	c.CRCErrors += o.CRCErrors
+	c.DecodeErrors += o.DecodeErrors
+	c.XReceived += o.XReceived
+	c.XLost += o.XLost
+}
+
+// total returns the number of frames counted.
+func (c *windowCounts) total() int {
+	n := 0
+	for _, v := range c.Frames {
+		n += v
+	}
+	return n
+}
+
+type windowBucket struct {
+	sec int64 // Unix second the bucket counts
+	windowCounts
+}
+
+// slidingWindow counts events in one-second buckets, keeping the most
+// recent len(buckets) seconds.
+type slidingWindow struct {
+	buckets []windowBucket
+}
+
+func newSlidingWindow(seconds int) *slidingWindow {
+	return &slidingWindow{buckets: make([]windowBucket, seconds)}
+}
+
+// at returns the counts for the second containing now, clearing a bucket
+// left over from an earlier lap of the ring.
+func (w *slidingWindow) at(now time.Time) *windowCounts {
+	sec := now.Unix()
+	b := &w.buckets[int(sec%int64(len(w.buckets)))]
+	if b.sec != sec {
+		*b = windowBucket{sec: sec, windowCounts: windowCounts{Frames: make(map[byte]int)}}
+	}
+	return &b.windowCounts
+}
+
+// span returns how many whole seconds of an n-second window have elapsed
+// since start, so rates early on are not diluted by seconds before it.
+func span(start, now time.Time, n int) int {
+	return int(max(0, min(int64(n), now.Unix()-start.Unix())))
+}
+
+// sum totals the n whole seconds before the one containing now. The
+// current second is left out so rates do not dip while it fills.
+func (w *slidingWindow) sum(now time.Time, n int) windowCounts {
+	total := windowCounts{Frames: make(map[byte]int)}
+	sec := now.Unix()
+	for _, b := range w.buckets {
+		if b.sec < sec && b.sec >= sec-int64(n) {
+			total.add(b.windowCounts)
+		}
+	}
+	return total
+}
@@ -9,6 +9,8 @@ import { ConnectionCard } from "./panels/connection-card"
 import { NavCard } from "./panels/nav-card"
 import { HomeCard } from "./panels/home-card"
 import { AttitudeCard } from "./panels/attitude-card"
+import { RadioLinkCard } from "./panels/radio-link-card"
+import { AlarmsCard } from "./panels/alarms-card"
 
 export function Dashboard() {
   return (
@@ -29,6 +31,9 @@ export function Dashboard() {
           <div className="min-h-0 animate-fade-up" style={{ animationDelay: "50ms" }}>
             <MapPanel />
           </div>
+          <div className="animate-fade-up" style={{ animationDelay: "100ms" }}>
+            <AlarmsCard />
+          </div>
           <div className="animate-fade-up" style={{ animationDelay: "125ms" }}>
             <ConnectionCard />
           </div>
@@ -40,6 +45,7 @@ export function Dashboard() {
           <div className="animate-fade-up" style={{ animationDelay: "75ms" }}><HomeCard /></div>
           <div className="animate-fade-up" style={{ animationDelay: "150ms" }}><SensorCard /></div>
           <div className="animate-fade-up" style={{ animationDelay: "225ms" }}><GPSCard /></div>
+          <div className="animate-fade-up" style={{ animationDelay: "300ms" }}><RadioLinkCard /></div>
         </div>
       </div>
     </div>
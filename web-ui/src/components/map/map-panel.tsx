@@ -154,12 +154,24 @@ export function MapPanel() {
           </Badge>
           <span className="text-[10px] text-white/80 drop-shadow-[0_1px_2px_rgba(0,0,0,0.8)]">{sats} sats</span>
         </div>
-        <button
-          onClick={clearRoute}
-          className="absolute top-2 right-2 z-[1000] px-2 py-0.5 text-[10px] font-medium rounded bg-black/50 text-white/80 hover:bg-black/70 hover:text-white transition-colors backdrop-blur-sm"
-        >
-          Clear Route
-        </button>
+        <div className="absolute top-2 right-2 z-[1000] flex items-center gap-1">
+          {(["gpx", "kml"] as const).map((ext) => (
+            <a
+              key={ext}
+              href={`/api/track.${ext}`}
+              download
+              className="px-2 py-0.5 text-[10px] font-medium uppercase rounded bg-black/50 text-white/80 hover:bg-black/70 hover:text-white transition-colors backdrop-blur-sm"
+            >
+              {ext}
+            </a>
+          ))}
+          <button
+            onClick={clearRoute}
+            className="px-2 py-0.5 text-[10px] font-medium rounded bg-black/50 text-white/80 hover:bg-black/70 hover:text-white transition-colors backdrop-blur-sm"
+          >
+            Clear Route
+          </button>
+        </div>
       </CardContent>
     </Card>
   )
@@ -0,0 +1,76 @@
+import { useCallback, useContext, useEffect, useState } from "react"
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card"
+import { Badge } from "@/components/ui/badge"
+import { BellRing } from "lucide-react"
+import { TelemetryContext } from "@/providers/telemetry-provider"
+import { useTelemetryValue } from "@/hooks/use-telemetry-value"
+import type { AlarmPayload } from "@/types/telemetry"
+
+const MAX_LOG = 8
+
+const severityVariant = {
+  info: "secondary",
+  warning: "outline",
+  critical: "destructive",
+} as const
+
+function timeOf(ts: number) {
+  return new Date(ts).toLocaleTimeString([], { hour12: false })
+}
+
+export function AlarmsCard() {
+  const { subscribeEvents } = useContext(TelemetryContext)
+  const [log, setLog] = useState<AlarmPayload[]>([])
+
+  const active = useTelemetryValue<AlarmPayload[]>(
+    useCallback((msg) => msg.alarms ?? [], []),
+  )
+
+  useEffect(() => {
+    return subscribeEvents((ev) => {
+      const alarm = ev.alarm
+      if (!alarm) return
+      setLog((prev) => [alarm, ...prev].slice(0, MAX_LOG))
+    })
+  }, [subscribeEvents])
+
+  if (!active?.length && !log.length) return null
+
+  return (
+    <Card>
+      <CardHeader className="pb-2">
+        <CardTitle className="text-xs uppercase tracking-wider text-muted-foreground flex items-center gap-1.5">
+          <BellRing className="size-3" />
+          Alarms
+        </CardTitle>
+      </CardHeader>
+      <CardContent className="space-y-1.5 px-3">
+        {active?.map((a) => (
+          <div key={a.rule} className="flex items-center justify-between gap-2">
+            <Badge
+              variant={severityVariant[a.severity]}
+              className={`text-[10px] ${a.severity === "critical" ? "animate-pulse" : ""}`}
+            >
+              {a.message}
+            </Badge>
+            <span className="text-xs tabular-nums text-muted-foreground">
+              {a.value.toFixed(1)}
+            </span>
+          </div>
+        ))}
+        {log.length > 0 && (
+          <div className="pt-1 space-y-0.5">
+            {log.map((a, i) => (
+              <div key={`${a.rule}-${a.ts}-${i}`} className="flex justify-between text-[10px] text-muted-foreground tabular-nums">
+                <span>
+                  {a.message} {a.state}
+                </span>
+                <span>{timeOf(a.ts)}</span>
+              </div>
+            ))}
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  )
+}
@@ -5,7 +5,22 @@ import { TelemetryContext } from "@/providers/telemetry-provider"
 import { useTelemetryValue } from "@/hooks/use-telemetry-value"
 import { Radio } from "lucide-react"
 import { Stat } from "./stat"
-import type { StatsPayload } from "@/types/telemetry"
+import type { Freshness, StatsPayload } from "@/types/telemetry"
+
+const streamLabels = [
+  ["attitude", "A"],
+  ["gps", "G"],
+  ["status", "S"],
+  ["origin", "O"],
+  ["extra", "X"],
+] as const
+
+const streamColor = {
+  none: "text-muted-foreground",
+  fresh: "text-green-500",
+  stale: "text-yellow-500",
+  lost: "text-red-500",
+}
 
 export function ConnectionCard() {
   const { status, dataStatus } = useContext(TelemetryContext)
@@ -14,6 +29,10 @@ export function ConnectionCard() {
     useCallback((msg) => msg.stats, []),
   )
 
+  const freshness = useTelemetryValue<Freshness | undefined>(
+    useCallback((msg) => msg.freshness, []),
+  )
+
   const statusColor =
     status === "connected"
       ? "default"
@@ -56,15 +75,57 @@ export function ConnectionCard() {
               ? "FC RECEIVING"
               : dataStatus === "stale"
                 ? "FC STALE"
-                : "NO FC DATA"}
+                : dataStatus === "lost"
+                  ? "FC LOST"
+                  : "NO FC DATA"}
           </Badge>
         </div>
 
+        <Stat
+          label="Link"
+          value={
+            stats?.link
+              ? stats.link.state === "connected"
+                ? "CONNECTED"
+                : `${stats.link.state.toUpperCase()} (${stats.link.retries})`
+              : undefined
+          }
+        />
+        <Stat
+          label="Streams"
+          value={
+            freshness ? (
+              <span className="flex gap-1">
+                {streamLabels.map(([key, label]) => {
+                  const st = freshness.streams[key]
+                  return (
+                    <span key={key} className={streamColor[st.state]} title={`${key}: ${st.state}`}>
+                      {label}
+                    </span>
+                  )
+                })}
+              </span>
+            ) : undefined
+          }
+        />
+        <Stat label="Link Quality" value={stats?.loss ? stats.link_quality : undefined} unit="%" />
+        <Stat label="X Lost" value={stats?.loss?.x_lost} />
+        <Stat label="Protocol" value={stats?.protocol?.toUpperCase() ?? (stats ? "DETECTING" : undefined)} />
         <Stat label="Uptime" value={stats ? formatUptime(stats.uptime_sec) : undefined} />
         <Stat label="FPS" value={stats?.fps.toFixed(1)} />
+        <Stat label="FPS 60s" value={stats?.windows?.find((w) => w.window === 60)?.fps.toFixed(1)} />
+        <Stat label="Rx" value={stats?.windows?.[0]?.bytes_per_sec.toFixed(0)} unit="B/s" />
         <Stat label="Total" value={stats?.total} />
         <Stat label="CRC Err" value={stats?.crc_errors} />
         <Stat label="Decode Err" value={stats?.decode_errors} />
+        <Stat
+          label="Err Bursts"
+          value={
+            stats?.error_bursts
+              ? `${stats.error_bursts.count}${stats.error_bursts.active ? " (active)" : ""}`
+              : undefined
+          }
+        />
       </CardContent>
     </Card>
   )
@@ -4,12 +4,16 @@ import { Badge } from "@/components/ui/badge"
 import { Home } from "lucide-react"
 import { useTelemetryValue } from "@/hooks/use-telemetry-value"
 import { Stat } from "./stat"
-import type { OriginData } from "@/types/telemetry"
+import type { DerivedData, OriginData } from "@/types/telemetry"
 
 export function HomeCard() {
   const data = useTelemetryValue<OriginData | undefined>(
     useCallback((msg) => msg.origin, []),
   )
+  const derived = useTelemetryValue<DerivedData | undefined>(
+    useCallback((msg) => msg.derived, []),
+  )
+  const nav = derived?.home_valid ? derived : undefined
 
   const hasHome = data && data.fix > 0
 
@@ -29,6 +33,11 @@ export function HomeCard() {
         <Stat label="Lon" value={data?.lon.toFixed(7)} unit={"\u00B0"} />
         <Stat label="Alt" value={data?.alt.toFixed(1)} unit="m" />
         <Stat label="OSD" value={data?.osd_on ? "On" : "Off"} />
+        <Stat label="Distance" value={nav?.distance_to_home.toFixed(0)} unit="m" />
+        <Stat label="To Home" value={nav?.bearing_to_home.toFixed(0)} unit={"\u00B0"} />
+        <Stat label="Antenna" value={nav?.bearing_from_home.toFixed(0)} unit={"\u00B0"} />
+        <Stat label="Rel Alt" value={derived?.altitude_above_home.toFixed(1)} unit="m" />
+        <Stat label="Climb" value={derived?.climb_rate.toFixed(1)} unit="m/s" />
       </CardContent>
     </Card>
   )
@@ -0,0 +1,38 @@
+import { useCallback } from "react"
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card"
+import { Antenna } from "lucide-react"
+import { useTelemetryValue } from "@/hooks/use-telemetry-value"
+import { Stat } from "./stat"
+import type { CRSFLinkStats } from "@/types/telemetry"
+
+// Only rendered for CRSF input, which is the only protocol carrying
+// radio link statistics.
+export function RadioLinkCard() {
+  const data = useTelemetryValue<CRSFLinkStats | undefined>(
+    useCallback((msg) => msg.crsf_link, []),
+  )
+
+  if (data === undefined) return null
+
+  const rssi =
+    data.active_antenna === 0 ? data.uplink_rssi_1 : data.uplink_rssi_2
+
+  return (
+    <Card>
+      <CardHeader className="pb-2">
+        <CardTitle className="text-xs uppercase tracking-wider text-muted-foreground flex items-center gap-1.5">
+          <Antenna className="size-3" />
+          Radio Link
+        </CardTitle>
+      </CardHeader>
+      <CardContent className="space-y-1.5 px-3">
+        <Stat label="Uplink RSSI" value={rssi} unit="dBm" />
+        <Stat label="Uplink LQ" value={data.uplink_lq} unit="%" />
+        <Stat label="Uplink SNR" value={data.uplink_snr} unit="dB" />
+        <Stat label="TX Power" value={data.uplink_tx_power} unit="mW" />
+        <Stat label="Downlink RSSI" value={data.downlink_rssi} unit="dBm" />
+        <Stat label="Downlink LQ" value={data.downlink_lq} unit="%" />
+      </CardContent>
+    </Card>
+  )
+}
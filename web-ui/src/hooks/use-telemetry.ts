@@ -1,15 +1,17 @@
 import { useCallback, useEffect, useRef, useState } from "react"
-import type { TelemetryMessage } from "@/types/telemetry"
+import type { EventMessage, TelemetryMessage } from "@/types/telemetry"
 
 export type ConnectionStatus = "connecting" | "connected" | "disconnected"
-export type DataStatus = "receiving" | "stale" | "none"
+export type DataStatus = "receiving" | "stale" | "lost" | "none"
 export type Listener = (msg: TelemetryMessage) => void
+export type EventListener = (ev: EventMessage) => void
 
 export interface TelemetryHandle {
   status: ConnectionStatus
   dataStatus: DataStatus
   messageRef: React.RefObject<TelemetryMessage | null>
   subscribe: (fn: Listener) => () => void
+  subscribeEvents: (fn: EventListener) => () => void
 }
 
 export function useTelemetry(): TelemetryHandle {
@@ -17,6 +19,7 @@ export function useTelemetry(): TelemetryHandle {
   const [dataStatus, setDataStatus] = useState<DataStatus>("none")
   const messageRef = useRef<TelemetryMessage | null>(null)
   const listenersRef = useRef<Set<Listener>>(new Set())
+  const eventListenersRef = useRef<Set<EventListener>>(new Set())
   const wsRef = useRef<WebSocket | null>(null)
   const reconnectTimer = useRef<ReturnType<typeof setTimeout>>(undefined)
 
@@ -27,6 +30,13 @@ export function useTelemetry(): TelemetryHandle {
     }
   }, [])
 
+  const subscribeEvents = useCallback((fn: EventListener) => {
+    eventListenersRef.current.add(fn)
+    return () => {
+      eventListenersRef.current.delete(fn)
+    }
+  }, [])
+
   useEffect(() => {
     let disposed = false
 
@@ -45,13 +55,37 @@ export function useTelemetry(): TelemetryHandle {
 
       ws.onmessage = (ev) => {
         try {
-          const msg: TelemetryMessage = JSON.parse(ev.data)
+          const data = JSON.parse(ev.data)
+          if ("event" in data) {
+            for (const fn of eventListenersRef.current) {
+              fn(data as EventMessage)
+            }
+            return
+          }
+
+          // Deltas carry only the sections that changed since the last message.
+          let msg: TelemetryMessage = data
+          if (msg.delta && messageRef.current) {
+            msg = { ...messageRef.current, ...msg }
+            for (const key of msg.cleared ?? []) {
+              delete msg[key]
+            }
+            delete msg.cleared
+          }
           messageRef.current = msg
 
-          if (msg.attitude_ts != null) {
-            setDataStatus(msg.ts - msg.attitude_ts < 2000 ? "receiving" : "stale")
-          } else {
-            setDataStatus("none")
+          switch (msg.freshness?.overall) {
+            case "fresh":
+              setDataStatus("receiving")
+              break
+            case "stale":
+              setDataStatus("stale")
+              break
+            case "lost":
+              setDataStatus("lost")
+              break
+            default:
+              setDataStatus("none")
           }
 
           for (const fn of listenersRef.current) {
@@ -83,5 +117,5 @@ export function useTelemetry(): TelemetryHandle {
     }
   }, [])
 
-  return { status, dataStatus, messageRef, subscribe }
+  return { status, dataStatus, messageRef, subscribe, subscribeEvents }
 }
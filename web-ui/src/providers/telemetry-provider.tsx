@@ -7,6 +7,7 @@ const noopHandle: TelemetryHandle = {
   dataStatus: "none",
   messageRef: { current: null },
   subscribe: () => () => {},
+  subscribeEvents: () => () => {},
 }
 
 export const TelemetryContext = createContext<TelemetryHandle>(noopHandle)
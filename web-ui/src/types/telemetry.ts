@@ -49,17 +49,117 @@ export interface ExtraData {
   disarm_reason: number
 }
 
+export interface CRSFLinkStats {
+  uplink_rssi_1: number
+  uplink_rssi_2: number
+  uplink_lq: number
+  uplink_snr: number
+  active_antenna: number
+  rf_mode: number
+  uplink_tx_power: number
+  downlink_rssi: number
+  downlink_lq: number
+  downlink_snr: number
+}
+
+export interface DerivedData {
+  home_valid: boolean
+  distance_to_home: number
+  bearing_to_home: number
+  bearing_from_home: number
+  altitude_above_home: number
+  climb_rate: number
+}
+
+export type StreamState = "none" | "fresh" | "stale" | "lost"
+
+export interface StreamFreshness {
+  state: StreamState
+  age_ms?: number
+  expected_hz: number
+}
+
+export interface Freshness {
+  overall: StreamState
+  streams: Record<"attitude" | "gps" | "status" | "origin" | "extra", StreamFreshness>
+}
+
+export type AlarmSeverity = "info" | "warning" | "critical"
+
+export interface AlarmPayload {
+  rule: string
+  state: "raised" | "cleared"
+  severity: AlarmSeverity
+  message: string
+  field: string
+  value: number
+  threshold: number
+  ts: number
+}
+
+export interface LinkPayload {
+  state: "connected" | "reconnecting" | "lost"
+  since_ts: number
+  last_connected_ts?: number
+  retries: number
+  last_error?: string
+}
+
+export interface RatePayload {
+  observed_hz: number
+  expected_hz: number
+}
+
+export interface LossPayload {
+  window_sec: number
+  x_received: number
+  x_lost: number
+  crc_errors: number
+  crc_error_rate: number
+  rates: Partial<Record<"attitude" | "gps" | "status" | "origin" | "extra", RatePayload>>
+}
+
+export type FrameKey = "attitude" | "gps" | "status" | "origin" | "nav" | "extra"
+
+export interface WindowPayload {
+  window: number
+  seconds: number
+  fps: number
+  frames: Partial<Record<FrameKey, number>>
+  bytes_per_sec: number
+  crc_errors: number
+  decode_errors: number
+}
+
+export interface BurstPayload {
+  count: number
+  active: boolean
+  last_start_ts: number
+  last_end_ts: number
+  last_errors: number
+}
+
 export interface StatsPayload {
   uptime_sec: number
   total: number
+  bytes: number
   fps: number
+  avg_fps: number
   crc_errors: number
   decode_errors: number
+  protocol?: "ltm" | "mavlink" | "crsf" | "msp"
+  link_quality: number
+  windows?: WindowPayload[]
+  error_bursts?: BurstPayload
+  link?: LinkPayload
+  loss?: LossPayload
 }
 
 // Full WebSocket message envelope
 export interface TelemetryMessage {
   ts: number
+  delta?: boolean
+  cleared?: "alarms"[]
 
   gps?: GPSData
   gps_ts?: number
@@ -73,6 +173,37 @@ export interface TelemetryMessage {
   nav_ts?: number
   extra?: ExtraData
   extra_ts?: number
+  crsf_link?: CRSFLinkStats
+  crsf_link_ts?: number
+  derived?: DerivedData
+  freshness?: Freshness
+
+  alarms?: AlarmPayload[]
 
   stats?: StatsPayload
 }
+
+// Flight started or ended, as sent in session events (without its track)
+export interface FlightSession {
+  id: number
+  start: string
+  end: string
+  active: boolean
+  disarm_reason_name: string
+}
+
+// Discrete event sent between periodic telemetry messages
+export interface EventMessage {
+  ts: number
+  event: "alarm" | "session" | "subscription" | "error"
+  alarm?: AlarmPayload
+  session?: FlightSession
+  subscription?: { topics: string[]; rate: number }
+  error?: string
+}
+
+// Request sent to the server to change what this client receives
+export type ClientMessage =
+  | { type: "subscribe" | "unsubscribe"; topics: string[] }
+  | { type: "rate"; rate: number }
+  | { type: "snapshot" }